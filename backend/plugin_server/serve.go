@@ -59,6 +59,10 @@ func init() {
 
 func Serve(opts ServeOpts) {
 
+	if dumpPath := os.Getenv(build.DumpMigrationEnv); dumpPath != "" {
+		dumpMigration(dumpPath, opts.Migration)
+	}
+
 	evRpcPort := EvRpcPort
 	pluginJson := new(build.PluginJsonData)
 	if opts.Assets == nil {
@@ -106,6 +110,22 @@ func Serve(opts ServeOpts) {
 
 }
 
+// dumpMigration 将迁移配置写入文件后退出，供 ev_plugin_cli migrate 使用
+func dumpMigration(path string, migration *build.Gormigrate) {
+	if migration == nil {
+		migration = new(build.Gormigrate)
+	}
+	b, err := json.Marshal(migration)
+	if err == nil {
+		err = os.WriteFile(path, b, 0644)
+	}
+	if err != nil {
+		log.Println("迁移配置导出失败", err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
 func GetTmpFileStorePath() string {
 	if _, err := os.Stat(TmpFileStorePath); os.IsNotExist(err) {
 		os.MkdirAll(TmpFileStorePath, os.ModePerm)
@@ -0,0 +1,348 @@
+package migrate
+
+// 导入所需的包
+import (
+	// 格式化包
+	"fmt"
+	// 字符串处理包
+	"strings"
+	// 时间处理包
+	"time"
+
+	// 错误处理包
+	"github.com/pkg/errors"
+)
+
+// ColumnType 与方言无关的列类型
+type ColumnType int
+
+// 支持的列类型
+const (
+	// TypeIncrements 自增INT主键
+	TypeIncrements ColumnType = iota
+	// TypeBigIncrements 自增BIGINT主键
+	TypeBigIncrements
+	// TypeSmallInt 小整数
+	TypeSmallInt
+	// TypeInt 整数
+	TypeInt
+	// TypeBigInt 长整数
+	TypeBigInt
+	// TypeBool 布尔值，MySQL为TINYINT(1)
+	TypeBool
+	// TypeFloat 单精度浮点数
+	TypeFloat
+	// TypeDouble 双精度浮点数
+	TypeDouble
+	// TypeDecimal 定点数
+	TypeDecimal
+	// TypeString 变长字符串
+	TypeString
+	// TypeText 长文本
+	TypeText
+	// TypeLongText 超长文本
+	TypeLongText
+	// TypeBlob 二进制数据
+	TypeBlob
+	// TypeDate 日期
+	TypeDate
+	// TypeDateTime 日期时间
+	TypeDateTime
+	// TypeTimestamp 时间戳
+	TypeTimestamp
+	// TypeJson JSON，SQLite为TEXT
+	TypeJson
+)
+
+// Expr 原样写入SQL的默认值表达式，例如 Expr("CURRENT_TIMESTAMP")
+type Expr string
+
+// Column 列定义，通过String、Int等函数创建，再链式设置属性
+type Column struct {
+	name          string
+	typ           ColumnType
+	size          int
+	precision     int
+	scale         int
+	nullable      bool
+	unsigned      bool
+	unique        bool
+	hasDefault    bool
+	defaultValue  interface{}
+	comment       string
+	autoIncrement bool
+}
+
+// newColumn 创建列定义
+func newColumn(name string, typ ColumnType) *Column {
+	return &Column{name: name, typ: typ, autoIncrement: typ == TypeIncrements || typ == TypeBigIncrements}
+}
+
+// Increments 自增INT主键列
+func Increments(name string) *Column { return newColumn(name, TypeIncrements) }
+
+// BigIncrements 自增BIGINT主键列
+func BigIncrements(name string) *Column { return newColumn(name, TypeBigIncrements) }
+
+// SmallInt 小整数列
+func SmallInt(name string) *Column { return newColumn(name, TypeSmallInt) }
+
+// Int 整数列
+func Int(name string) *Column { return newColumn(name, TypeInt) }
+
+// BigInt 长整数列
+func BigInt(name string) *Column { return newColumn(name, TypeBigInt) }
+
+// Bool 布尔列
+func Bool(name string) *Column { return newColumn(name, TypeBool) }
+
+// Float 单精度浮点数列
+func Float(name string) *Column { return newColumn(name, TypeFloat) }
+
+// Double 双精度浮点数列
+func Double(name string) *Column { return newColumn(name, TypeDouble) }
+
+// Decimal 定点数列
+func Decimal(name string, precision, scale int) *Column {
+	col := newColumn(name, TypeDecimal)
+	col.precision, col.scale = precision, scale
+	return col
+}
+
+// String 变长字符串列，size为最大字符数
+func String(name string, size int) *Column {
+	col := newColumn(name, TypeString)
+	col.size = size
+	return col
+}
+
+// Text 长文本列
+func Text(name string) *Column { return newColumn(name, TypeText) }
+
+// LongText 超长文本列
+func LongText(name string) *Column { return newColumn(name, TypeLongText) }
+
+// Blob 二进制数据列
+func Blob(name string) *Column { return newColumn(name, TypeBlob) }
+
+// Date 日期列
+func Date(name string) *Column { return newColumn(name, TypeDate) }
+
+// DateTime 日期时间列
+func DateTime(name string) *Column { return newColumn(name, TypeDateTime) }
+
+// Timestamp 时间戳列
+func Timestamp(name string) *Column { return newColumn(name, TypeTimestamp) }
+
+// Json JSON列
+func Json(name string) *Column { return newColumn(name, TypeJson) }
+
+// Nullable 允许为NULL，列默认为NOT NULL
+func (this *Column) Nullable() *Column {
+	this.nullable = true
+	return this
+}
+
+// Unsigned 无符号整数，仅MySQL生效
+func (this *Column) Unsigned() *Column {
+	this.unsigned = true
+	return this
+}
+
+// Unique 为该列创建唯一索引，索引名为 uk_表名_列名
+func (this *Column) Unique() *Column {
+	this.unique = true
+	return this
+}
+
+// Default 设置默认值，支持字符串、数值、布尔值、time.Time和Expr
+func (this *Column) Default(value interface{}) *Column {
+	this.hasDefault = true
+	this.defaultValue = value
+	return this
+}
+
+// Comment 设置列注释，仅MySQL生效
+func (this *Column) Comment(comment string) *Column {
+	this.comment = comment
+	return this
+}
+
+// Name 返回列名
+func (this *Column) Name() string {
+	return this.name
+}
+
+// isText 是否为MySQL不允许设置字面量默认值的类型
+func (this *Column) isText() bool {
+	switch this.typ {
+	case TypeText, TypeLongText, TypeBlob, TypeJson:
+		return true
+	}
+	return false
+}
+
+// validate 检查列定义
+func (this *Column) validate() error {
+	if this.name == "" {
+		return errors.New("列名不能为空")
+	}
+	if this.typ == TypeString && this.size <= 0 {
+		return errors.Errorf("列 %s: 字符串长度必须大于0", this.name)
+	}
+	if this.typ == TypeDecimal && (this.precision <= 0 || this.scale < 0 || this.scale > this.precision) {
+		return errors.Errorf("列 %s: 无效的精度 (%d,%d)", this.name, this.precision, this.scale)
+	}
+	if this.autoIncrement && (this.nullable || this.hasDefault) {
+		return errors.Errorf("列 %s: 自增列不能为NULL或设置默认值", this.name)
+	}
+	if _, ok := this.defaultValue.(Expr); !ok && this.hasDefault && this.isText() {
+		return errors.Errorf("列 %s: MySQL的TEXT、BLOB和JSON列不能设置字面量默认值", this.name)
+	}
+	return nil
+}
+
+// sqlType 返回列在方言下的类型
+func (this *Column) sqlType(dialect Dialect) string {
+	if dialect == Sqlite {
+		switch this.typ {
+		case TypeIncrements, TypeBigIncrements, TypeSmallInt, TypeInt, TypeBigInt, TypeBool:
+			return "INTEGER"
+		case TypeFloat, TypeDouble:
+			return "REAL"
+		case TypeDecimal:
+			return fmt.Sprintf("NUMERIC(%d,%d)", this.precision, this.scale)
+		case TypeString:
+			return fmt.Sprintf("VARCHAR(%d)", this.size)
+		case TypeText, TypeLongText, TypeJson:
+			return "TEXT"
+		case TypeBlob:
+			return "BLOB"
+		case TypeDate:
+			return "DATE"
+		case TypeDateTime:
+			return "DATETIME"
+		case TypeTimestamp:
+			return "TIMESTAMP"
+		}
+		return ""
+	}
+
+	var typ string
+	switch this.typ {
+	case TypeIncrements, TypeInt:
+		typ = "INT"
+	case TypeBigIncrements, TypeBigInt:
+		typ = "BIGINT"
+	case TypeSmallInt:
+		typ = "SMALLINT"
+	case TypeBool:
+		return "TINYINT(1)"
+	case TypeFloat:
+		typ = "FLOAT"
+	case TypeDouble:
+		typ = "DOUBLE"
+	case TypeDecimal:
+		typ = fmt.Sprintf("DECIMAL(%d,%d)", this.precision, this.scale)
+	case TypeString:
+		return fmt.Sprintf("VARCHAR(%d)", this.size)
+	case TypeText:
+		return "TEXT"
+	case TypeLongText:
+		return "LONGTEXT"
+	case TypeBlob:
+		return "LONGBLOB"
+	case TypeDate:
+		return "DATE"
+	case TypeDateTime:
+		return "DATETIME"
+	case TypeTimestamp:
+		return "TIMESTAMP"
+	case TypeJson:
+		return "JSON"
+	}
+	if this.unsigned {
+		typ += " UNSIGNED"
+	}
+	return typ
+}
+
+// definition 返回列定义SQL，inlinePK为true时自增列带上主键声明
+func (this *Column) definition(dialect Dialect, inlinePK bool) string {
+	parts := []string{quote(dialect, this.name), this.sqlType(dialect)}
+	if this.autoIncrement {
+		if dialect == Sqlite {
+			// SQLite只有INTEGER PRIMARY KEY才能自增
+			return strings.Join(append(parts, "PRIMARY KEY AUTOINCREMENT"), " ")
+		}
+		parts = append(parts, "NOT NULL", "AUTO_INCREMENT")
+		if inlinePK {
+			parts = append(parts, "PRIMARY KEY")
+		}
+	} else {
+		if this.nullable {
+			parts = append(parts, "NULL")
+		} else {
+			parts = append(parts, "NOT NULL")
+		}
+		if this.hasDefault {
+			parts = append(parts, "DEFAULT "+literal(dialect, this.defaultValue))
+		}
+	}
+	if dialect == Mysql && this.comment != "" {
+		parts = append(parts, "COMMENT "+QuoteString(dialect, this.comment))
+	}
+	return strings.Join(parts, " ")
+}
+
+// literal 将默认值转换为SQL字面量
+func literal(dialect Dialect, value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case Expr:
+		return string(v)
+	case string:
+		return QuoteString(dialect, v)
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	case time.Time:
+		return QuoteString(dialect, v.Format("2006-01-02 15:04:05"))
+	}
+	return fmt.Sprint(value)
+}
+
+// QuoteString 将字符串转换为方言对应的单引号字符串字面量
+// MySQL默认的sql_mode下反斜杠是转义符，需要先转义反斜杠；SQLite中反斜杠没有特殊含义
+// 参数：
+//   - dialect: 方言
+//   - s: 字符串
+//
+// 返回：
+//   - string: 字符串字面量
+func QuoteString(dialect Dialect, s string) string {
+	if dialect == Mysql {
+		s = strings.ReplaceAll(s, `\`, `\\`)
+	}
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// quote 按方言为标识符加引号
+func quote(dialect Dialect, name string) string {
+	if dialect == Mysql {
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// quoteList 为多个标识符加引号并用逗号连接
+func quoteList(dialect Dialect, names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = quote(dialect, name)
+	}
+	return strings.Join(quoted, ", ")
+}
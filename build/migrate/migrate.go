@@ -0,0 +1,415 @@
+// migrate包提供与方言无关的数据库迁移DSL，
+// 同一份定义同时生成SQLite和MySQL的迁移语句及回滚语句，输出build.Gormigrate供基座执行
+//
+// 示例用法:
+//
+//	m := migrate.New("20240601_create_users").
+//		CreateTable("users", func(t *migrate.Table) {
+//			t.BigIncrements("id")
+//			t.String("name", 64).Default("")
+//			t.String("email", 128).Unique()
+//			t.DateTime("created_at").Default(migrate.Expr("CURRENT_TIMESTAMP"))
+//			t.Index("", "name")
+//		})
+//	gormigrate, err := migrate.Gormigrate(m)
+//
+// 回滚语句由各操作自动反推，按逆序执行。
+// RenameColumn需要SQLite 3.25+、MySQL 8.0+，DropColumn需要SQLite 3.35+
+package migrate
+
+// 导入所需的包
+import (
+	// 字符串处理包
+	"strings"
+
+	// 构建包
+	"github.com/1340691923/eve-plugin-sdk-go/build"
+	// 错误处理包
+	"github.com/pkg/errors"
+)
+
+// Dialect 数据库方言
+type Dialect string
+
+// 支持的数据库方言，与plugin_server.DbType的取值一致
+const (
+	// Sqlite SQLite方言
+	Sqlite Dialect = "sqlite3"
+	// Mysql MySQL方言
+	Mysql Dialect = "mysql"
+)
+
+// Dialects 全部支持的方言
+var Dialects = []Dialect{Sqlite, Mysql}
+
+// operation 迁移操作，up和down分别返回迁移和回滚语句
+type operation interface {
+	up(dialect Dialect) ([]string, error)
+	down(dialect Dialect) ([]string, error)
+}
+
+// Migration 迁移定义，通过New创建，链式添加操作后使用Build或Gormigrate生成
+type Migration struct {
+	id  string
+	ops []operation
+	err error
+}
+
+// New 创建迁移，id为迁移版本号，基座按id记录已执行的迁移，建议使用时间戳前缀保证有序
+func New(id string) *Migration {
+	return &Migration{id: id}
+}
+
+// ID 返回迁移版本号
+func (this *Migration) ID() string {
+	return this.id
+}
+
+// add 添加操作，定义有误时记录第一个错误，在Build时返回
+func (this *Migration) add(op operation, err error) *Migration {
+	if err != nil {
+		if this.err == nil {
+			this.err = err
+		}
+		return this
+	}
+	this.ops = append(this.ops, op)
+	return this
+}
+
+// CreateTable 建表，回滚时删除该表
+func (this *Migration) CreateTable(name string, define func(t *Table)) *Migration {
+	table := &Table{name: name}
+	define(table)
+	return this.add(&createTable{table: table}, table.validate())
+}
+
+// DropTable 删表，define描述被删表的结构，用于回滚时重建
+func (this *Migration) DropTable(name string, define func(t *Table)) *Migration {
+	table := &Table{name: name}
+	define(table)
+	return this.add(&dropTable{table: table}, table.validate())
+}
+
+// RenameTable 重命名表，回滚时改回原名
+func (this *Migration) RenameTable(from, to string) *Migration {
+	var err error
+	if from == "" || to == "" {
+		err = errors.New("RenameTable: 表名不能为空")
+	}
+	return this.add(&renameTable{from: from, to: to}, err)
+}
+
+// AddColumn 添加列，回滚时删除该列
+// SQLite不支持添加自增列、非NULL且无默认值的列以及默认值为表达式的列
+func (this *Migration) AddColumn(table string, col *Column) *Migration {
+	return this.add(&addColumn{table: table, col: col}, validateAddColumn(table, col))
+}
+
+// DropColumn 删除列，col描述被删列的定义，用于回滚时重新添加
+func (this *Migration) DropColumn(table string, col *Column) *Migration {
+	err := validateAddColumn(table, col)
+	if err != nil {
+		err = errors.WithMessage(err, "DropColumn的列定义无法用于回滚")
+	}
+	return this.add(&dropColumn{table: table, col: col}, err)
+}
+
+// RenameColumn 重命名列，回滚时改回原名
+func (this *Migration) RenameColumn(table, from, to string) *Migration {
+	var err error
+	if table == "" || from == "" || to == "" {
+		err = errors.New("RenameColumn: 表名和列名不能为空")
+	}
+	return this.add(&renameColumn{table: table, from: from, to: to}, err)
+}
+
+// AddIndex 添加普通索引，name为空时使用 idx_表名_列名，回滚时删除该索引
+func (this *Migration) AddIndex(table, name string, columns ...string) *Migration {
+	return this.addIndex(newIndex(table, name, false, columns))
+}
+
+// AddUniqueIndex 添加唯一索引，name为空时使用 uk_表名_列名，回滚时删除该索引
+func (this *Migration) AddUniqueIndex(table, name string, columns ...string) *Migration {
+	return this.addIndex(newIndex(table, name, true, columns))
+}
+
+// addIndex 添加索引
+func (this *Migration) addIndex(index *Index) *Migration {
+	return this.add(&addIndex{index: index}, validateIndex(index))
+}
+
+// DropIndex 删除索引，index描述被删索引，用于回滚时重建
+func (this *Migration) DropIndex(index *Index) *Migration {
+	return this.add(&dropIndex{index: index}, validateIndex(index))
+}
+
+// Raw 添加原生SQL，用于DSL无法表达的变更，例如数据修正
+// 四组语句均需提供，回滚语句可以是无副作用的语句但不能为空，保证回滚完整
+func (this *Migration) Raw(sqliteUp, mysqlUp, sqliteDown, mysqlDown []string) *Migration {
+	var err error
+	if len(sqliteUp) == 0 || len(mysqlUp) == 0 {
+		err = errors.New("Raw: 迁移语句不能为空")
+	} else if len(sqliteDown) == 0 || len(mysqlDown) == 0 {
+		err = errors.New("Raw: 回滚语句不能为空")
+	}
+	return this.add(&raw{
+		ups:   map[Dialect][]string{Sqlite: sqliteUp, Mysql: mysqlUp},
+		downs: map[Dialect][]string{Sqlite: sqliteDown, Mysql: mysqlDown},
+	}, err)
+}
+
+// Sqls 返回指定方言的迁移语句和回滚语句，回滚语句已按逆序排列
+// 参数：
+//   - dialect: 数据库方言
+//
+// 返回：
+//   - ups: 迁移语句
+//   - downs: 回滚语句
+//   - err: 迁移定义有误时返回错误
+func (this *Migration) Sqls(dialect Dialect) (ups, downs []string, err error) {
+	if this.err != nil {
+		return nil, nil, errors.WithMessagef(this.err, "迁移 %s", this.id)
+	}
+	if this.id == "" {
+		return nil, nil, errors.New("迁移ID不能为空")
+	}
+	if len(this.ops) == 0 {
+		return nil, nil, errors.Errorf("迁移 %s: 没有任何操作", this.id)
+	}
+	for i, op := range this.ops {
+		sqls, err := op.up(dialect)
+		if err != nil {
+			return nil, nil, errors.WithMessagef(err, "迁移 %s", this.id)
+		}
+		ups = append(ups, sqls...)
+		rollback, err := this.ops[len(this.ops)-1-i].down(dialect)
+		if err != nil {
+			return nil, nil, errors.WithMessagef(err, "迁移 %s", this.id)
+		}
+		downs = append(downs, rollback...)
+	}
+	return ups, downs, nil
+}
+
+// Build 生成基座使用的迁移项
+// 返回：
+//   - *build.Migration: 包含SQLite、MySQL迁移及回滚语句的迁移项
+//   - err: 迁移定义有误时返回错误
+func (this *Migration) Build() (*build.Migration, error) {
+	migration := &build.Migration{ID: this.id}
+	for _, dialect := range Dialects {
+		ups, downs, err := this.Sqls(dialect)
+		if err != nil {
+			return nil, err
+		}
+		if dialect == Sqlite {
+			migration.SqliteMigrateSqls, migration.SqliteRollback = execSqls(ups), execSqls(downs)
+		} else {
+			migration.MysqlMigrateSqls, migration.MysqlRollback = execSqls(ups), execSqls(downs)
+		}
+	}
+	return migration, nil
+}
+
+// Gormigrate 按顺序生成全部迁移，迁移ID不能重复
+// 参数：
+//   - migrations: 迁移定义，基座按此顺序执行
+//
+// 返回：
+//   - *build.Gormigrate: 可直接传给plugin_server.ServeOpts.Migration
+//   - err: 任一迁移有误时返回错误
+func Gormigrate(migrations ...*Migration) (*build.Gormigrate, error) {
+	gormigrate := &build.Gormigrate{Migrations: make([]*build.Migration, 0, len(migrations))}
+	ids := map[string]bool{}
+	for _, m := range migrations {
+		if ids[m.id] {
+			return nil, errors.Errorf("迁移ID %s 重复", m.id)
+		}
+		ids[m.id] = true
+		migration, err := m.Build()
+		if err != nil {
+			return nil, err
+		}
+		gormigrate.Migrations = append(gormigrate.Migrations, migration)
+	}
+	return gormigrate, nil
+}
+
+// MustGormigrate 同Gormigrate，出错时panic，便于在main中直接使用
+func MustGormigrate(migrations ...*Migration) *build.Gormigrate {
+	gormigrate, err := Gormigrate(migrations...)
+	if err != nil {
+		panic(err)
+	}
+	return gormigrate
+}
+
+// execSqls 将SQL语句转换为基座的执行结构
+func execSqls(sqls []string) []*build.ExecSql {
+	res := make([]*build.ExecSql, len(sqls))
+	for i, sql := range sqls {
+		res[i] = &build.ExecSql{Sql: sql, Args: []interface{}{}}
+	}
+	return res
+}
+
+// validateAddColumn 检查可通过ALTER TABLE ADD COLUMN添加的列
+func validateAddColumn(table string, col *Column) error {
+	if table == "" {
+		return errors.New("表名不能为空")
+	}
+	if err := col.validate(); err != nil {
+		return errors.WithMessagef(err, "表 %s", table)
+	}
+	if col.autoIncrement {
+		return errors.Errorf("表 %s 列 %s: 不能通过AddColumn添加自增列", table, col.name)
+	}
+	if !col.nullable && !col.hasDefault {
+		return errors.Errorf("表 %s 列 %s: SQLite添加非NULL列时必须设置默认值", table, col.name)
+	}
+	if _, ok := col.defaultValue.(Expr); ok {
+		return errors.Errorf("表 %s 列 %s: SQLite添加列时默认值不能为表达式", table, col.name)
+	}
+	return nil
+}
+
+// validateIndex 检查索引定义
+func validateIndex(index *Index) error {
+	if index == nil || index.Table == "" || index.Name == "" || len(index.Columns) == 0 {
+		return errors.New("索引的表名、索引名和列不能为空")
+	}
+	if len(index.Name) > 64 {
+		// MySQL标识符最长64个字符
+		return errors.Errorf("索引名 %s 超过64个字符", index.Name)
+	}
+	return nil
+}
+
+// createTable 建表操作
+type createTable struct{ table *Table }
+
+func (this *createTable) up(dialect Dialect) ([]string, error) {
+	return this.table.createSqls(dialect), nil
+}
+
+func (this *createTable) down(dialect Dialect) ([]string, error) {
+	return []string{"DROP TABLE " + quote(dialect, this.table.name)}, nil
+}
+
+// dropTable 删表操作
+type dropTable struct{ table *Table }
+
+func (this *dropTable) up(dialect Dialect) ([]string, error) {
+	return (&createTable{table: this.table}).down(dialect)
+}
+
+func (this *dropTable) down(dialect Dialect) ([]string, error) {
+	return this.table.createSqls(dialect), nil
+}
+
+// renameTable 重命名表操作
+type renameTable struct{ from, to string }
+
+func (this *renameTable) up(dialect Dialect) ([]string, error) {
+	return []string{"ALTER TABLE " + quote(dialect, this.from) + " RENAME TO " + quote(dialect, this.to)}, nil
+}
+
+func (this *renameTable) down(dialect Dialect) ([]string, error) {
+	return (&renameTable{from: this.to, to: this.from}).up(dialect)
+}
+
+// addColumn 添加列操作
+type addColumn struct {
+	table string
+	col   *Column
+}
+
+func (this *addColumn) up(dialect Dialect) ([]string, error) {
+	sqls := []string{"ALTER TABLE " + quote(dialect, this.table) + " ADD COLUMN " + this.col.definition(dialect, false)}
+	if this.col.unique {
+		sqls = append(sqls, newIndex(this.table, "", true, []string{this.col.name}).createSql(dialect))
+	}
+	return sqls, nil
+}
+
+func (this *addColumn) down(dialect Dialect) ([]string, error) {
+	var sqls []string
+	if this.col.unique {
+		// SQLite不能删除带索引的列，先删除索引
+		sqls = append(sqls, newIndex(this.table, "", true, []string{this.col.name}).dropSql(dialect))
+	}
+	return append(sqls, "ALTER TABLE "+quote(dialect, this.table)+" DROP COLUMN "+quote(dialect, this.col.name)), nil
+}
+
+// dropColumn 删除列操作
+type dropColumn addColumn
+
+func (this *dropColumn) up(dialect Dialect) ([]string, error) {
+	return (*addColumn)(this).down(dialect)
+}
+
+func (this *dropColumn) down(dialect Dialect) ([]string, error) {
+	return (*addColumn)(this).up(dialect)
+}
+
+// renameColumn 重命名列操作
+type renameColumn struct{ table, from, to string }
+
+func (this *renameColumn) up(dialect Dialect) ([]string, error) {
+	return []string{"ALTER TABLE " + quote(dialect, this.table) + " RENAME COLUMN " + quote(dialect, this.from) + " TO " + quote(dialect, this.to)}, nil
+}
+
+func (this *renameColumn) down(dialect Dialect) ([]string, error) {
+	return (&renameColumn{table: this.table, from: this.to, to: this.from}).up(dialect)
+}
+
+// addIndex 添加索引操作
+type addIndex struct{ index *Index }
+
+func (this *addIndex) up(dialect Dialect) ([]string, error) {
+	return []string{this.index.createSql(dialect)}, nil
+}
+
+func (this *addIndex) down(dialect Dialect) ([]string, error) {
+	return []string{this.index.dropSql(dialect)}, nil
+}
+
+// dropIndex 删除索引操作
+type dropIndex addIndex
+
+func (this *dropIndex) up(dialect Dialect) ([]string, error) {
+	return (*addIndex)(this).down(dialect)
+}
+
+func (this *dropIndex) down(dialect Dialect) ([]string, error) {
+	return (*addIndex)(this).up(dialect)
+}
+
+// raw 原生SQL操作
+type raw struct {
+	ups   map[Dialect][]string
+	downs map[Dialect][]string
+}
+
+func (this *raw) up(dialect Dialect) ([]string, error) {
+	return this.sqls(this.ups, dialect)
+}
+
+func (this *raw) down(dialect Dialect) ([]string, error) {
+	return this.sqls(this.downs, dialect)
+}
+
+// sqls 返回指定方言的语句，去掉空语句
+func (this *raw) sqls(all map[Dialect][]string, dialect Dialect) ([]string, error) {
+	sqls := make([]string, 0, len(all[dialect]))
+	for _, sql := range all[dialect] {
+		if sql = strings.TrimSpace(sql); sql != "" {
+			sqls = append(sqls, sql)
+		}
+	}
+	if len(sqls) == 0 {
+		return nil, errors.Errorf("不支持的方言 %s", dialect)
+	}
+	return sqls, nil
+}
@@ -0,0 +1,221 @@
+package migrate
+
+// 导入所需的包
+import (
+	// 格式化包
+	"fmt"
+	// 字符串处理包
+	"strings"
+
+	// 错误处理包
+	"github.com/pkg/errors"
+)
+
+// Table 表定义，在CreateTable和DropTable的回调中描述表结构
+type Table struct {
+	name    string
+	columns []*Column
+	primary []string
+	indexes []*Index
+	comment string
+}
+
+// Index 索引定义
+type Index struct {
+	// 索引名
+	Name string
+	// 所属表
+	Table string
+	// 索引列
+	Columns []string
+	// 是否唯一索引
+	Unique bool
+}
+
+// Column 添加列定义，返回列以便链式设置属性
+func (this *Table) Column(col *Column) *Column {
+	this.columns = append(this.columns, col)
+	return col
+}
+
+// Increments 添加自增INT主键列
+func (this *Table) Increments(name string) *Column { return this.Column(Increments(name)) }
+
+// BigIncrements 添加自增BIGINT主键列
+func (this *Table) BigIncrements(name string) *Column { return this.Column(BigIncrements(name)) }
+
+// SmallInt 添加小整数列
+func (this *Table) SmallInt(name string) *Column { return this.Column(SmallInt(name)) }
+
+// Int 添加整数列
+func (this *Table) Int(name string) *Column { return this.Column(Int(name)) }
+
+// BigInt 添加长整数列
+func (this *Table) BigInt(name string) *Column { return this.Column(BigInt(name)) }
+
+// Bool 添加布尔列
+func (this *Table) Bool(name string) *Column { return this.Column(Bool(name)) }
+
+// Float 添加单精度浮点数列
+func (this *Table) Float(name string) *Column { return this.Column(Float(name)) }
+
+// Double 添加双精度浮点数列
+func (this *Table) Double(name string) *Column { return this.Column(Double(name)) }
+
+// Decimal 添加定点数列
+func (this *Table) Decimal(name string, precision, scale int) *Column {
+	return this.Column(Decimal(name, precision, scale))
+}
+
+// String 添加变长字符串列
+func (this *Table) String(name string, size int) *Column { return this.Column(String(name, size)) }
+
+// Text 添加长文本列
+func (this *Table) Text(name string) *Column { return this.Column(Text(name)) }
+
+// LongText 添加超长文本列
+func (this *Table) LongText(name string) *Column { return this.Column(LongText(name)) }
+
+// Blob 添加二进制数据列
+func (this *Table) Blob(name string) *Column { return this.Column(Blob(name)) }
+
+// Date 添加日期列
+func (this *Table) Date(name string) *Column { return this.Column(Date(name)) }
+
+// DateTime 添加日期时间列
+func (this *Table) DateTime(name string) *Column { return this.Column(DateTime(name)) }
+
+// Timestamp 添加时间戳列
+func (this *Table) Timestamp(name string) *Column { return this.Column(Timestamp(name)) }
+
+// Json 添加JSON列
+func (this *Table) Json(name string) *Column { return this.Column(Json(name)) }
+
+// Primary 设置主键列，使用自增列时不需要调用
+func (this *Table) Primary(columns ...string) {
+	this.primary = columns
+}
+
+// Index 添加普通索引，name为空时使用 idx_表名_列名
+func (this *Table) Index(name string, columns ...string) {
+	this.indexes = append(this.indexes, newIndex(this.name, name, false, columns))
+}
+
+// Unique 添加唯一索引，name为空时使用 uk_表名_列名
+func (this *Table) Unique(name string, columns ...string) {
+	this.indexes = append(this.indexes, newIndex(this.name, name, true, columns))
+}
+
+// Comment 设置表注释，仅MySQL生效
+func (this *Table) Comment(comment string) {
+	this.comment = comment
+}
+
+// newIndex 创建索引定义，name为空时按表名和列名生成
+func newIndex(table, name string, unique bool, columns []string) *Index {
+	if name == "" {
+		prefix := "idx"
+		if unique {
+			prefix = "uk"
+		}
+		name = fmt.Sprintf("%s_%s_%s", prefix, table, strings.Join(columns, "_"))
+	}
+	return &Index{Name: name, Table: table, Columns: columns, Unique: unique}
+}
+
+// allIndexes 返回表的全部索引，包括列上声明的唯一索引
+func (this *Table) allIndexes() []*Index {
+	indexes := make([]*Index, 0, len(this.indexes))
+	for _, col := range this.columns {
+		if col.unique {
+			indexes = append(indexes, newIndex(this.name, "", true, []string{col.name}))
+		}
+	}
+	return append(indexes, this.indexes...)
+}
+
+// validate 检查表定义
+func (this *Table) validate() error {
+	if this.name == "" {
+		return errors.New("表名不能为空")
+	}
+	if len(this.columns) == 0 {
+		return errors.Errorf("表 %s: 至少需要一列", this.name)
+	}
+	names := map[string]bool{}
+	autoIncrements := 0
+	for _, col := range this.columns {
+		if err := col.validate(); err != nil {
+			return errors.WithMessagef(err, "表 %s", this.name)
+		}
+		if names[col.name] {
+			return errors.Errorf("表 %s: 列 %s 重复", this.name, col.name)
+		}
+		names[col.name] = true
+		if col.autoIncrement {
+			autoIncrements++
+		}
+	}
+	if autoIncrements > 1 {
+		return errors.Errorf("表 %s: 只能有一个自增列", this.name)
+	}
+	if autoIncrements == 1 && len(this.primary) > 0 {
+		return errors.Errorf("表 %s: 自增列已是主键，不能再调用Primary", this.name)
+	}
+	for _, name := range this.primary {
+		if !names[name] {
+			return errors.Errorf("表 %s: 主键列 %s 不存在", this.name, name)
+		}
+	}
+	for _, index := range this.allIndexes() {
+		if err := validateIndex(index); err != nil {
+			return errors.WithMessagef(err, "表 %s", this.name)
+		}
+		for _, name := range index.Columns {
+			if !names[name] {
+				return errors.Errorf("表 %s: 索引 %s 的列 %s 不存在", this.name, index.Name, name)
+			}
+		}
+	}
+	return nil
+}
+
+// createSqls 返回建表及建索引语句
+func (this *Table) createSqls(dialect Dialect) []string {
+	defs := make([]string, 0, len(this.columns)+1)
+	for _, col := range this.columns {
+		defs = append(defs, col.definition(dialect, true))
+	}
+	if len(this.primary) > 0 {
+		defs = append(defs, "PRIMARY KEY ("+quoteList(dialect, this.primary)+")")
+	}
+	createSql := fmt.Sprintf("CREATE TABLE %s (\n  %s\n)", quote(dialect, this.name), strings.Join(defs, ",\n  "))
+	if dialect == Mysql {
+		createSql += " ENGINE=InnoDB DEFAULT CHARSET=utf8mb4"
+		if this.comment != "" {
+			createSql += " COMMENT=" + QuoteString(dialect, this.comment)
+		}
+	}
+	sqls := []string{createSql}
+	for _, index := range this.allIndexes() {
+		sqls = append(sqls, index.createSql(dialect))
+	}
+	return sqls
+}
+
+// createSql 返回建索引语句
+func (this *Index) createSql(dialect Dialect) string {
+	kind := "INDEX"
+	if this.Unique {
+		kind = "UNIQUE INDEX"
+	}
+	return fmt.Sprintf("CREATE %s %s ON %s (%s)", kind, quote(dialect, this.Name), quote(dialect, this.Table), quoteList(dialect, this.Columns))
+}
+
+// dropSql 返回删除索引语句
+func (this *Index) dropSql(dialect Dialect) string {
+	if dialect == Mysql {
+		return fmt.Sprintf("DROP INDEX %s ON %s", quote(dialect, this.Name), quote(dialect, this.Table))
+	}
+	return "DROP INDEX " + quote(dialect, this.Name)
+}
@@ -74,6 +74,10 @@ func (this *PluginJsonData) String() string {
 	return fmt.Sprintf("插件名：%s，开发者：%s,main文件：%s,插件别名：%s,版本号：%s", this.PluginName, this.Developer, this.MainGoFile, this.PluginAlias, this.Version)
 }
 
+// DumpMigrationEnv 设置该环境变量为文件路径时，插件启动后将迁移配置以JSON写入该文件并退出，
+// 供 ev_plugin_cli migrate 在本地读取插件的迁移
+const DumpMigrationEnv = "EV_DUMP_MIGRATION"
+
 // Gormigrate 定义数据库迁移结构
 type Gormigrate struct {
 	// 迁移项列表
@@ -408,6 +408,22 @@ func runDoctorCmd(cmd *cobra.Command, args []string) error {
 			description: "版本控制系统",
 			installCmd:  "https://git-scm.com/downloads",
 		},
+		{
+			name:        "sqlite3",
+			command:     "sqlite3",
+			args:        []string{"-version"},
+			required:    false,
+			description: "SQLite 命令行工具，ev_plugin_cli migrate 使用",
+			installCmd:  "https://www.sqlite.org/download.html",
+		},
+		{
+			name:        "mysql",
+			command:     "mysql",
+			args:        []string{"--version"},
+			required:    false,
+			description: "MySQL 命令行客户端，ev_plugin_cli migrate --mysql-dsn 使用",
+			installCmd:  "https://dev.mysql.com/downloads/",
+		},
 	}
 
 	// 执行检查
@@ -0,0 +1,752 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/1340691923/eve-plugin-sdk-go/build"
+	"github.com/1340691923/eve-plugin-sdk-go/build/migrate"
+	"github.com/1340691923/eve-plugin-sdk-go/enum"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "本地检查插件存储迁移",
+	Long: `在本地预览和试运行插件存储迁移，迁移配置默认通过 go run plugin.json 中的 main_go_file 读取。
+执行迁移依赖 sqlite3 命令行工具，指定 --mysql-dsn 时还依赖 mysql 命令行工具。`,
+}
+
+var migratePlanCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "打印迁移SQL",
+	Long:  `按 --dialect 指定的方言打印每个迁移的迁移语句和回滚语句`,
+	RunE:  runMigratePlanCmd,
+}
+
+var migrateUpCmd = &cobra.Command{
+	Use:   "up",
+	Short: "执行迁移",
+	Long: `对本地SQLite文件（默认为用完即删的临时文件）执行未执行过的迁移，指定 --mysql-dsn 时同时对MySQL执行。
+已执行的迁移记录在 migrations 表中`,
+	RunE: runMigrateUpCmd,
+}
+
+var migrateDownCmd = &cobra.Command{
+	Use:   "down",
+	Short: "回滚迁移",
+	Long: `回滚最近执行的 --steps 个迁移。
+使用临时SQLite文件时会先执行全部迁移再回滚`,
+	RunE: runMigrateDownCmd,
+}
+
+var migrateVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "校验迁移",
+	Long: `检查迁移ID唯一且有序、每个迁移的回滚语句齐全，
+然后在临时SQLite文件上执行全部迁移、逐个回滚并确认表结构恢复原样，最后重新执行全部迁移。
+指定 --mysql-dsn 时对MySQL做同样的校验，请使用空的测试库`,
+	RunE: runMigrateVerifyCmd,
+}
+
+// migrate 命令参数
+var (
+	migrateFile     string
+	migrateDialect  string
+	migrateSqliteDb string
+	migrateMysqlDsn string
+	migrateTo       string
+	migrateSteps    int
+)
+
+// migrationsTable 本地记录已执行迁移的表
+const migrationsTable = "migrations"
+
+func init() {
+	migrateCmd.PersistentFlags().StringVarP(&pluginJsonFile, "plugin-json", "p", "plugin.json", "插件配置文件")
+	migrateCmd.PersistentFlags().StringVarP(&migrateFile, "file", "f", "", "迁移配置JSON文件，为空时通过 go run 插件读取")
+	migrateCmd.PersistentFlags().StringVar(&migrateSqliteDb, "db", "", "SQLite文件路径，为空时使用临时文件")
+	migrateCmd.PersistentFlags().StringVar(&migrateMysqlDsn, "mysql-dsn", "", "MySQL连接串，例如 root:123456@tcp(127.0.0.1:3306)/ev_test")
+
+	migratePlanCmd.Flags().StringVarP(&migrateDialect, "dialect", "d", enum.SqliteDbTyp, "数据库方言 sqlite3 或 mysql")
+	migrateUpCmd.Flags().StringVar(&migrateTo, "to", "", "执行到指定迁移ID（包含），为空时执行全部")
+	migrateDownCmd.Flags().IntVarP(&migrateSteps, "steps", "n", 1, "回滚的迁移个数，0为全部")
+
+	migrateCmd.AddCommand(migratePlanCmd)
+	migrateCmd.AddCommand(migrateUpCmd)
+	migrateCmd.AddCommand(migrateDownCmd)
+	migrateCmd.AddCommand(migrateVerifyCmd)
+	rootCmd.AddCommand(migrateCmd)
+}
+
+// runMigratePlanCmd 执行迁移预览命令
+func runMigratePlanCmd(cmd *cobra.Command, args []string) error {
+	if migrateDialect != enum.SqliteDbTyp && migrateDialect != enum.MysqlDbTyp {
+		return fmt.Errorf("不支持的方言: %s", migrateDialect)
+	}
+	gormigrate, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+	for _, m := range gormigrate.Migrations {
+		ups, downs := migrationSqls(m, migrateDialect)
+		fmt.Printf("-- ==================== %s ====================\n", m.ID)
+		fmt.Println("-- 迁移")
+		printExecSqls(migrateDialect, ups)
+		fmt.Println("-- 回滚")
+		printExecSqls(migrateDialect, downs)
+		fmt.Println()
+	}
+	return nil
+}
+
+// runMigrateUpCmd 执行迁移命令
+func runMigrateUpCmd(cmd *cobra.Command, args []string) error {
+	gormigrate, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+	migrations := gormigrate.Migrations
+	if migrateTo != "" {
+		idx := migrationIndex(migrations, migrateTo)
+		if idx < 0 {
+			return fmt.Errorf("迁移 %s 不存在", migrateTo)
+		}
+		migrations = migrations[:idx+1]
+	}
+	return eachMigrateDb(func(db migrateDb) error {
+		n, err := migrateUp(db, migrations)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ [%s] 执行了 %d 个迁移\n", db.name(), n)
+		return nil
+	})
+}
+
+// runMigrateDownCmd 执行回滚命令
+func runMigrateDownCmd(cmd *cobra.Command, args []string) error {
+	gormigrate, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+	return eachMigrateDb(func(db migrateDb) error {
+		if db.temporary() {
+			fmt.Printf("📋 [%s] 临时数据库，先执行全部迁移\n", db.name())
+			if _, err := migrateUp(db, gormigrate.Migrations); err != nil {
+				return err
+			}
+		}
+		applied, err := appliedMigrations(db)
+		if err != nil {
+			return err
+		}
+		n := 0
+		for i := len(gormigrate.Migrations) - 1; i >= 0; i-- {
+			if migrateSteps > 0 && n >= migrateSteps {
+				break
+			}
+			m := gormigrate.Migrations[i]
+			if !applied[m.ID] {
+				continue
+			}
+			if err := migrateDownOne(db, m); err != nil {
+				return err
+			}
+			n++
+		}
+		fmt.Printf("✅ [%s] 回滚了 %d 个迁移\n", db.name(), n)
+		return nil
+	})
+}
+
+// runMigrateVerifyCmd 执行迁移校验命令
+func runMigrateVerifyCmd(cmd *cobra.Command, args []string) error {
+	gormigrate, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+	fmt.Println("🔍 检查迁移配置...")
+	problems := checkMigrations(gormigrate.Migrations)
+	if len(problems) > 0 {
+		for _, problem := range problems {
+			fmt.Printf("   ❌ %s\n", problem)
+		}
+		return fmt.Errorf("迁移配置检查未通过，共 %d 个问题", len(problems))
+	}
+	fmt.Printf("   ✅ %d 个迁移，ID唯一有序，回滚语句齐全\n", len(gormigrate.Migrations))
+
+	// verify 总是在全新的临时SQLite文件上校验
+	migrateSqliteDb = ""
+	return eachMigrateDb(func(db migrateDb) error {
+		return verifyMigrations(db, gormigrate.Migrations)
+	})
+}
+
+// loadMigrations 读取迁移配置
+func loadMigrations() (*build.Gormigrate, error) {
+	var data []byte
+	var err error
+	if migrateFile != "" {
+		data, err = os.ReadFile(migrateFile)
+		if err != nil {
+			return nil, fmt.Errorf("读取迁移配置失败: %w", err)
+		}
+	} else {
+		data, err = dumpPluginMigrations()
+		if err != nil {
+			return nil, err
+		}
+	}
+	gormigrate := new(build.Gormigrate)
+	if err = json.Unmarshal(data, gormigrate); err != nil {
+		return nil, fmt.Errorf("解析迁移配置失败: %w", err)
+	}
+	return gormigrate, nil
+}
+
+// dumpPluginMigrations 通过 go run 启动插件，由插件将迁移配置写入临时文件
+func dumpPluginMigrations() ([]byte, error) {
+	data, err := os.ReadFile(pluginJsonFile)
+	if err != nil {
+		return nil, fmt.Errorf("读取 %s 失败: %w", pluginJsonFile, err)
+	}
+	pluginJson := new(build.PluginJsonData)
+	if err = json.Unmarshal(data, pluginJson); err != nil {
+		return nil, fmt.Errorf("解析 %s 失败: %w", pluginJsonFile, err)
+	}
+	if pluginJson.MainGoFile == "" {
+		return nil, fmt.Errorf("%s 缺少 main_go_file", pluginJsonFile)
+	}
+
+	dumpFile, err := os.CreateTemp("", "ev_migration_*.json")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	dumpFile.Close()
+	defer os.Remove(dumpFile.Name())
+
+	fmt.Printf("🔍 读取插件迁移配置 (go run %s)...\n", pluginJson.MainGoFile)
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "go", "run", pluginJson.MainGoFile)
+	cmd.Env = append(os.Environ(), build.DumpMigrationEnv+"="+dumpFile.Name())
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("运行插件失败: %w\n%s", err, output)
+	}
+	data, err = os.ReadFile(dumpFile.Name())
+	if err != nil || len(data) == 0 {
+		return nil, fmt.Errorf("插件未导出迁移配置，请确认插件调用了 plugin_server.Serve 且SDK版本支持 %s，或使用 --file 指定迁移配置", build.DumpMigrationEnv)
+	}
+	return data, nil
+}
+
+// migrationSqls 返回迁移在指定方言下的迁移语句和回滚语句
+func migrationSqls(m *build.Migration, dialect string) (ups, downs []*build.ExecSql) {
+	if dialect == enum.MysqlDbTyp {
+		return m.MysqlMigrateSqls, m.MysqlRollback
+	}
+	return m.SqliteMigrateSqls, m.SqliteRollback
+}
+
+// printExecSqls 打印SQL语句
+func printExecSqls(dialect string, sqls []*build.ExecSql) {
+	if len(sqls) == 0 {
+		fmt.Println("-- （无）")
+		return
+	}
+	for _, sql := range sqls {
+		fmt.Println(statement(dialect, sql))
+	}
+}
+
+// statement 将参数代入SQL并以分号结尾
+func statement(dialect string, sql *build.ExecSql) string {
+	s := strings.TrimRight(strings.TrimSpace(inlineArgs(dialect, sql.Sql, sql.Args)), ";")
+	return s + ";"
+}
+
+// inlineArgs 将参数以字面量代入SQL中引号外的?占位符，命令行工具不支持参数绑定
+func inlineArgs(dialect string, sql string, args []interface{}) string {
+	if len(args) == 0 {
+		return sql
+	}
+	var sb strings.Builder
+	var quoteChar rune
+	escaped := false
+	argIdx := 0
+	for _, r := range sql {
+		switch {
+		case escaped:
+			escaped = false
+		case quoteChar != 0:
+			if r == '\\' && quoteChar != '`' && dialect == enum.MysqlDbTyp {
+				// MySQL字符串中的反斜杠转义下一个字符
+				escaped = true
+			} else if r == quoteChar {
+				quoteChar = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quoteChar = r
+		case r == '?' && argIdx < len(args):
+			sb.WriteString(sqlLiteral(dialect, args[argIdx]))
+			argIdx++
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// sqlLiteral 将参数转换为方言对应的SQL字面量
+func sqlLiteral(dialect string, value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return migrate.QuoteString(migrate.Dialect(dialect), v)
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+// migrationIndex 返回迁移ID所在下标，不存在时返回-1
+func migrationIndex(migrations []*build.Migration, id string) int {
+	for i, m := range migrations {
+		if m.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// migrateDb 执行迁移的本地数据库，通过命令行工具执行SQL
+type migrateDb interface {
+	// name 数据库说明
+	name() string
+	// dialect 数据库方言
+	dialect() string
+	// temporary 是否为用完即删的临时数据库
+	temporary() bool
+	// exec 执行一组SQL，SQLite在同一事务内执行
+	exec(sqls []string) error
+	// query 执行查询，返回按行拆分的结果
+	query(sql string) ([]string, error)
+	// close 关闭数据库，临时数据库会被删除
+	close()
+}
+
+// eachMigrateDb 依次对SQLite和指定的MySQL执行fn
+func eachMigrateDb(fn func(db migrateDb) error) error {
+	dbs := []func() (migrateDb, error){openSqliteDb}
+	if migrateMysqlDsn != "" {
+		dbs = append(dbs, openMysqlDb)
+	}
+	for _, open := range dbs {
+		db, err := open()
+		if err != nil {
+			return err
+		}
+		err = fn(db)
+		db.close()
+		if err != nil {
+			return fmt.Errorf("[%s] %w", db.name(), err)
+		}
+	}
+	return nil
+}
+
+// sqliteDb 通过 sqlite3 命令行工具操作的SQLite文件
+type sqliteDb struct {
+	path string
+	temp bool
+}
+
+// openSqliteDb 打开 --db 指定的SQLite文件，未指定时创建临时文件
+func openSqliteDb() (migrateDb, error) {
+	if _, err := exec.LookPath("sqlite3"); err != nil {
+		return nil, fmt.Errorf("未找到 sqlite3 命令行工具，请先安装: https://www.sqlite.org/download.html")
+	}
+	if migrateSqliteDb != "" {
+		return &sqliteDb{path: migrateSqliteDb}, nil
+	}
+	f, err := os.CreateTemp("", "ev_migrate_*.db")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	f.Close()
+	return &sqliteDb{path: f.Name(), temp: true}, nil
+}
+
+func (this *sqliteDb) name() string {
+	if this.temp {
+		return "sqlite3 " + filepath.Base(this.path) + "（临时）"
+	}
+	return "sqlite3 " + this.path
+}
+
+func (this *sqliteDb) dialect() string { return enum.SqliteDbTyp }
+
+func (this *sqliteDb) temporary() bool { return this.temp }
+
+func (this *sqliteDb) exec(sqls []string) error {
+	script := "BEGIN;\n" + strings.Join(sqls, "\n") + "\nCOMMIT;\n"
+	_, err := runSqlCli(exec.Command("sqlite3", "-bail", this.path), nil, script)
+	return err
+}
+
+func (this *sqliteDb) query(sql string) ([]string, error) {
+	return runSqlCli(exec.Command("sqlite3", "-bail", "-noheader", "-list", this.path), nil, sql)
+}
+
+func (this *sqliteDb) close() {
+	if this.temp {
+		os.Remove(this.path)
+	}
+}
+
+// mysqlDb 通过 mysql 命令行工具操作的MySQL库
+type mysqlDb struct {
+	args     []string
+	password string
+	database string
+}
+
+// mysqlDsnRe 匹配 user:password@tcp(host:port)/dbname?params 格式的连接串
+var mysqlDsnRe = regexp.MustCompile(`^(?:([^:@]*)(?::(.*))?@)?(?:(tcp|unix)\(([^)]*)\))?/([^?]+)(?:\?.*)?$`)
+
+// openMysqlDb 解析 --mysql-dsn 并检查 mysql 命令行工具
+func openMysqlDb() (migrateDb, error) {
+	if _, err := exec.LookPath("mysql"); err != nil {
+		return nil, fmt.Errorf("未找到 mysql 命令行工具，请先安装MySQL客户端")
+	}
+	match := mysqlDsnRe.FindStringSubmatch(migrateMysqlDsn)
+	if match == nil {
+		return nil, fmt.Errorf("无效的MySQL连接串: %s", migrateMysqlDsn)
+	}
+	db := &mysqlDb{password: match[2], database: match[5]}
+	db.args = []string{"--batch", "--skip-column-names", "--default-character-set=utf8mb4"}
+	if match[1] != "" {
+		db.args = append(db.args, "-u", match[1])
+	}
+	if match[3] == "unix" {
+		db.args = append(db.args, "-S", match[4])
+	} else if match[4] != "" {
+		host, port := match[4], ""
+		if i := strings.LastIndex(host, ":"); i >= 0 {
+			host, port = host[:i], host[i+1:]
+		}
+		db.args = append(db.args, "--protocol=TCP", "-h", host)
+		if port != "" {
+			db.args = append(db.args, "-P", port)
+		}
+	}
+	db.args = append(db.args, db.database)
+	return db, nil
+}
+
+func (this *mysqlDb) name() string { return "mysql " + this.database }
+
+func (this *mysqlDb) dialect() string { return enum.MysqlDbTyp }
+
+func (this *mysqlDb) temporary() bool { return false }
+
+func (this *mysqlDb) env() []string {
+	// 通过环境变量传递密码，避免出现在进程参数中
+	return []string{"MYSQL_PWD=" + this.password}
+}
+
+func (this *mysqlDb) exec(sqls []string) error {
+	// MySQL的DDL会隐式提交，无法整体回滚
+	_, err := runSqlCli(exec.Command("mysql", this.args...), this.env(), strings.Join(sqls, "\n"))
+	return err
+}
+
+func (this *mysqlDb) query(sql string) ([]string, error) {
+	return runSqlCli(exec.Command("mysql", this.args...), this.env(), sql)
+}
+
+func (this *mysqlDb) close() {}
+
+// runSqlCli 运行SQL命令行工具，script从标准输入传入，返回按行拆分的输出
+func runSqlCli(cmd *exec.Cmd, env []string, script string) ([]string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdin = strings.NewReader(script)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return nil, errors.New(msg)
+	}
+	var lines []string
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		if line = strings.TrimRight(line, "\r"); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, nil
+}
+
+// appliedMigrations 返回已执行的迁移ID，记录表不存在时创建
+func appliedMigrations(db migrateDb) (map[string]bool, error) {
+	createSql := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (id VARCHAR(255) NOT NULL PRIMARY KEY);", migrationsTable)
+	if err := db.exec([]string{createSql}); err != nil {
+		return nil, fmt.Errorf("创建迁移记录表失败: %w", err)
+	}
+	ids, err := db.query(fmt.Sprintf("SELECT id FROM %s;", migrationsTable))
+	if err != nil {
+		return nil, fmt.Errorf("读取迁移记录失败: %w", err)
+	}
+	applied := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		applied[id] = true
+	}
+	return applied, nil
+}
+
+// migrateUp 按顺序执行未执行过的迁移，返回执行的个数
+func migrateUp(db migrateDb, migrations []*build.Migration) (int, error) {
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, m := range migrations {
+		if applied[m.ID] {
+			continue
+		}
+		if err = migrateUpOne(db, m); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// migrateUpOne 执行单个迁移并记录
+func migrateUpOne(db migrateDb, m *build.Migration) error {
+	ups, _ := migrationSqls(m, db.dialect())
+	sqls := make([]string, 0, len(ups)+1)
+	for _, sql := range ups {
+		sqls = append(sqls, statement(db.dialect(), sql))
+	}
+	sqls = append(sqls, fmt.Sprintf("INSERT INTO %s (id) VALUES (%s);", migrationsTable, sqlLiteral(db.dialect(), m.ID)))
+	if err := db.exec(sqls); err != nil {
+		return fmt.Errorf("迁移 %s 执行失败: %w", m.ID, err)
+	}
+	fmt.Printf("   ⬆️  %s\n", m.ID)
+	return nil
+}
+
+// migrateDownOne 回滚单个迁移并删除记录
+func migrateDownOne(db migrateDb, m *build.Migration) error {
+	_, downs := migrationSqls(m, db.dialect())
+	sqls := make([]string, 0, len(downs)+1)
+	for _, sql := range downs {
+		sqls = append(sqls, statement(db.dialect(), sql))
+	}
+	sqls = append(sqls, fmt.Sprintf("DELETE FROM %s WHERE id = %s;", migrationsTable, sqlLiteral(db.dialect(), m.ID)))
+	if err := db.exec(sqls); err != nil {
+		return fmt.Errorf("迁移 %s 回滚失败: %w", m.ID, err)
+	}
+	fmt.Printf("   ⬇️  %s\n", m.ID)
+	return nil
+}
+
+// checkMigrations 静态检查迁移配置，返回发现的问题
+func checkMigrations(migrations []*build.Migration) []string {
+	var problems []string
+	seen := map[string]bool{}
+	for i, m := range migrations {
+		if m.ID == "" {
+			problems = append(problems, fmt.Sprintf("第 %d 个迁移的ID为空", i+1))
+			continue
+		}
+		if seen[m.ID] {
+			problems = append(problems, fmt.Sprintf("迁移ID %s 重复", m.ID))
+		} else if i > 0 && migrations[i-1].ID != "" && compareMigrationIds(migrations[i-1].ID, m.ID) >= 0 {
+			problems = append(problems, fmt.Sprintf("迁移ID %s 排在 %s 之后，但版本不大于它", m.ID, migrations[i-1].ID))
+		}
+		seen[m.ID] = true
+		for _, dialect := range []string{enum.SqliteDbTyp, enum.MysqlDbTyp} {
+			ups, downs := migrationSqls(m, dialect)
+			if len(ups) == 0 {
+				problems = append(problems, fmt.Sprintf("迁移 %s 缺少 %s 迁移语句", m.ID, dialect))
+			} else if len(downs) == 0 {
+				problems = append(problems, fmt.Sprintf("迁移 %s 缺少 %s 回滚语句", m.ID, dialect))
+			}
+		}
+	}
+	return problems
+}
+
+// migrationIdPartRe 将迁移ID拆分为数字和非数字片段
+var migrationIdPartRe = regexp.MustCompile(`\d+|\D+`)
+
+// compareMigrationIds 按自然顺序比较迁移ID，数字片段按数值比较，例如 v0.0.9 < v0.0.10
+func compareMigrationIds(a, b string) int {
+	pa, pb := migrationIdPartRe.FindAllString(a, -1), migrationIdPartRe.FindAllString(b, -1)
+	for i := 0; i < len(pa) && i < len(pb); i++ {
+		na, errA := strconv.ParseUint(pa[i], 10, 64)
+		nb, errB := strconv.ParseUint(pb[i], 10, 64)
+		if errA == nil && errB == nil {
+			if na != nb {
+				if na < nb {
+					return -1
+				}
+				return 1
+			}
+			continue
+		}
+		if c := strings.Compare(pa[i], pb[i]); c != 0 {
+			return c
+		}
+	}
+	return len(pa) - len(pb)
+}
+
+// verifyMigrations 执行全部迁移并记录每步前的表结构，再逐个回滚检查表结构是否恢复，最后重新执行全部迁移
+func verifyMigrations(db migrateDb, migrations []*build.Migration) error {
+	fmt.Printf("🧪 [%s] 校验迁移...\n", db.name())
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return err
+	}
+	if len(applied) > 0 {
+		return fmt.Errorf("数据库已执行过迁移，请使用空的测试库")
+	}
+
+	snapshots := make([][]string, len(migrations))
+	for i, m := range migrations {
+		if snapshots[i], err = schemaSnapshot(db); err != nil {
+			return err
+		}
+		if err = migrateUpOne(db, m); err != nil {
+			return err
+		}
+	}
+	finalSnapshot, err := schemaSnapshot(db)
+	if err != nil {
+		return err
+	}
+
+	var problems []string
+	// 已报告的差异会延续到之前的迁移，只报告新出现的差异
+	reported := map[string]bool{}
+	for i := len(migrations) - 1; i >= 0; i-- {
+		if err = migrateDownOne(db, migrations[i]); err != nil {
+			return err
+		}
+		snapshot, err := schemaSnapshot(db)
+		if err != nil {
+			return err
+		}
+		var diff []string
+		for _, line := range diffSnapshots(snapshots[i], snapshot) {
+			if !reported[line] {
+				reported[line] = true
+				diff = append(diff, line)
+			}
+		}
+		if len(diff) > 0 {
+			problems = append(problems, fmt.Sprintf("迁移 %s 回滚后表结构未恢复:\n      %s", migrations[i].ID, strings.Join(diff, "\n      ")))
+		}
+	}
+
+	for _, m := range migrations {
+		if err = migrateUpOne(db, m); err != nil {
+			return fmt.Errorf("回滚后重新执行失败: %w", err)
+		}
+	}
+	snapshot, err := schemaSnapshot(db)
+	if err != nil {
+		return err
+	}
+	if diff := diffSnapshots(finalSnapshot, snapshot); len(diff) > 0 {
+		problems = append(problems, fmt.Sprintf("重新执行后表结构与首次执行不一致:\n      %s", strings.Join(diff, "\n      ")))
+	}
+
+	if len(problems) > 0 {
+		for _, problem := range problems {
+			fmt.Printf("   ❌ %s\n", problem)
+		}
+		return fmt.Errorf("迁移校验未通过，共 %d 个问题", len(problems))
+	}
+	fmt.Printf("✅ [%s] 全部 %d 个迁移执行、回滚、重新执行均正常\n", db.name(), len(migrations))
+	return nil
+}
+
+// schemaSnapshot 返回当前的表结构，每行为一列或一个索引，列按名称排序以忽略列顺序
+func schemaSnapshot(db migrateDb) ([]string, error) {
+	var queries []string
+	if db.dialect() == enum.MysqlDbTyp {
+		queries = []string{
+			fmt.Sprintf(`SELECT CONCAT_WS('|', 'column', table_name, column_name, column_type, is_nullable, IFNULL(column_default, 'NULL'), column_key)
+FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name != '%s' ORDER BY table_name, column_name;`, migrationsTable),
+			fmt.Sprintf(`SELECT CONCAT_WS('|', 'index', table_name, index_name, non_unique, GROUP_CONCAT(column_name ORDER BY seq_in_index))
+FROM information_schema.statistics WHERE table_schema = DATABASE() AND table_name != '%s'
+GROUP BY table_name, index_name, non_unique ORDER BY table_name, index_name;`, migrationsTable),
+		}
+	} else {
+		queries = []string{
+			fmt.Sprintf(`SELECT 'column', m.name, p.name, p.type, p."notnull", IFNULL(p.dflt_value, 'NULL'), p.pk
+FROM sqlite_master m JOIN pragma_table_info(m.name) p
+WHERE m.type = 'table' AND m.name NOT LIKE 'sqlite_%%' AND m.name != '%s' ORDER BY m.name, p.name;`, migrationsTable),
+			`SELECT 'index', m.tbl_name, m.name, group_concat(i.name)
+FROM sqlite_master m JOIN pragma_index_info(m.name) i
+WHERE m.type = 'index' AND m.name NOT LIKE 'sqlite_%' GROUP BY m.name ORDER BY m.tbl_name, m.name;`,
+		}
+	}
+	var snapshot []string
+	for _, query := range queries {
+		lines, err := db.query(query)
+		if err != nil {
+			return nil, fmt.Errorf("读取表结构失败: %w", err)
+		}
+		snapshot = append(snapshot, lines...)
+	}
+	return snapshot, nil
+}
+
+// diffSnapshots 比较两次表结构，返回缺少（-）和多出（+）的行
+func diffSnapshots(expected, actual []string) []string {
+	count := map[string]int{}
+	for _, line := range expected {
+		count[line]++
+	}
+	for _, line := range actual {
+		count[line]--
+	}
+	var diff []string
+	for line, n := range count {
+		if n > 0 {
+			diff = append(diff, "- "+line)
+		} else if n < 0 {
+			diff = append(diff, "+ "+line)
+		}
+	}
+	sort.Strings(diff)
+	return diff
+}
@@ -0,0 +1,393 @@
+package bson
+
+// 导入所需的包
+import (
+	// 字节处理包
+	"bytes"
+	// Base64编码包
+	"encoding/base64"
+	// JSON处理包
+	"encoding/json"
+	// 格式化包
+	"fmt"
+	// 数学计算包
+	"math"
+	// 反射包
+	"reflect"
+	// 排序包
+	"sort"
+	// 字符串转换包
+	"strconv"
+	// 字符串处理包
+	"strings"
+	// 时间处理包
+	"time"
+
+	// 原始类型包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/primitive"
+	// 错误处理包
+	"github.com/pkg/errors"
+)
+
+// maxRelaxedDate 宽松模式下以ISO-8601字符串表示的最大毫秒时间戳（9999-12-31T23:59:59.999Z）
+const maxRelaxedDate = 253402300799999
+
+// MarshalExtJSON 将值编码为MongoDB扩展JSON
+// 规范模式（canonical）下所有数值、日期都带类型包装，可无损还原；宽松模式（relaxed）下数值以JSON数字、日期以ISO-8601字符串表示，便于阅读
+// D和结构体按元素顺序输出，M按键名排序输出
+// 参数：
+//   - val: 要编码的值
+//   - canonical: 是否使用规范模式
+//   - escapeHTML: 是否转义HTML字符
+//
+// 返回：
+//   - []byte: 扩展JSON
+//   - error: 错误信息
+func MarshalExtJSON(val interface{}, canonical, escapeHTML bool) ([]byte, error) {
+	enc := &extJSONEncoder{canonical: canonical, escapeHTML: escapeHTML}
+	if err := enc.encode(val); err != nil {
+		return nil, err
+	}
+	return enc.buf.Bytes(), nil
+}
+
+// extJSONEncoder 扩展JSON编码器
+type extJSONEncoder struct {
+	buf        bytes.Buffer
+	canonical  bool
+	escapeHTML bool
+}
+
+// encode 编码单个值
+func (this *extJSONEncoder) encode(val interface{}) error {
+	switch v := val.(type) {
+	case nil, primitive.Null:
+		this.buf.WriteString("null")
+	case bool:
+		this.buf.WriteString(strconv.FormatBool(v))
+	case string:
+		return this.writeString(v)
+	case int8:
+		this.writeInt32(int32(v))
+	case int16:
+		this.writeInt32(int32(v))
+	case int32:
+		this.writeInt32(v)
+	case uint8:
+		this.writeInt32(int32(v))
+	case uint16:
+		this.writeInt32(int32(v))
+	case int:
+		this.writeInt(int64(v))
+	case int64:
+		this.writeInt64(v)
+	case uint32:
+		this.writeInt(int64(v))
+	case uint:
+		return this.writeUint(uint64(v))
+	case uint64:
+		return this.writeUint(v)
+	case float32:
+		this.writeDouble(float64(v))
+	case float64:
+		this.writeDouble(v)
+	case json.Number:
+		this.buf.WriteString(v.String())
+	case primitive.ObjectID:
+		return this.writeWrapper("$oid", func() error { return this.writeString(v.Hex()) })
+	case primitive.DateTime:
+		this.writeDateTime(v)
+	case time.Time:
+		this.writeDateTime(primitive.NewDateTimeFromTime(v))
+	case primitive.Decimal128:
+		return this.writeWrapper("$numberDecimal", func() error { return this.writeString(v.String()) })
+	case primitive.Binary:
+		this.writeBinary(v.Subtype, v.Data)
+	case []byte:
+		this.writeBinary(0, v)
+	case primitive.Regex:
+		return this.writeRegex(v)
+	case primitive.Timestamp:
+		this.buf.WriteString(fmt.Sprintf(`{"$timestamp":{"t":%d,"i":%d}}`, v.T, v.I))
+	case primitive.MinKey:
+		this.buf.WriteString(`{"$minKey":1}`)
+	case primitive.MaxKey:
+		this.buf.WriteString(`{"$maxKey":1}`)
+	case primitive.Undefined:
+		this.buf.WriteString(`{"$undefined":true}`)
+	case primitive.Symbol:
+		return this.writeWrapper("$symbol", func() error { return this.writeString(string(v)) })
+	case primitive.JavaScript:
+		return this.writeWrapper("$code", func() error { return this.writeString(string(v)) })
+	case primitive.CodeWithScope:
+		this.buf.WriteString(`{"$code":`)
+		if err := this.writeString(string(v.Code)); err != nil {
+			return err
+		}
+		this.buf.WriteString(`,"$scope":`)
+		if err := this.encode(v.Scope); err != nil {
+			return err
+		}
+		this.buf.WriteByte('}')
+	case primitive.DBPointer:
+		this.buf.WriteString(`{"$dbPointer":{"$ref":`)
+		if err := this.writeString(v.DB); err != nil {
+			return err
+		}
+		this.buf.WriteString(`,"$id":{"$oid":"` + v.Pointer.Hex() + `"}}}`)
+	case D:
+		return this.writeD(v)
+	case M:
+		return this.writeMap(v)
+	case map[string]interface{}:
+		return this.writeMap(v)
+	case A:
+		return this.writeArray(v)
+	case []interface{}:
+		return this.writeArray(v)
+	default:
+		return this.encodeReflect(reflect.ValueOf(val))
+	}
+	return nil
+}
+
+// encodeReflect 通过反射编码其他类型：指针取值，字符串键的map编码为文档，切片和数组编码为数组，结构体按bson标签编码为文档，其余类型按普通JSON编码
+func (this *extJSONEncoder) encodeReflect(rv reflect.Value) error {
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			this.buf.WriteString("null")
+			return nil
+		}
+		return this.encode(rv.Elem().Interface())
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			break
+		}
+		if rv.IsNil() {
+			this.buf.WriteString("null")
+			return nil
+		}
+		m := make(map[string]interface{}, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			m[iter.Key().String()] = iter.Value().Interface()
+		}
+		return this.writeMap(m)
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			this.buf.WriteString("null")
+			return nil
+		}
+		arr := make([]interface{}, rv.Len())
+		for i := range arr {
+			arr[i] = rv.Index(i).Interface()
+		}
+		return this.writeArray(arr)
+	case reflect.String:
+		return this.writeString(rv.String())
+	case reflect.Bool:
+		this.buf.WriteString(strconv.FormatBool(rv.Bool()))
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		this.writeInt(rv.Int())
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return this.writeUint(rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		this.writeDouble(rv.Float())
+		return nil
+	case reflect.Struct:
+		doc, err := marshalStruct(rv)
+		if err != nil {
+			return err
+		}
+		return this.writeD(doc)
+	}
+
+	b, err := json.Marshal(rv.Interface())
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	this.buf.Write(b)
+	return nil
+}
+
+// writeString 写入JSON字符串
+func (this *extJSONEncoder) writeString(s string) error {
+	enc := json.NewEncoder(&this.buf)
+	enc.SetEscapeHTML(this.escapeHTML)
+	if err := enc.Encode(s); err != nil {
+		return errors.WithStack(err)
+	}
+	// Encode会在末尾追加换行符
+	this.buf.Truncate(this.buf.Len() - 1)
+	return nil
+}
+
+// writeWrapper 写入单键类型包装 {"$key":value}
+func (this *extJSONEncoder) writeWrapper(key string, writeValue func() error) error {
+	this.buf.WriteString(`{"` + key + `":`)
+	if err := writeValue(); err != nil {
+		return err
+	}
+	this.buf.WriteByte('}')
+	return nil
+}
+
+// writeInt 按取值范围写入int32或int64
+func (this *extJSONEncoder) writeInt(v int64) {
+	if v >= math.MinInt32 && v <= math.MaxInt32 {
+		this.writeInt32(int32(v))
+		return
+	}
+	this.writeInt64(v)
+}
+
+// writeUint 写入无符号整数，超出int64范围时报错
+func (this *extJSONEncoder) writeUint(v uint64) error {
+	if v > math.MaxInt64 {
+		return errors.Errorf("%d 超出BSON int64的取值范围", v)
+	}
+	this.writeInt(int64(v))
+	return nil
+}
+
+// writeInt32 写入int32
+func (this *extJSONEncoder) writeInt32(v int32) {
+	if this.canonical {
+		this.buf.WriteString(`{"$numberInt":"` + strconv.FormatInt(int64(v), 10) + `"}`)
+		return
+	}
+	this.buf.WriteString(strconv.FormatInt(int64(v), 10))
+}
+
+// writeInt64 写入int64
+func (this *extJSONEncoder) writeInt64(v int64) {
+	if this.canonical {
+		this.buf.WriteString(`{"$numberLong":"` + strconv.FormatInt(v, 10) + `"}`)
+		return
+	}
+	this.buf.WriteString(strconv.FormatInt(v, 10))
+}
+
+// writeDouble 写入double，整数值保留小数点以便解码时仍识别为double
+func (this *extJSONEncoder) writeDouble(v float64) {
+	var s string
+	switch {
+	case math.IsNaN(v):
+		s = "NaN"
+	case math.IsInf(v, 1):
+		s = "Infinity"
+	case math.IsInf(v, -1):
+		s = "-Infinity"
+	default:
+		s = formatDouble(v)
+		if !this.canonical {
+			this.buf.WriteString(s)
+			return
+		}
+	}
+	this.buf.WriteString(`{"$numberDouble":"` + s + `"}`)
+}
+
+// formatDouble 格式化double
+func formatDouble(v float64) string {
+	s := strconv.FormatFloat(v, 'G', -1, 64)
+	if !strings.ContainsAny(s, ".E") {
+		s += ".0"
+	}
+	return s
+}
+
+// writeDateTime 写入日期，宽松模式下1970年至9999年之间的日期以ISO-8601字符串表示
+func (this *extJSONEncoder) writeDateTime(v primitive.DateTime) {
+	if !this.canonical && v >= 0 && v <= maxRelaxedDate {
+		s := v.Time().UTC().Format("2006-01-02T15:04:05.999Z07:00")
+		this.buf.WriteString(`{"$date":"` + s + `"}`)
+		return
+	}
+	this.buf.WriteString(`{"$date":{"$numberLong":"` + strconv.FormatInt(int64(v), 10) + `"}}`)
+}
+
+// writeBinary 写入二进制数据
+func (this *extJSONEncoder) writeBinary(subtype byte, data []byte) {
+	this.buf.WriteString(`{"$binary":{"base64":"`)
+	this.buf.WriteString(base64.StdEncoding.EncodeToString(data))
+	this.buf.WriteString(fmt.Sprintf(`","subType":"%02x"}}`, subtype))
+}
+
+// writeRegex 写入正则表达式，选项保持原有顺序以便无损还原
+func (this *extJSONEncoder) writeRegex(v primitive.Regex) error {
+	this.buf.WriteString(`{"$regularExpression":{"pattern":`)
+	if err := this.writeString(v.Pattern); err != nil {
+		return err
+	}
+	this.buf.WriteString(`,"options":`)
+	if err := this.writeString(v.Options); err != nil {
+		return err
+	}
+	this.buf.WriteString(`}}`)
+	return nil
+}
+
+// writeD 按顺序写入有序文档
+func (this *extJSONEncoder) writeD(d D) error {
+	if d == nil {
+		this.buf.WriteString("null")
+		return nil
+	}
+	this.buf.WriteByte('{')
+	for i, e := range d {
+		if i > 0 {
+			this.buf.WriteByte(',')
+		}
+		if err := this.writeString(e.Key); err != nil {
+			return err
+		}
+		this.buf.WriteByte(':')
+		if err := this.encode(e.Value); err != nil {
+			return errors.Wrapf(err, "编码字段 %s 失败", e.Key)
+		}
+	}
+	this.buf.WriteByte('}')
+	return nil
+}
+
+// writeMap 按键名排序写入无序文档
+func (this *extJSONEncoder) writeMap(m map[string]interface{}) error {
+	if m == nil {
+		this.buf.WriteString("null")
+		return nil
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	d := make(D, 0, len(keys))
+	for _, k := range keys {
+		d = append(d, E{Key: k, Value: m[k]})
+	}
+	return this.writeD(d)
+}
+
+// writeArray 写入数组
+func (this *extJSONEncoder) writeArray(arr []interface{}) error {
+	if arr == nil {
+		this.buf.WriteString("null")
+		return nil
+	}
+	this.buf.WriteByte('[')
+	for i, v := range arr {
+		if i > 0 {
+			this.buf.WriteByte(',')
+		}
+		if err := this.encode(v); err != nil {
+			return err
+		}
+	}
+	this.buf.WriteByte(']')
+	return nil
+}
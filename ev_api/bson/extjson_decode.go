@@ -0,0 +1,421 @@
+package bson
+
+// 导入所需的包
+import (
+	// 字节处理包
+	"bytes"
+	// Base64编码包
+	"encoding/base64"
+	// 十六进制编码包
+	"encoding/hex"
+	// JSON处理包
+	"encoding/json"
+	// IO包
+	"io"
+	// 数学计算包
+	"math"
+	// 字符串转换包
+	"strconv"
+	// 字符串处理包
+	"strings"
+	// 时间处理包
+	"time"
+
+	// 原始类型包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/primitive"
+	// 错误处理包
+	"github.com/pkg/errors"
+)
+
+// UnmarshalExtJSON 将MongoDB扩展JSON解码到val
+// 规范模式和宽松模式的输入都可以解析，canonical仅为与官方驱动保持签名一致；类型包装会还原为对应的原始类型，
+// 不带包装的整数按取值范围还原为int32或int64，带小数点或指数的数字还原为float64
+// val支持 *D、*M、*A、*[]D、*[]M、*[]interface{}、*map[string]interface{} 和 *interface{}，
+// 解码到*D和*interface{}时嵌套文档为D，其余情况嵌套文档为M，数组均为A；其他类型的目标（如结构体指针）通过Unmarshal解码
+// 参数：
+//   - data: 扩展JSON
+//   - canonical: 是否为规范模式
+//   - val: 解码目标指针
+//
+// 返回：
+//   - error: 错误信息
+func UnmarshalExtJSON(data []byte, canonical bool, val interface{}) error {
+	v, err := decodeExtJSON(data)
+	if err != nil {
+		return err
+	}
+
+	switch out := val.(type) {
+	case *interface{}:
+		*out = v
+	case *D:
+		doc, ok := v.(D)
+		if v != nil && !ok {
+			return errors.Errorf("扩展JSON不是文档，无法解码到%T", val)
+		}
+		*out = doc
+	case *M:
+		doc, err := toM(v, val)
+		if err != nil {
+			return err
+		}
+		*out = doc
+	case *map[string]interface{}:
+		doc, err := toM(v, val)
+		if err != nil {
+			return err
+		}
+		*out = doc
+	case *A, *[]interface{}, *[]D, *[]M:
+		arr, ok := v.(A)
+		if v != nil && !ok {
+			return errors.Errorf("扩展JSON不是数组，无法解码到%T", val)
+		}
+		return assignArray(arr, val)
+	default:
+		return Unmarshal(v, val)
+	}
+	return nil
+}
+
+// assignArray 将数组赋值到切片类型的目标
+func assignArray(arr A, val interface{}) error {
+	switch out := val.(type) {
+	case *A:
+		*out = mapDocs(arr).(A)
+	case *[]interface{}:
+		*out = mapDocs(arr).(A)
+	case *[]D:
+		if arr == nil {
+			*out = nil
+			return nil
+		}
+		*out = make([]D, 0, len(arr))
+		for i, item := range arr {
+			doc, ok := item.(D)
+			if item != nil && !ok {
+				return errors.Errorf("第%d个元素不是文档", i)
+			}
+			*out = append(*out, doc)
+		}
+	case *[]M:
+		if arr == nil {
+			*out = nil
+			return nil
+		}
+		*out = make([]M, 0, len(arr))
+		for i, item := range arr {
+			doc, err := toM(item, val)
+			if err != nil {
+				return errors.Wrapf(err, "第%d个元素", i)
+			}
+			*out = append(*out, doc)
+		}
+	}
+	return nil
+}
+
+// toM 将解码结果转换为M
+func toM(v interface{}, val interface{}) (M, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if _, ok := v.(D); !ok {
+		return nil, errors.Errorf("扩展JSON不是文档，无法解码到%T", val)
+	}
+	return mapDocs(v).(M), nil
+}
+
+// mapDocs 递归地将D转换为M
+func mapDocs(v interface{}) interface{} {
+	switch val := v.(type) {
+	case D:
+		m := make(M, len(val))
+		for _, e := range val {
+			m[e.Key] = mapDocs(e.Value)
+		}
+		return m
+	case A:
+		for i := range val {
+			val[i] = mapDocs(val[i])
+		}
+		return val
+	case primitive.CodeWithScope:
+		val.Scope = mapDocs(val.Scope)
+		return val
+	}
+	return v
+}
+
+// decodeExtJSON 解码扩展JSON，文档保持字段顺序解码为D
+func decodeExtJSON(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	v, err := decodeExtJSONValue(dec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = dec.Token(); err != io.EOF {
+		return nil, errors.New("扩展JSON末尾存在多余的内容")
+	}
+	return v, nil
+}
+
+// decodeExtJSONValue 从解码器中读取一个值
+func decodeExtJSONValue(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		if t == '[' {
+			arr := A{}
+			for dec.More() {
+				item, err := decodeExtJSONValue(dec)
+				if err != nil {
+					return nil, err
+				}
+				arr = append(arr, item)
+			}
+			if _, err = dec.Token(); err != nil {
+				return nil, errors.WithStack(err)
+			}
+			return arr, nil
+		}
+
+		doc := D{}
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			key, _ := keyTok.(string)
+			value, err := decodeExtJSONValue(dec)
+			if err != nil {
+				return nil, errors.Wrapf(err, "解码字段 %s 失败", key)
+			}
+			doc = append(doc, E{Key: key, Value: value})
+		}
+		if _, err = dec.Token(); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if len(doc) > 0 && strings.HasPrefix(doc[0].Key, "$") {
+			if v, ok, err := decodeWrapper(doc); ok || err != nil {
+				return v, err
+			}
+		}
+		return doc, nil
+	case json.Number:
+		return decodeNumber(t), nil
+	default:
+		return t, nil
+	}
+}
+
+// decodeNumber 按宽松模式的规则解析不带类型包装的数字
+func decodeNumber(n json.Number) interface{} {
+	s := n.String()
+	if !strings.ContainsAny(s, ".eE") {
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			if i >= math.MinInt32 && i <= math.MaxInt32 {
+				return int32(i)
+			}
+			return i
+		}
+	}
+	f, _ := strconv.ParseFloat(s, 64)
+	return f
+}
+
+// decodeWrapper 将类型包装还原为原始类型，doc不是已知的类型包装时ok为false
+func decodeWrapper(doc D) (v interface{}, ok bool, err error) {
+	key, value := doc[0].Key, doc[0].Value
+
+	if len(doc) == 2 {
+		if key == "$code" && doc[1].Key == "$scope" {
+			code, isStr := value.(string)
+			if !isStr {
+				return nil, true, errors.New("$code 必须是字符串")
+			}
+			return primitive.CodeWithScope{Code: primitive.JavaScript(code), Scope: doc[1].Value}, true, nil
+		}
+		if key == "$binary" && doc[1].Key == "$type" {
+			b, err := decodeBinary(D{{Key: "base64", Value: value}, {Key: "subType", Value: doc[1].Value}})
+			return b, true, err
+		}
+		return nil, false, nil
+	}
+	if len(doc) != 1 {
+		return nil, false, nil
+	}
+
+	switch key {
+	case "$oid":
+		s, isStr := value.(string)
+		if !isStr {
+			return nil, true, errors.New("$oid 必须是字符串")
+		}
+		oid, err := primitive.ObjectIDFromHex(s)
+		return oid, true, errors.WithStack(err)
+	case "$numberInt":
+		s, isStr := value.(string)
+		if !isStr {
+			return nil, true, errors.New("$numberInt 必须是字符串")
+		}
+		i, err := strconv.ParseInt(s, 10, 32)
+		return int32(i), true, errors.WithStack(err)
+	case "$numberLong":
+		s, isStr := value.(string)
+		if !isStr {
+			return nil, true, errors.New("$numberLong 必须是字符串")
+		}
+		i, err := strconv.ParseInt(s, 10, 64)
+		return i, true, errors.WithStack(err)
+	case "$numberDouble":
+		s, isStr := value.(string)
+		if !isStr {
+			return nil, true, errors.New("$numberDouble 必须是字符串")
+		}
+		f, err := parseDouble(s)
+		return f, true, err
+	case "$numberDecimal":
+		s, isStr := value.(string)
+		if !isStr {
+			return nil, true, errors.New("$numberDecimal 必须是字符串")
+		}
+		d, err := primitive.ParseDecimal128(s)
+		return d, true, errors.WithStack(err)
+	case "$date":
+		dt, err := decodeDate(value)
+		return dt, true, err
+	case "$binary":
+		b, err := decodeBinary(value)
+		return b, true, err
+	case "$regularExpression":
+		fields, isDoc := value.(D)
+		if !isDoc {
+			return nil, true, errors.New("$regularExpression 必须是文档")
+		}
+		m := fields.Map()
+		pattern, _ := m["pattern"].(string)
+		options, _ := m["options"].(string)
+		return primitive.Regex{Pattern: pattern, Options: options}, true, nil
+	case "$timestamp":
+		fields, isDoc := value.(D)
+		if !isDoc {
+			return nil, true, errors.New("$timestamp 必须是文档")
+		}
+		m := fields.Map()
+		t, err := toUint32(m["t"])
+		if err != nil {
+			return nil, true, errors.Wrap(err, "$timestamp.t")
+		}
+		i, err := toUint32(m["i"])
+		if err != nil {
+			return nil, true, errors.Wrap(err, "$timestamp.i")
+		}
+		return primitive.Timestamp{T: t, I: i}, true, nil
+	case "$minKey":
+		return primitive.MinKey{}, true, nil
+	case "$maxKey":
+		return primitive.MaxKey{}, true, nil
+	case "$undefined":
+		return primitive.Undefined{}, true, nil
+	case "$symbol":
+		s, _ := value.(string)
+		return primitive.Symbol(s), true, nil
+	case "$code":
+		s, _ := value.(string)
+		return primitive.JavaScript(s), true, nil
+	case "$dbPointer":
+		fields, isDoc := value.(D)
+		if !isDoc {
+			return nil, true, errors.New("$dbPointer 必须是文档")
+		}
+		m := fields.Map()
+		ref, _ := m["$ref"].(string)
+		oid, isOid := m["$id"].(primitive.ObjectID)
+		if !isOid {
+			return nil, true, errors.New("$dbPointer.$id 必须是ObjectID")
+		}
+		return primitive.DBPointer{DB: ref, Pointer: oid}, true, nil
+	}
+	return nil, false, nil
+}
+
+// parseDouble 解析$numberDouble，支持Infinity、-Infinity和NaN
+func parseDouble(s string) (float64, error) {
+	switch s {
+	case "Infinity":
+		return math.Inf(1), nil
+	case "-Infinity":
+		return math.Inf(-1), nil
+	case "NaN":
+		return math.NaN(), nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	return f, errors.WithStack(err)
+}
+
+// decodeDate 解析$date，支持ISO-8601字符串、$numberLong毫秒时间戳和旧版的数字毫秒时间戳
+func decodeDate(value interface{}) (primitive.DateTime, error) {
+	switch v := value.(type) {
+	case string:
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return 0, errors.WithStack(err)
+		}
+		return primitive.NewDateTimeFromTime(t), nil
+	case int64:
+		return primitive.DateTime(v), nil
+	case int32:
+		return primitive.DateTime(v), nil
+	case float64:
+		return primitive.DateTime(int64(v)), nil
+	}
+	return 0, errors.Errorf("无法解析的$date: %v", value)
+}
+
+// decodeBinary 解析$binary的 {"base64":"","subType":""} 文档
+func decodeBinary(value interface{}) (primitive.Binary, error) {
+	fields, isDoc := value.(D)
+	if !isDoc {
+		return primitive.Binary{}, errors.New("$binary 必须是文档")
+	}
+	m := fields.Map()
+	b64, _ := m["base64"].(string)
+	subType, _ := m["subType"].(string)
+
+	data, err := base64.StdEncoding.DecodeString(b64)
+	if err != nil {
+		return primitive.Binary{}, errors.WithStack(err)
+	}
+	if len(subType) == 1 {
+		subType = "0" + subType
+	}
+	st, err := hex.DecodeString(subType)
+	if err != nil || len(st) != 1 {
+		return primitive.Binary{}, errors.Errorf("无效的$binary.subType: %s", subType)
+	}
+	return primitive.Binary{Subtype: st[0], Data: data}, nil
+}
+
+// toUint32 将解码后的数字转换为uint32
+func toUint32(v interface{}) (uint32, error) {
+	var i int64
+	switch n := v.(type) {
+	case int32:
+		i = int64(n)
+	case int64:
+		i = n
+	default:
+		return 0, errors.Errorf("无效的数字: %v", v)
+	}
+	if i < 0 || i > math.MaxUint32 {
+		return 0, errors.Errorf("%d 超出uint32的取值范围", i)
+	}
+	return uint32(i), nil
+}
@@ -0,0 +1,574 @@
+package bson
+
+// 导入所需的包
+import (
+	// JSON处理包
+	"encoding/json"
+	// 数学计算包
+	"math"
+	// 反射包
+	"reflect"
+	// 字符串转换包
+	"strconv"
+	// 时间处理包
+	"time"
+
+	// 原始类型包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/primitive"
+	// 错误处理包
+	"github.com/pkg/errors"
+)
+
+// 常用类型
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	dateTimeType  = reflect.TypeOf(primitive.DateTime(0))
+	objectIDType  = reflect.TypeOf(primitive.ObjectID{})
+	decimalType   = reflect.TypeOf(primitive.Decimal128{})
+	bytesType     = reflect.TypeOf([]byte(nil))
+	numberType    = reflect.TypeOf(json.Number(""))
+	interfaceType = reflect.TypeOf((*interface{})(nil)).Elem()
+	zeroerType    = reflect.TypeOf((*Zeroer)(nil)).Elem()
+)
+
+// leafTypes 编码时原样保留的类型
+var leafTypes = map[reflect.Type]bool{
+	timeType:                                  true,
+	dateTimeType:                              true,
+	objectIDType:                              true,
+	decimalType:                               true,
+	bytesType:                                 true,
+	numberType:                                true,
+	reflect.TypeOf(primitive.Binary{}):        true,
+	reflect.TypeOf(primitive.Regex{}):         true,
+	reflect.TypeOf(primitive.Timestamp{}):     true,
+	reflect.TypeOf(primitive.MinKey{}):        true,
+	reflect.TypeOf(primitive.MaxKey{}):        true,
+	reflect.TypeOf(primitive.Undefined{}):     true,
+	reflect.TypeOf(primitive.Null{}):          true,
+	reflect.TypeOf(primitive.Symbol("")):      true,
+	reflect.TypeOf(primitive.JavaScript("")):  true,
+	reflect.TypeOf(primitive.CodeWithScope{}): true,
+	reflect.TypeOf(primitive.DBPointer{}):     true,
+}
+
+// Marshal 将结构体、map或D编码为有序文档D
+// 结构体按字段声明顺序输出，字段规则见TagName，嵌套的结构体编码为D，map编码为M，切片编码为A
+// 参数：
+//   - val: 要编码的值
+//
+// 返回：
+//   - D: 文档
+//   - error: 错误信息
+func Marshal(val interface{}) (D, error) {
+	v, err := marshalValue(reflect.ValueOf(val), false)
+	if err != nil {
+		return nil, err
+	}
+	switch doc := v.(type) {
+	case D:
+		return doc, nil
+	case M:
+		return mapToD(doc), nil
+	}
+	return nil, errors.Errorf("%T 不能编码为文档", val)
+}
+
+// MarshalM 将结构体、map或D编码为无序文档M，便于构造过滤条件和更新文档
+// 参数：
+//   - val: 要编码的值
+//
+// 返回：
+//   - M: 文档
+//   - error: 错误信息
+func MarshalM(val interface{}) (M, error) {
+	doc, err := Marshal(val)
+	if err != nil {
+		return nil, err
+	}
+	return doc.Map(), nil
+}
+
+// mapToD 将M转换为D
+func mapToD(m M) D {
+	d := make(D, 0, len(m))
+	for k, v := range m {
+		d = append(d, E{Key: k, Value: v})
+	}
+	return d
+}
+
+// marshalValue 将任意值转换为文档中的值
+func marshalValue(rv reflect.Value, minSize bool) (interface{}, error) {
+	if !rv.IsValid() {
+		return nil, nil
+	}
+	if leafTypes[rv.Type()] {
+		return rv.Interface(), nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		return marshalValue(rv.Elem(), minSize)
+	case reflect.Struct:
+		return marshalStruct(rv)
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return nil, errors.Errorf("%s: map的键必须是字符串", rv.Type())
+		}
+		if rv.IsNil() {
+			return nil, nil
+		}
+		m := make(M, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			item, err := marshalValue(iter.Value(), false)
+			if err != nil {
+				return nil, errors.Wrapf(err, "编码字段 %s 失败", iter.Key().String())
+			}
+			m[iter.Key().String()] = item
+		}
+		return m, nil
+	case reflect.Slice:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		if d, ok := rv.Interface().(D); ok {
+			return marshalD(d)
+		}
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return rv.Bytes(), nil
+		}
+		fallthrough
+	case reflect.Array:
+		arr := make(A, rv.Len())
+		for i := range arr {
+			item, err := marshalValue(rv.Index(i), false)
+			if err != nil {
+				return nil, errors.Wrapf(err, "编码第%d个元素失败", i)
+			}
+			arr[i] = item
+		}
+		return arr, nil
+	case reflect.Int64:
+		if minSize && rv.Int() >= math.MinInt32 && rv.Int() <= math.MaxInt32 {
+			return int32(rv.Int()), nil
+		}
+		return rv.Int(), nil
+	case reflect.Int:
+		return int(rv.Int()), nil
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return int32(rv.Int()), nil
+	case reflect.Uint8, reflect.Uint16:
+		return int32(rv.Uint()), nil
+	case reflect.Uint, reflect.Uint32, reflect.Uint64:
+		if rv.Uint() > math.MaxInt64 {
+			return nil, errors.Errorf("%d 超出BSON int64的取值范围", rv.Uint())
+		}
+		if minSize && rv.Uint() <= math.MaxInt32 {
+			return int32(rv.Uint()), nil
+		}
+		return int64(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), nil
+	case reflect.String:
+		return rv.String(), nil
+	case reflect.Bool:
+		return rv.Bool(), nil
+	}
+	return nil, errors.Errorf("不支持编码的类型 %s", rv.Type())
+}
+
+// marshalD 编码D中的值
+func marshalD(d D) (D, error) {
+	res := make(D, 0, len(d))
+	for _, e := range d {
+		item, err := marshalValue(reflect.ValueOf(e.Value), false)
+		if err != nil {
+			return nil, errors.Wrapf(err, "编码字段 %s 失败", e.Key)
+		}
+		res = append(res, E{Key: e.Key, Value: item})
+	}
+	return res, nil
+}
+
+// marshalStruct 按字段声明顺序将结构体编码为D，内联map中的元素追加在末尾
+func marshalStruct(rv reflect.Value) (D, error) {
+	codec, err := codecOf(rv.Type())
+	if err != nil {
+		return nil, err
+	}
+
+	doc := make(D, 0, len(codec.fields))
+	for _, field := range codec.fields {
+		fv := fieldByIndex(rv, field.index, false)
+		if !fv.IsValid() {
+			continue
+		}
+		if field.omitEmpty && isEmpty(fv) {
+			continue
+		}
+		item, err := marshalValue(fv, field.minSize)
+		if err != nil {
+			return nil, errors.Wrapf(err, "编码字段 %s 失败", field.name)
+		}
+		doc = append(doc, E{Key: field.name, Value: item})
+	}
+
+	if codec.inlineMap != nil {
+		mv := fieldByIndex(rv, codec.inlineMap, false)
+		if mv.IsValid() && !mv.IsNil() {
+			iter := mv.MapRange()
+			for iter.Next() {
+				key := iter.Key().String()
+				if _, ok := codec.byName[key]; ok {
+					return nil, errors.Errorf("内联map中的键 %s 与结构体字段重复", key)
+				}
+				item, err := marshalValue(iter.Value(), false)
+				if err != nil {
+					return nil, errors.Wrapf(err, "编码字段 %s 失败", key)
+				}
+				doc = append(doc, E{Key: key, Value: item})
+			}
+		}
+	}
+	return doc, nil
+}
+
+// isEmpty 判断omitempty字段是否为空：实现Zeroer的类型以IsZero为准，
+// 切片、map、字符串长度为0时为空，未实现Zeroer的结构体不视为空
+func isEmpty(v reflect.Value) bool {
+	if v.Type().Implements(zeroerType) {
+		if v.Kind() == reflect.Ptr && v.IsNil() {
+			return true
+		}
+		return v.Interface().(Zeroer).IsZero()
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.String, reflect.Array:
+		return v.Len() == 0
+	case reflect.Struct:
+		return false
+	}
+	return v.IsZero()
+}
+
+// Unmarshal 将文档解码到结构体、map等目标中
+// doc可以是D、M、map[string]interface{}，字段规则见TagName，结构体中没有对应字段的元素存放到内联map中，没有内联map时忽略
+// 参数：
+//   - doc: 文档
+//   - val: 解码目标指针
+//
+// 返回：
+//   - error: 错误信息
+func Unmarshal(doc interface{}, val interface{}) error {
+	rv := reflect.ValueOf(val)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.Errorf("解码目标必须是非nil指针，实际为%T", val)
+	}
+	return unmarshalValue(doc, rv.Elem(), false)
+}
+
+// unmarshalValue 将文档中的值解码到dst
+func unmarshalValue(src interface{}, dst reflect.Value, truncate bool) error {
+	if src == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+	if dst.Kind() == reflect.Ptr {
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		return unmarshalValue(src, dst.Elem(), truncate)
+	}
+	if dst.Type() == interfaceType {
+		dst.Set(reflect.ValueOf(src))
+		return nil
+	}
+
+	sv := reflect.ValueOf(src)
+	switch dst.Type() {
+	case timeType:
+		switch s := src.(type) {
+		case primitive.DateTime:
+			dst.Set(reflect.ValueOf(s.Time().UTC()))
+			return nil
+		case time.Time:
+			dst.Set(sv)
+			return nil
+		}
+	case dateTimeType:
+		switch s := src.(type) {
+		case primitive.DateTime:
+			dst.Set(sv)
+			return nil
+		case time.Time:
+			dst.Set(reflect.ValueOf(primitive.NewDateTimeFromTime(s)))
+			return nil
+		}
+	case objectIDType:
+		switch s := src.(type) {
+		case primitive.ObjectID:
+			dst.Set(sv)
+			return nil
+		case string:
+			oid, err := primitive.ObjectIDFromHex(s)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			dst.Set(reflect.ValueOf(oid))
+			return nil
+		}
+	}
+
+	switch dst.Kind() {
+	case reflect.Struct:
+		if d, ok := asDoc(src); ok {
+			return unmarshalStruct(d, dst)
+		}
+	case reflect.Map:
+		if d, ok := asDoc(src); ok {
+			return unmarshalMap(d, dst)
+		}
+	case reflect.Slice:
+		if dst.Type().Elem().Kind() == reflect.Uint8 {
+			switch s := src.(type) {
+			case []byte:
+				dst.SetBytes(append([]byte(nil), s...))
+				return nil
+			case primitive.Binary:
+				dst.SetBytes(append([]byte(nil), s.Data...))
+				return nil
+			case string:
+				dst.SetBytes([]byte(s))
+				return nil
+			}
+		}
+		if dst.Type() == reflect.TypeOf(D{}) {
+			if d, ok := asDoc(src); ok {
+				dst.Set(reflect.ValueOf(d))
+				return nil
+			}
+		}
+		if arr, ok := asArray(src); ok {
+			slice := reflect.MakeSlice(dst.Type(), len(arr), len(arr))
+			for i, item := range arr {
+				if err := unmarshalValue(item, slice.Index(i), false); err != nil {
+					return errors.Wrapf(err, "解码第%d个元素失败", i)
+				}
+			}
+			dst.Set(slice)
+			return nil
+		}
+	case reflect.Array:
+		if arr, ok := asArray(src); ok {
+			if len(arr) > dst.Len() {
+				return errors.Errorf("数组长度%d超出%s的长度", len(arr), dst.Type())
+			}
+			dst.Set(reflect.Zero(dst.Type()))
+			for i, item := range arr {
+				if err := unmarshalValue(item, dst.Index(i), false); err != nil {
+					return errors.Wrapf(err, "解码第%d个元素失败", i)
+				}
+			}
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := toInt64(src, truncate)
+		if err != nil {
+			return err
+		}
+		if dst.OverflowInt(i) {
+			return errors.Errorf("%d 超出%s的取值范围", i, dst.Type())
+		}
+		dst.SetInt(i)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, err := toInt64(src, truncate)
+		if err != nil {
+			return err
+		}
+		if i < 0 || dst.OverflowUint(uint64(i)) {
+			return errors.Errorf("%d 超出%s的取值范围", i, dst.Type())
+		}
+		dst.SetUint(uint64(i))
+		return nil
+	case reflect.Float32, reflect.Float64:
+		f, err := toFloat64(src)
+		if err != nil {
+			return err
+		}
+		dst.SetFloat(f)
+		return nil
+	case reflect.String:
+		switch s := src.(type) {
+		case string:
+			dst.SetString(s)
+			return nil
+		case primitive.Symbol:
+			dst.SetString(string(s))
+			return nil
+		case primitive.JavaScript:
+			dst.SetString(string(s))
+			return nil
+		case primitive.ObjectID:
+			dst.SetString(s.Hex())
+			return nil
+		}
+	case reflect.Bool:
+		if b, ok := src.(bool); ok {
+			dst.SetBool(b)
+			return nil
+		}
+	}
+
+	if sv.Type().AssignableTo(dst.Type()) {
+		dst.Set(sv)
+		return nil
+	}
+	if sv.Type().ConvertibleTo(dst.Type()) && sv.Kind() == dst.Kind() {
+		dst.Set(sv.Convert(dst.Type()))
+		return nil
+	}
+	return errors.Errorf("无法将%T解码到%s", src, dst.Type())
+}
+
+// unmarshalStruct 将文档解码到结构体
+func unmarshalStruct(doc D, dst reflect.Value) error {
+	codec, err := codecOf(dst.Type())
+	if err != nil {
+		return err
+	}
+
+	for _, e := range doc {
+		i, ok := codec.byName[e.Key]
+		if !ok {
+			if codec.inlineMap == nil {
+				continue
+			}
+			mv := fieldByIndex(dst, codec.inlineMap, true)
+			if mv.IsNil() {
+				mv.Set(reflect.MakeMap(mv.Type()))
+			}
+			item := reflect.New(mv.Type().Elem()).Elem()
+			if err = unmarshalValue(e.Value, item, false); err != nil {
+				return errors.Wrapf(err, "解码字段 %s 失败", e.Key)
+			}
+			mv.SetMapIndex(reflect.ValueOf(e.Key).Convert(mv.Type().Key()), item)
+			continue
+		}
+		field := codec.fields[i]
+		if err = unmarshalValue(e.Value, fieldByIndex(dst, field.index, true), field.truncate); err != nil {
+			return errors.Wrapf(err, "解码字段 %s 失败", e.Key)
+		}
+	}
+	return nil
+}
+
+// unmarshalMap 将文档解码到map
+func unmarshalMap(doc D, dst reflect.Value) error {
+	if dst.Type().Key().Kind() != reflect.String {
+		return errors.Errorf("%s: map的键必须是字符串", dst.Type())
+	}
+	if dst.IsNil() {
+		dst.Set(reflect.MakeMapWithSize(dst.Type(), len(doc)))
+	}
+	// 与官方驱动一致，解码到M时嵌套文档同样解码为M
+	nestedM := dst.Type().Elem() == interfaceType
+	for _, e := range doc {
+		item := reflect.New(dst.Type().Elem()).Elem()
+		if nestedM {
+			if e.Value != nil {
+				item.Set(reflect.ValueOf(mapDocs(e.Value)))
+			}
+			dst.SetMapIndex(reflect.ValueOf(e.Key).Convert(dst.Type().Key()), item)
+			continue
+		}
+		if err := unmarshalValue(e.Value, item, false); err != nil {
+			return errors.Wrapf(err, "解码字段 %s 失败", e.Key)
+		}
+		dst.SetMapIndex(reflect.ValueOf(e.Key).Convert(dst.Type().Key()), item)
+	}
+	return nil
+}
+
+// asDoc 将文档类型的值统一转换为D
+func asDoc(src interface{}) (D, bool) {
+	switch s := src.(type) {
+	case D:
+		return s, true
+	case M:
+		return mapToD(s), true
+	case map[string]interface{}:
+		return mapToD(s), true
+	}
+	return nil, false
+}
+
+// asArray 将数组类型的值统一转换为[]interface{}
+func asArray(src interface{}) ([]interface{}, bool) {
+	switch s := src.(type) {
+	case A:
+		return s, true
+	case []interface{}:
+		return s, true
+	}
+	return nil, false
+}
+
+// toInt64 将数字转换为int64，浮点数只有为整数或声明了truncate时才允许转换
+func toInt64(src interface{}, truncate bool) (int64, error) {
+	switch s := src.(type) {
+	case int32:
+		return int64(s), nil
+	case int64:
+		return s, nil
+	case int:
+		return int64(s), nil
+	case json.Number:
+		if i, err := s.Int64(); err == nil {
+			return i, nil
+		}
+		f, err := s.Float64()
+		if err != nil {
+			return 0, errors.WithStack(err)
+		}
+		return toInt64(f, truncate)
+	case float64:
+		if s != math.Trunc(s) && !truncate {
+			return 0, errors.Errorf("%v 不是整数，如需截断请使用truncate标签", s)
+		}
+		if s < math.MinInt64 || s >= math.MaxInt64 {
+			return 0, errors.Errorf("%v 超出int64的取值范围", s)
+		}
+		return int64(s), nil
+	case string:
+		i, err := strconv.ParseInt(s, 10, 64)
+		return i, errors.WithStack(err)
+	}
+	return 0, errors.Errorf("无法将%T解码为整数", src)
+}
+
+// toFloat64 将数字转换为float64
+func toFloat64(src interface{}) (float64, error) {
+	switch s := src.(type) {
+	case float64:
+		return s, nil
+	case int32:
+		return float64(s), nil
+	case int64:
+		return float64(s), nil
+	case int:
+		return float64(s), nil
+	case json.Number:
+		f, err := s.Float64()
+		return f, errors.WithStack(err)
+	case primitive.Decimal128:
+		f, err := strconv.ParseFloat(s.String(), 64)
+		return f, errors.WithStack(err)
+	}
+	return 0, errors.Errorf("无法将%T解码为浮点数", src)
+}
@@ -0,0 +1,285 @@
+package bson
+
+// 导入所需的包
+import (
+	// 排序包
+	"sort"
+	// 字符串处理包
+	"strings"
+)
+
+// PipelineBuilder 聚合管道构建器，各阶段方法按调用顺序追加阶段并返回构建器本身，
+// 最后调用Build得到可直接传给MongoAggregateDocuments的Pipeline
+//
+// 示例用法:
+//
+//	pipeline := bson.NewPipeline().
+//		Match(bson.M{"status": "paid"}).
+//		Group("$user_id", bson.E{Key: "total", Value: bson.Sum("$amount")}).
+//		Sort(bson.D{{"total", -1}}).
+//		Limit(10).
+//		Build()
+type PipelineBuilder struct {
+	// 已添加的阶段
+	stages Pipeline
+}
+
+// NewPipeline 创建聚合管道构建器
+func NewPipeline() *PipelineBuilder {
+	return &PipelineBuilder{}
+}
+
+// Stage 添加任意阶段，用于构建器未提供方法的阶段，例如 Stage("$out", "coll")
+func (this *PipelineBuilder) Stage(name string, value interface{}) *PipelineBuilder {
+	this.stages = append(this.stages, D{{Key: name, Value: value}})
+	return this
+}
+
+// Match 添加$match阶段
+func (this *PipelineBuilder) Match(filter interface{}) *PipelineBuilder {
+	return this.Stage("$match", filter)
+}
+
+// Project 添加$project阶段
+func (this *PipelineBuilder) Project(spec interface{}) *PipelineBuilder {
+	return this.Stage("$project", spec)
+}
+
+// Group 添加$group阶段，id为分组表达式，为nil时对全部文档分组；
+// accumulators为输出字段及其累加器，例如 bson.E{Key: "total", Value: bson.Sum("$amount")}
+func (this *PipelineBuilder) Group(id interface{}, accumulators ...E) *PipelineBuilder {
+	group := make(D, 0, len(accumulators)+1)
+	group = append(group, E{Key: "_id", Value: id})
+	group = append(group, accumulators...)
+	return this.Stage("$group", group)
+}
+
+// Sort 添加$sort阶段，1为升序，-1为降序
+func (this *PipelineBuilder) Sort(keys D) *PipelineBuilder {
+	return this.Stage("$sort", keys)
+}
+
+// Skip 添加$skip阶段
+func (this *PipelineBuilder) Skip(n int64) *PipelineBuilder {
+	return this.Stage("$skip", n)
+}
+
+// Limit 添加$limit阶段
+func (this *PipelineBuilder) Limit(n int64) *PipelineBuilder {
+	return this.Stage("$limit", n)
+}
+
+// Sample 添加$sample阶段
+func (this *PipelineBuilder) Sample(size int64) *PipelineBuilder {
+	return this.Stage("$sample", D{{Key: "size", Value: size}})
+}
+
+// Lookup 添加按字段相等关联的$lookup阶段
+func (this *PipelineBuilder) Lookup(from, localField, foreignField, as string) *PipelineBuilder {
+	return this.Stage("$lookup", D{
+		{Key: "from", Value: from},
+		{Key: "localField", Value: localField},
+		{Key: "foreignField", Value: foreignField},
+		{Key: "as", Value: as},
+	})
+}
+
+// LookupPipeline 添加使用子管道关联的$lookup阶段，let中定义的变量在子管道中以$$name引用
+func (this *PipelineBuilder) LookupPipeline(from string, let interface{}, pipeline Pipeline, as string) *PipelineBuilder {
+	lookup := D{{Key: "from", Value: from}}
+	if let != nil {
+		lookup = append(lookup, E{Key: "let", Value: let})
+	}
+	lookup = append(lookup, E{Key: "pipeline", Value: pipeline}, E{Key: "as", Value: as})
+	return this.Stage("$lookup", lookup)
+}
+
+// Unwind 添加$unwind阶段，path可以省略前缀$
+func (this *PipelineBuilder) Unwind(path string) *PipelineBuilder {
+	return this.Stage("$unwind", Field(path))
+}
+
+// UnwindWithOptions 添加带选项的$unwind阶段
+// includeArrayIndex为保存数组下标的字段名，为空时不保存；preserveNullAndEmptyArrays为true时保留字段缺失或为空数组的文档
+func (this *PipelineBuilder) UnwindWithOptions(path, includeArrayIndex string, preserveNullAndEmptyArrays bool) *PipelineBuilder {
+	unwind := D{{Key: "path", Value: Field(path)}}
+	if includeArrayIndex != "" {
+		unwind = append(unwind, E{Key: "includeArrayIndex", Value: includeArrayIndex})
+	}
+	if preserveNullAndEmptyArrays {
+		unwind = append(unwind, E{Key: "preserveNullAndEmptyArrays", Value: true})
+	}
+	return this.Stage("$unwind", unwind)
+}
+
+// Facet 添加$facet阶段，facets为输出字段 => 子管道，输出字段按名称排序
+func (this *PipelineBuilder) Facet(facets map[string]*PipelineBuilder) *PipelineBuilder {
+	names := make([]string, 0, len(facets))
+	for name := range facets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	facet := make(D, 0, len(names))
+	for _, name := range names {
+		facet = append(facet, E{Key: name, Value: facets[name].Build()})
+	}
+	return this.Stage("$facet", facet)
+}
+
+// Bucket 添加$bucket阶段
+// boundaries为升序的分桶边界；defaultBucket为不在边界内的文档所属的桶，为nil时不设置；
+// output为各桶的输出字段，为空时只输出count
+func (this *PipelineBuilder) Bucket(groupBy interface{}, boundaries A, defaultBucket interface{}, output ...E) *PipelineBuilder {
+	bucket := D{
+		{Key: "groupBy", Value: groupBy},
+		{Key: "boundaries", Value: boundaries},
+	}
+	if defaultBucket != nil {
+		bucket = append(bucket, E{Key: "default", Value: defaultBucket})
+	}
+	if len(output) > 0 {
+		bucket = append(bucket, E{Key: "output", Value: D(output)})
+	}
+	return this.Stage("$bucket", bucket)
+}
+
+// BucketAuto 添加$bucketAuto阶段，按文档数自动划分buckets个桶
+func (this *PipelineBuilder) BucketAuto(groupBy interface{}, buckets int32, output ...E) *PipelineBuilder {
+	bucket := D{
+		{Key: "groupBy", Value: groupBy},
+		{Key: "buckets", Value: buckets},
+	}
+	if len(output) > 0 {
+		bucket = append(bucket, E{Key: "output", Value: D(output)})
+	}
+	return this.Stage("$bucketAuto", bucket)
+}
+
+// AddFields 添加$addFields阶段
+func (this *PipelineBuilder) AddFields(fields ...E) *PipelineBuilder {
+	return this.Stage("$addFields", D(fields))
+}
+
+// ReplaceRoot 添加$replaceRoot阶段，newRoot通常为嵌套文档的字段引用，例如 "$profile"
+func (this *PipelineBuilder) ReplaceRoot(newRoot interface{}) *PipelineBuilder {
+	return this.Stage("$replaceRoot", D{{Key: "newRoot", Value: newRoot}})
+}
+
+// Count 添加$count阶段，field为保存文档数的字段名
+func (this *PipelineBuilder) Count(field string) *PipelineBuilder {
+	return this.Stage("$count", field)
+}
+
+// Build 返回构建好的聚合管道，返回值是副本，之后继续添加阶段不会影响已返回的管道
+func (this *PipelineBuilder) Build() Pipeline {
+	pipeline := make(Pipeline, len(this.stages))
+	copy(pipeline, this.stages)
+	return pipeline
+}
+
+// Field 返回字段引用表达式，name已带$前缀时原样返回，例如 Field("amount") => "$amount"
+func Field(name string) string {
+	if strings.HasPrefix(name, "$") {
+		return name
+	}
+	return "$" + name
+}
+
+// op 构建单个操作符的表达式
+func op(name string, value interface{}) D {
+	return D{{Key: name, Value: value}}
+}
+
+// Sum $sum累加器或表达式，对文档计数时使用 Sum(1)
+func Sum(expr interface{}) D { return op("$sum", expr) }
+
+// Avg $avg累加器或表达式
+func Avg(expr interface{}) D { return op("$avg", expr) }
+
+// Min $min累加器或表达式
+func Min(expr interface{}) D { return op("$min", expr) }
+
+// Max $max累加器或表达式
+func Max(expr interface{}) D { return op("$max", expr) }
+
+// First $first累加器
+func First(expr interface{}) D { return op("$first", expr) }
+
+// Last $last累加器
+func Last(expr interface{}) D { return op("$last", expr) }
+
+// Push $push累加器
+func Push(expr interface{}) D { return op("$push", expr) }
+
+// AddToSet $addToSet累加器
+func AddToSet(expr interface{}) D { return op("$addToSet", expr) }
+
+// Eq $eq比较表达式
+func Eq(a, b interface{}) D { return op("$eq", A{a, b}) }
+
+// Ne $ne比较表达式
+func Ne(a, b interface{}) D { return op("$ne", A{a, b}) }
+
+// Gt $gt比较表达式
+func Gt(a, b interface{}) D { return op("$gt", A{a, b}) }
+
+// Gte $gte比较表达式
+func Gte(a, b interface{}) D { return op("$gte", A{a, b}) }
+
+// Lt $lt比较表达式
+func Lt(a, b interface{}) D { return op("$lt", A{a, b}) }
+
+// Lte $lte比较表达式
+func Lte(a, b interface{}) D { return op("$lte", A{a, b}) }
+
+// In $in表达式，判断value是否在数组表达式array中
+func In(value, array interface{}) D { return op("$in", A{value, array}) }
+
+// And $and逻辑表达式
+func And(exprs ...interface{}) D { return op("$and", A(exprs)) }
+
+// Or $or逻辑表达式
+func Or(exprs ...interface{}) D { return op("$or", A(exprs)) }
+
+// Not $not逻辑表达式
+func Not(expr interface{}) D { return op("$not", A{expr}) }
+
+// Cond $cond条件表达式
+func Cond(ifExpr, thenExpr, elseExpr interface{}) D {
+	return op("$cond", D{{Key: "if", Value: ifExpr}, {Key: "then", Value: thenExpr}, {Key: "else", Value: elseExpr}})
+}
+
+// IfNull $ifNull表达式，expr为null或缺失时返回replacement
+func IfNull(expr, replacement interface{}) D { return op("$ifNull", A{expr, replacement}) }
+
+// Add $add算术表达式
+func Add(exprs ...interface{}) D { return op("$add", A(exprs)) }
+
+// Subtract $subtract算术表达式
+func Subtract(a, b interface{}) D { return op("$subtract", A{a, b}) }
+
+// Multiply $multiply算术表达式
+func Multiply(exprs ...interface{}) D { return op("$multiply", A(exprs)) }
+
+// Divide $divide算术表达式
+func Divide(a, b interface{}) D { return op("$divide", A{a, b}) }
+
+// Concat $concat字符串拼接表达式
+func Concat(exprs ...interface{}) D { return op("$concat", A(exprs)) }
+
+// ToString $toString类型转换表达式
+func ToString(expr interface{}) D { return op("$toString", expr) }
+
+// DateToString $dateToString表达式，format例如 "%Y-%m-%d"
+func DateToString(format string, date interface{}) D {
+	return op("$dateToString", D{{Key: "format", Value: format}, {Key: "date", Value: date}})
+}
+
+// Size $size表达式，返回数组长度
+func Size(array interface{}) D { return op("$size", array) }
+
+// ArrayElemAt $arrayElemAt表达式，返回数组中指定下标的元素
+func ArrayElemAt(array interface{}, index int32) D { return op("$arrayElemAt", A{array, index}) }
+
+// Literal $literal表达式，值不作为表达式解析，例如以$开头的字符串
+func Literal(value interface{}) D { return op("$literal", value) }
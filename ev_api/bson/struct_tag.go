@@ -0,0 +1,158 @@
+package bson
+
+// 导入所需的包
+import (
+	// 反射包
+	"reflect"
+	// 字符串处理包
+	"strings"
+	// 并发控制包
+	"sync"
+
+	// 错误处理包
+	"github.com/pkg/errors"
+)
+
+// TagName 结构体字段标签名
+const TagName = "bson"
+
+// structField 结构体字段的编解码信息
+type structField struct {
+	// 文档中的字段名
+	name string
+	// 字段在结构体中的索引路径，内联结构体的字段为多级索引
+	index []int
+	// 零值时编码跳过该字段
+	omitEmpty bool
+	// int64可用int32表示时编码为int32
+	minSize bool
+	// 解码时允许将浮点数截断为整数
+	truncate bool
+}
+
+// structCodec 结构体的编解码信息
+type structCodec struct {
+	// 按声明顺序排列的字段
+	fields []structField
+	// 字段名 => fields下标
+	byName map[string]int
+	// 内联map字段的索引路径，用于存放结构体中没有对应字段的元素
+	inlineMap []int
+}
+
+// codecCache 结构体类型 => *structCodec
+var codecCache sync.Map
+
+// codecOf 返回结构体类型的编解码信息，规则与官方驱动一致：
+// 字段名取`bson`标签，未设置时为字段名的小写形式，`bson:"-"`表示忽略；
+// 标签选项支持omitempty、minsize、truncate和inline，匿名结构体字段只有声明inline时才会展开
+func codecOf(t reflect.Type) (*structCodec, error) {
+	if cached, ok := codecCache.Load(t); ok {
+		return cached.(*structCodec), nil
+	}
+	codec := &structCodec{byName: map[string]int{}}
+	if err := codec.collect(t, nil); err != nil {
+		return nil, err
+	}
+	codecCache.Store(t, codec)
+	return codec, nil
+}
+
+// collect 递归收集字段
+func (this *structCodec) collect(t reflect.Type, parent []int) error {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag, ok := f.Tag.Lookup(TagName)
+		if !ok && !strings.Contains(string(f.Tag), ":") {
+			// 兼容官方驱动：整个标签不是key:"value"格式时视为bson标签
+			tag = string(f.Tag)
+		}
+		if tag == "-" {
+			continue
+		}
+
+		parts := strings.Split(tag, ",")
+		field := structField{name: parts[0]}
+		inline := false
+		for _, opt := range parts[1:] {
+			switch opt {
+			case "omitempty":
+				field.omitEmpty = true
+			case "minsize":
+				field.minSize = true
+			case "truncate":
+				field.truncate = true
+			case "inline":
+				inline = true
+			}
+		}
+
+		index := make([]int, len(parent)+1)
+		copy(index, parent)
+		index[len(parent)] = i
+
+		if inline {
+			ft := f.Type
+			switch {
+			case ft.Kind() == reflect.Map:
+				if ft.Key().Kind() != reflect.String {
+					return errors.Errorf("%s.%s: 内联map的键必须是字符串", t, f.Name)
+				}
+				if this.inlineMap != nil {
+					return errors.Errorf("%s: 只能有一个内联map字段", t)
+				}
+				this.inlineMap = index
+				continue
+			case ft.Kind() == reflect.Struct, ft.Kind() == reflect.Ptr && ft.Elem().Kind() == reflect.Struct:
+				if ft.Kind() == reflect.Ptr {
+					ft = ft.Elem()
+				}
+				if err := this.collect(ft, index); err != nil {
+					return err
+				}
+				continue
+			default:
+				return errors.Errorf("%s.%s: inline只能用于结构体或map字段", t, f.Name)
+			}
+		}
+
+		if field.name == "" {
+			field.name = strings.ToLower(f.Name)
+		}
+		field.index = index
+		if exist, ok := this.byName[field.name]; ok {
+			// 外层字段优先于内联结构体中的同名字段，同一层级的重名字段视为错误
+			if len(this.fields[exist].index) < len(index) {
+				continue
+			}
+			if len(this.fields[exist].index) == len(index) {
+				return errors.Errorf("%s: 重复的字段名 %s", t, field.name)
+			}
+			this.fields[exist] = field
+			continue
+		}
+		this.byName[field.name] = len(this.fields)
+		this.fields = append(this.fields, field)
+	}
+	return nil
+}
+
+// fieldByIndex 按索引路径取字段，alloc为true时为途经的nil指针分配内存，否则遇到nil指针返回无效值
+func fieldByIndex(v reflect.Value, index []int, alloc bool) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !alloc {
+					return reflect.Value{}
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
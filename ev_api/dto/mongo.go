@@ -4,6 +4,8 @@ package dto
 import (
 	// MongoDB BSON包
 	"github.com/1340691923/eve-plugin-sdk-go/ev_api/bson"
+	// MongoDB原始类型包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/primitive"
 	// 时间处理包
 	"time"
 )
@@ -93,3 +95,260 @@ type AggregateMongoDocumentsReq struct {
 	CollectionName string        `json:"collection_name"`
 	Pipeline       bson.Pipeline `json:"pipeline"`
 }
+
+// MongoFindCursorReq MongoDB打开查询游标请求结构
+type MongoFindCursorReq struct {
+	// ES连接数据
+	EsConnectData EsConnectData `json:"es_connect_data"`
+	// 数据库名称
+	DbName string `json:"db_name"`
+	// 集合名称
+	CollectionName string `json:"collection_name"`
+	// 过滤条件
+	Filter bson.M `json:"filter"`
+	// 投影
+	Projection bson.M `json:"projection"`
+	// 排序条件
+	Sort bson.D `json:"sort"`
+	// 跳过的文档数
+	Skip int64 `json:"skip"`
+	// 返回的最大文档数，0表示不限制
+	Limit int64 `json:"limit"`
+	// 每批返回的文档数
+	BatchSize int32 `json:"batch_size"`
+	// 空闲超时时间（秒），超时未拉取时基座自动关闭游标
+	IdleTimeout int `json:"idle_timeout"`
+}
+
+// MongoAggregateCursorReq MongoDB打开聚合游标请求结构
+type MongoAggregateCursorReq struct {
+	// ES连接数据
+	EsConnectData EsConnectData `json:"es_connect_data"`
+	// 数据库名称
+	DbName string `json:"db_name"`
+	// 集合名称
+	CollectionName string `json:"collection_name"`
+	// 聚合管道
+	Pipeline bson.Pipeline `json:"pipeline"`
+	// 每批返回的文档数
+	BatchSize int32 `json:"batch_size"`
+	// 是否允许使用磁盘临时文件
+	AllowDiskUse bool `json:"allow_disk_use"`
+	// 空闲超时时间（秒），超时未拉取时基座自动关闭游标
+	IdleTimeout int `json:"idle_timeout"`
+}
+
+// MongoCursorGetMoreReq MongoDB游标拉取请求结构，对应getMore命令
+type MongoCursorGetMoreReq struct {
+	// ES连接数据
+	EsConnectData EsConnectData `json:"es_connect_data"`
+	// 游标ID
+	CursorId string `json:"cursor_id"`
+	// 本次拉取的文档数
+	BatchSize int32 `json:"batch_size"`
+}
+
+// MongoCursorKillReq MongoDB关闭游标请求结构，对应killCursors命令
+type MongoCursorKillReq struct {
+	// ES连接数据
+	EsConnectData EsConnectData `json:"es_connect_data"`
+	// 游标ID
+	CursorId string `json:"cursor_id"`
+}
+
+// MongoIndexModel MongoDB索引定义
+type MongoIndexModel struct {
+	// 索引键，例如 bson.D{{"a", 1}, {"b", -1}}，文本索引为 {"content", "text"}，地理索引为 {"loc", "2dsphere"}
+	Keys bson.D
+	// 索引名称，为空时按官方驱动的规则生成，例如 a_1_b_-1
+	Name string
+	// 唯一索引
+	Unique bool
+	// 稀疏索引
+	Sparse bool
+	// 隐藏索引，查询优化器不会使用
+	Hidden bool
+	// TTL索引的过期秒数，为nil时不是TTL索引
+	ExpireAfterSeconds *int32
+	// 部分索引的过滤条件
+	PartialFilterExpression bson.M
+	// 文本索引各字段的权重
+	Weights bson.M
+	// 文本索引的默认语言
+	DefaultLanguage string
+	// 文本索引中指定文档语言的字段
+	LanguageOverride string
+	// 2dsphere索引版本
+	SphereVersion int32
+	// 排序规则
+	Collation bson.M
+}
+
+// MongoTimeSeriesOptions MongoDB时间序列集合选项
+type MongoTimeSeriesOptions struct {
+	// 时间字段
+	TimeField string
+	// 元数据字段
+	MetaField string
+	// 时间粒度：seconds、minutes、hours
+	Granularity string
+}
+
+// MongoCreateCollectionOptions MongoDB创建集合选项
+type MongoCreateCollectionOptions struct {
+	// 固定集合
+	Capped bool
+	// 固定集合的最大字节数，Capped为true时必填
+	SizeInBytes int64
+	// 固定集合的最大文档数
+	MaxDocuments int64
+	// 文档校验规则，例如 bson.M{"$jsonSchema": ...}
+	Validator bson.M
+	// 校验级别：off、strict、moderate
+	ValidationLevel string
+	// 校验失败时的动作：error、warn
+	ValidationAction string
+	// 时间序列集合选项
+	TimeSeries *MongoTimeSeriesOptions
+	// 时间序列集合中文档的过期秒数
+	ExpireAfterSeconds *int64
+	// 默认排序规则
+	Collation bson.M
+}
+
+// MongoWriteOp 批量写入的操作类型
+type MongoWriteOp string
+
+// 批量写入支持的操作类型
+const (
+	// MongoInsertOne 插入单个文档
+	MongoInsertOne MongoWriteOp = "insertOne"
+	// MongoUpdateOne 更新第一个匹配的文档
+	MongoUpdateOne MongoWriteOp = "updateOne"
+	// MongoUpdateMany 更新全部匹配的文档
+	MongoUpdateMany MongoWriteOp = "updateMany"
+	// MongoReplaceOne 替换第一个匹配的文档
+	MongoReplaceOne MongoWriteOp = "replaceOne"
+	// MongoDeleteOne 删除第一个匹配的文档
+	MongoDeleteOne MongoWriteOp = "deleteOne"
+	// MongoDeleteMany 删除全部匹配的文档
+	MongoDeleteMany MongoWriteOp = "deleteMany"
+)
+
+// MongoWriteModel MongoDB批量写入中的单个操作，建议通过NewMongoInsertOneModel等函数创建
+type MongoWriteModel struct {
+	// 操作类型
+	Op MongoWriteOp
+	// 要插入的文档，仅insertOne使用，没有_id时自动生成ObjectID
+	Document bson.M
+	// 过滤条件，insertOne以外的操作使用
+	Filter bson.M
+	// 更新内容，updateOne和updateMany使用，可以是更新操作符文档（bson.M、bson.D）或聚合管道（bson.Pipeline）
+	Update interface{}
+	// 替换后的文档，仅replaceOne使用，不能包含更新操作符
+	Replacement bson.M
+	// 没有匹配文档时是否插入，update和replace操作使用
+	Upsert bool
+	// 数组过滤条件，update操作使用
+	ArrayFilters []bson.M
+	// 排序规则
+	Collation bson.M
+	// 索引提示，可以是索引名称或索引键
+	Hint interface{}
+}
+
+// NewMongoInsertOneModel 创建insertOne操作
+func NewMongoInsertOneModel(doc bson.M) MongoWriteModel {
+	return MongoWriteModel{Op: MongoInsertOne, Document: doc}
+}
+
+// NewMongoUpdateOneModel 创建updateOne操作
+func NewMongoUpdateOneModel(filter bson.M, update interface{}, upsert bool) MongoWriteModel {
+	return MongoWriteModel{Op: MongoUpdateOne, Filter: filter, Update: update, Upsert: upsert}
+}
+
+// NewMongoUpdateManyModel 创建updateMany操作
+func NewMongoUpdateManyModel(filter bson.M, update interface{}, upsert bool) MongoWriteModel {
+	return MongoWriteModel{Op: MongoUpdateMany, Filter: filter, Update: update, Upsert: upsert}
+}
+
+// NewMongoReplaceOneModel 创建replaceOne操作
+func NewMongoReplaceOneModel(filter bson.M, replacement bson.M, upsert bool) MongoWriteModel {
+	return MongoWriteModel{Op: MongoReplaceOne, Filter: filter, Replacement: replacement, Upsert: upsert}
+}
+
+// NewMongoDeleteOneModel 创建deleteOne操作
+func NewMongoDeleteOneModel(filter bson.M) MongoWriteModel {
+	return MongoWriteModel{Op: MongoDeleteOne, Filter: filter}
+}
+
+// NewMongoDeleteManyModel 创建deleteMany操作
+func NewMongoDeleteManyModel(filter bson.M) MongoWriteModel {
+	return MongoWriteModel{Op: MongoDeleteMany, Filter: filter}
+}
+
+// MongoBulkWriteOptions MongoDB批量写入选项
+type MongoBulkWriteOptions struct {
+	// 无序执行：为false时按顺序执行，遇到第一个写入错误即停止；为true时服务端可以任意顺序执行并在出错后继续
+	Unordered bool
+	// 跳过集合的文档校验
+	BypassDocumentValidation bool
+}
+
+// MongoSchemaOptions MongoDB结构推断选项
+type MongoSchemaOptions struct {
+	// 抽样的文档数，默认为1000
+	SampleSize int
+	// 抽样前的过滤条件
+	Filter bson.M
+	// 每个字段保留的示例值数量，默认为3
+	MaxExamples int
+}
+
+// MongoWatchReq MongoDB打开变更流请求结构
+type MongoWatchReq struct {
+	// ES连接数据
+	EsConnectData EsConnectData `json:"es_connect_data"`
+	// 数据库名称，为空时监听整个集群
+	DbName string `json:"db_name"`
+	// 集合名称，为空时监听整个数据库
+	CollectionName string `json:"collection_name"`
+	// 追加在$changeStream之后的过滤管道，例如按operationType过滤
+	Pipeline bson.Pipeline `json:"pipeline"`
+	// 更新事件是否返回完整文档：default、updateLookup、whenAvailable、required
+	FullDocument string `json:"full_document"`
+	// 是否返回变更前的文档：off、whenAvailable、required
+	FullDocumentBeforeChange string `json:"full_document_before_change"`
+	// 从该恢复令牌之后继续监听
+	ResumeAfter bson.M `json:"resume_after"`
+	// 从该恢复令牌之后开始监听，与ResumeAfter不同，可以越过invalidate事件
+	StartAfter bson.M `json:"start_after"`
+	// 从该操作时间开始监听
+	StartAtOperationTime *primitive.Timestamp `json:"start_at_operation_time"`
+	// 每批返回的事件数
+	BatchSize int32 `json:"batch_size"`
+	// 没有事件时服务端等待的最长时间（毫秒）
+	MaxAwaitTimeMS int64 `json:"max_await_time_ms"`
+	// 空闲超时时间（秒），超时未拉取时基座自动关闭变更流
+	IdleTimeout int `json:"idle_timeout"`
+}
+
+// MongoWatchNextReq MongoDB变更流拉取请求结构
+type MongoWatchNextReq struct {
+	// ES连接数据
+	EsConnectData EsConnectData `json:"es_connect_data"`
+	// 变更流ID
+	StreamId string `json:"stream_id"`
+	// 本次拉取的事件数
+	BatchSize int32 `json:"batch_size"`
+	// 没有事件时服务端等待的最长时间（毫秒）
+	MaxAwaitTimeMS int64 `json:"max_await_time_ms"`
+}
+
+// MongoWatchCloseReq MongoDB关闭变更流请求结构
+type MongoWatchCloseReq struct {
+	// ES连接数据
+	EsConnectData EsConnectData `json:"es_connect_data"`
+	// 变更流ID
+	StreamId string `json:"stream_id"`
+}
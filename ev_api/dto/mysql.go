@@ -23,6 +23,8 @@ type MysqlSelectReq struct {
 	Args []interface{} `json:"args"`
 	// 数据库名称
 	DbName string `json:"dbName"`
+	// 是否返回列类型元数据
+	WithColumnTypes bool `json:"with_column_types,omitempty"`
 }
 
 type MysqlDbsReq struct {
@@ -51,3 +53,67 @@ type DsTypeReq struct {
 	// ES连接数据
 	EsConnectData EsConnectData `json:"es_connect_data"`
 }
+
+// MysqlBeginTxReq MySQL开启事务请求结构
+type MysqlBeginTxReq struct {
+	// ES连接数据
+	EsConnectData EsConnectData `json:"es_connect_data"`
+	// 数据库名称
+	DbName string `json:"dbName"`
+	// 空闲超时时间（秒），超时未操作时基座自动回滚
+	IdleTimeout int `json:"idle_timeout"`
+}
+
+// MysqlTxExecReq MySQL事务内执行SQL请求结构
+type MysqlTxExecReq struct {
+	// ES连接数据
+	EsConnectData EsConnectData `json:"es_connect_data"`
+	// 事务ID
+	TxId string `json:"tx_id"`
+	// SQL语句
+	Sql string `json:"sql"`
+	// SQL参数
+	Args []interface{} `json:"args"`
+	// 是否返回列类型元数据
+	WithColumnTypes bool `json:"with_column_types,omitempty"`
+}
+
+// MysqlTxReq MySQL事务提交/回滚请求结构
+type MysqlTxReq struct {
+	// ES连接数据
+	EsConnectData EsConnectData `json:"es_connect_data"`
+	// 事务ID
+	TxId string `json:"tx_id"`
+}
+
+// MysqlCursorOpenReq MySQL打开游标请求结构
+type MysqlCursorOpenReq struct {
+	// ES连接数据
+	EsConnectData EsConnectData `json:"es_connect_data"`
+	// 数据库名称
+	DbName string `json:"dbName"`
+	// SQL语句
+	Sql string `json:"sql"`
+	// SQL参数
+	Args []interface{} `json:"args"`
+	// 空闲超时时间（秒），超时未拉取时基座自动关闭游标
+	IdleTimeout int `json:"idle_timeout"`
+}
+
+// MysqlCursorFetchReq MySQL游标拉取请求结构
+type MysqlCursorFetchReq struct {
+	// ES连接数据
+	EsConnectData EsConnectData `json:"es_connect_data"`
+	// 游标ID
+	CursorId string `json:"cursor_id"`
+	// 本次拉取的最大行数
+	Size int `json:"size"`
+}
+
+// MysqlCursorCloseReq MySQL关闭游标请求结构
+type MysqlCursorCloseReq struct {
+	// ES连接数据
+	EsConnectData EsConnectData `json:"es_connect_data"`
+	// 游标ID
+	CursorId string `json:"cursor_id"`
+}
@@ -11,6 +11,7 @@ type RedisExecReq struct {
 	DbName int `json:"dbName"`
 }
 
+// RedisBatchExecReq Redis批量执行请求结构，基座通过pipeline一次性发送所有命令
 type RedisBatchExecReq struct {
 	// ES连接数据
 	EsConnectData EsConnectData `json:"es_connect_data"`
@@ -18,4 +19,6 @@ type RedisBatchExecReq struct {
 	Args [][]interface{} `json:"args"`
 	// 数据库索引
 	DbName int `json:"dbName"`
+	// 是否使用MULTI/EXEC包裹为事务
+	Transaction bool `json:"transaction"`
 }
@@ -5,6 +5,8 @@ package dto
 type ExecSqlReq struct {
 	// 插件ID
 	PluginId string `json:"plugin_id"`
+	// 事务ID，非空时在StoreBeginTx开启的事务内执行
+	TxId string `json:"tx_id,omitempty"`
 	// SQL语句
 	Sql string `json:"sql"`
 	// SQL参数
@@ -15,10 +17,14 @@ type ExecSqlReq struct {
 type SelectReq struct {
 	// 插件ID
 	PluginId string `json:"plugin_id"`
+	// 事务ID，非空时在StoreBeginTx开启的事务内执行
+	TxId string `json:"tx_id,omitempty"`
 	// SQL语句
 	Sql string `json:"sql"`
 	// SQL参数
 	Args []interface{} `json:"args"`
+	// 是否返回列类型元数据
+	WithColumnTypes bool `json:"with_column_types,omitempty"`
 }
 
 // ExecMoreReq 批量SQL执行请求结构
@@ -41,6 +47,8 @@ type ExecSql struct {
 type SaveDb struct {
 	// 插件ID
 	PluginId string `json:"plugin_id"`
+	// 事务ID，非空时在StoreBeginTx开启的事务内执行
+	TxId string `json:"tx_id,omitempty"`
 	// 目标表名
 	TableName string `json:"table"` // 目标表名
 	// 要插入或更新的数据
@@ -51,6 +59,8 @@ type SaveDb struct {
 type UpdateDb struct {
 	// 插件ID
 	PluginId string `json:"plugin_id"`
+	// 事务ID，非空时在StoreBeginTx开启的事务内执行
+	TxId string `json:"tx_id,omitempty"`
 	// 目标表名
 	TableName string `json:"table"` // 目标表名
 	// 更新SQL语句
@@ -65,6 +75,8 @@ type UpdateDb struct {
 type InsertOrUpdateDb struct {
 	// 插件ID
 	PluginId string `json:"plugin_id"`
+	// 事务ID，非空时在StoreBeginTx开启的事务内执行
+	TxId string `json:"tx_id,omitempty"`
 	// 目标表名
 	TableName string `json:"table"` // 目标表名
 	// 没有则新增，有则更新的数据
@@ -77,6 +89,8 @@ type InsertOrUpdateDb struct {
 type DeleteDb struct {
 	// 插件ID
 	PluginId string `json:"plugin_id"`
+	// 事务ID，非空时在StoreBeginTx开启的事务内执行
+	TxId string `json:"tx_id,omitempty"`
 	// 目标表名
 	TableName string `json:"table"` // 目标表名
 	// WHERE条件SQL
@@ -84,3 +98,19 @@ type DeleteDb struct {
 	// WHERE条件参数
 	WhereArgs []interface{} `json:"where_args"`
 }
+
+// StoreBeginTxReq 插件存储开启事务请求结构
+type StoreBeginTxReq struct {
+	// 插件ID
+	PluginId string `json:"plugin_id"`
+	// 空闲超时时间（秒），超时未操作时基座自动回滚
+	IdleTimeout int `json:"idle_timeout"`
+}
+
+// StoreTxReq 插件存储事务提交/回滚请求结构
+type StoreTxReq struct {
+	// 插件ID
+	PluginId string `json:"plugin_id"`
+	// 事务ID
+	TxId string `json:"tx_id"`
+}
@@ -13,6 +13,8 @@ import (
 	"github.com/1340691923/eve-plugin-sdk-go/ev_api/dto"
 	// Protobuf协议包
 	"github.com/1340691923/eve-plugin-sdk-go/ev_api/proto"
+	// 视图对象包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/vo"
 	// 错误处理包
 	"github.com/pkg/errors"
 	// IO操作包
@@ -452,6 +454,25 @@ func (this *EvApiAdapter) MysqlFirstSql(ctx context.Context, dbName, sql string,
 	})
 }
 
+// MysqlSelectRows 执行MySQL查询语句，返回有序列名、列类型元数据及结果，数字以json.Number保留精度
+// 参数：
+//   - ctx: 上下文
+//   - dbName: 数据库名称
+//   - sql: SQL语句
+//   - args: SQL参数
+//
+// 返回：
+//   - res: 查询结果
+//   - err: 错误信息
+func (this *EvApiAdapter) MysqlSelectRows(ctx context.Context, dbName, sql string, args ...interface{}) (res *vo.MysqlSelectSqlRes, err error) {
+	return GetEvApi().MysqlSelectRows(ctx, &dto.MysqlSelectReq{
+		EsConnectData: this.buildEsConnectData(),
+		DbName:        dbName,
+		Sql:           sql,
+		Args:          args,
+	})
+}
+
 // MysqlDbs 获取MySQL数据库列表
 // 参数：
 //   - ctx: 上下文
@@ -488,7 +509,7 @@ func (this *EvApiAdapter) MysqlTables(ctx context.Context, dbName string) (table
 //
 // 返回：
 //   - data: 执行结果
-//   - err: 错误信息
+//   - err: 错误信息，nil回复返回vo.ErrRedisNil
 func (this *EvApiAdapter) RedisExecCommand(ctx context.Context, dbName int, args ...interface{}) (data interface{}, err error) {
 	return GetEvApi().RedisExecCommand(ctx, &dto.RedisExecReq{
 		EsConnectData: this.buildEsConnectData(),
@@ -497,6 +518,46 @@ func (this *EvApiAdapter) RedisExecCommand(ctx context.Context, dbName int, args
 	})
 }
 
+// RedisBatchExec 通过pipeline批量执行Redis命令，只需一次基座往返
+// 单条命令失败不影响其他命令，错误通过RedisReply.Err()获取
+// 参数：
+//   - ctx: 上下文
+//   - dbName: 数据库编号
+//   - cmds: 命令列表，每条命令为命令名及参数，例如 []interface{}{"SET", "k", "v"}
+//
+// 返回：
+//   - replies: 与命令一一对应的执行结果
+//   - err: 整批执行的错误信息
+func (this *EvApiAdapter) RedisBatchExec(ctx context.Context, dbName int, cmds [][]interface{}) (replies []vo.RedisReply, err error) {
+	return this.redisBatchExec(ctx, dbName, cmds, false)
+}
+
+// RedisTxBatchExec 使用MULTI/EXEC事务批量执行Redis命令，命令要么全部执行要么全部不执行
+// 参数：
+//   - ctx: 上下文
+//   - dbName: 数据库编号
+//   - cmds: 命令列表
+//
+// 返回：
+//   - replies: 与命令一一对应的执行结果
+//   - err: 整批执行的错误信息，事务被放弃时返回错误
+func (this *EvApiAdapter) RedisTxBatchExec(ctx context.Context, dbName int, cmds [][]interface{}) (replies []vo.RedisReply, err error) {
+	return this.redisBatchExec(ctx, dbName, cmds, true)
+}
+
+// redisBatchExec 批量执行Redis命令
+func (this *EvApiAdapter) redisBatchExec(ctx context.Context, dbName int, cmds [][]interface{}, transaction bool) (replies []vo.RedisReply, err error) {
+	if len(cmds) == 0 {
+		return []vo.RedisReply{}, nil
+	}
+	return GetEvApi().RedisBatchExec(ctx, &dto.RedisBatchExecReq{
+		EsConnectData: this.buildEsConnectData(),
+		DbName:        dbName,
+		Args:          cmds,
+		Transaction:   transaction,
+	})
+}
+
 // ExecMongoCommand 执行MongoDB命令
 // 参数：
 //   - ctx: 上下文
@@ -848,9 +848,14 @@ func (this *evApi) EsTasksCancel(ctx context.Context, req dto.TasksCancelReq) (r
 //   - rowsAffected: 影响的行数
 //   - err: 错误信息
 func (this *evApi) StoreExec(ctx context.Context, sql string, args ...interface{}) (rowsAffected int64, err error) {
+	return this.storeExec(ctx, "", sql, args...)
+}
+
+// storeExec 在txId指定的事务内执行StoreExec，txId为空时不使用事务
+func (this *evApi) storeExec(ctx context.Context, txId string, sql string, args ...interface{}) (rowsAffected int64, err error) {
 	data := &vo.ExecSqlRes{}
 	err = this.request(ctx, "api/plugin_util/ExecSql",
-		&dto.ExecSqlReq{PluginId: this.pluginId, Sql: sql, Args: args},
+		&dto.ExecSqlReq{PluginId: this.pluginId, TxId: txId, Sql: sql, Args: args},
 		&vo.ApiCommonRes{Data: data})
 	if err != nil {
 		return 0, err
@@ -950,7 +955,12 @@ func (this *evApi) LiveBroadcastEvMsg2Users(ctx context.Context, notice *dto.Not
 // 返回：
 //   - err: 错误信息
 func (this *evApi) StoreSave(ctx context.Context, table string, data interface{}) (err error) {
-	err = this.request(ctx, "api/plugin_util/SaveDb", &dto.SaveDb{PluginId: this.pluginId, TableName: table, Data: data}, &vo.ApiCommonRes{})
+	return this.storeSave(ctx, "", table, data)
+}
+
+// storeSave 在txId指定的事务内执行StoreSave，txId为空时不使用事务
+func (this *evApi) storeSave(ctx context.Context, txId string, table string, data interface{}) (err error) {
+	err = this.request(ctx, "api/plugin_util/SaveDb", &dto.SaveDb{PluginId: this.pluginId, TxId: txId, TableName: table, Data: data}, &vo.ApiCommonRes{})
 	if err != nil {
 		return errors.WithStack(err)
 	}
@@ -969,9 +979,14 @@ func (this *evApi) StoreSave(ctx context.Context, table string, data interface{}
 //   - rowsAffected: 影响的行数
 //   - err: 错误信息
 func (this *evApi) StoreUpdate(ctx context.Context, table string, updateData map[string]interface{}, whereSql string, whereArgs ...interface{}) (rowsAffected int64, err error) {
+	return this.storeUpdate(ctx, "", table, updateData, whereSql, whereArgs...)
+}
+
+// storeUpdate 在txId指定的事务内执行StoreUpdate，txId为空时不使用事务
+func (this *evApi) storeUpdate(ctx context.Context, txId string, table string, updateData map[string]interface{}, whereSql string, whereArgs ...interface{}) (rowsAffected int64, err error) {
 	data := &vo.ExecSqlRes{}
 	err = this.request(ctx, "api/plugin_util/UpdateDb",
-		&dto.UpdateDb{PluginId: this.pluginId, TableName: table, Data: updateData, UpdateArgs: whereArgs, UpdateSql: whereSql}, &vo.ApiCommonRes{Data: data})
+		&dto.UpdateDb{PluginId: this.pluginId, TxId: txId, TableName: table, Data: updateData, UpdateArgs: whereArgs, UpdateSql: whereSql}, &vo.ApiCommonRes{Data: data})
 	if err != nil {
 		return 0, err
 	}
@@ -989,9 +1004,14 @@ func (this *evApi) StoreUpdate(ctx context.Context, table string, updateData map
 //   - rowsAffected: 影响的行数
 //   - err: 错误信息
 func (this *evApi) StoreDelete(ctx context.Context, tableName, whereSql string, whereArgs ...interface{}) (rowsAffected int64, err error) {
+	return this.storeDelete(ctx, "", tableName, whereSql, whereArgs...)
+}
+
+// storeDelete 在txId指定的事务内执行StoreDelete，txId为空时不使用事务
+func (this *evApi) storeDelete(ctx context.Context, txId string, tableName, whereSql string, whereArgs ...interface{}) (rowsAffected int64, err error) {
 	data := &vo.ExecSqlRes{}
 	err = this.request(ctx, "api/plugin_util/DeleteDb",
-		&dto.DeleteDb{PluginId: this.pluginId, TableName: tableName, WhereArgs: whereArgs, WhereSql: whereSql}, &vo.ApiCommonRes{Data: data})
+		&dto.DeleteDb{PluginId: this.pluginId, TxId: txId, TableName: tableName, WhereArgs: whereArgs, WhereSql: whereSql}, &vo.ApiCommonRes{Data: data})
 	if err != nil {
 		return 0, err
 	}
@@ -1008,8 +1028,13 @@ func (this *evApi) StoreDelete(ctx context.Context, tableName, whereSql string,
 // 返回：
 //   - err: 错误信息
 func (this *evApi) StoreInsertOrUpdate(ctx context.Context, table string, upsertData map[string]interface{}, uniqueKeys ...string) (err error) {
+	return this.storeInsertOrUpdate(ctx, "", table, upsertData, uniqueKeys...)
+}
+
+// storeInsertOrUpdate 在txId指定的事务内执行StoreInsertOrUpdate，txId为空时不使用事务
+func (this *evApi) storeInsertOrUpdate(ctx context.Context, txId string, table string, upsertData map[string]interface{}, uniqueKeys ...string) (err error) {
 	err = this.request(ctx, "api/plugin_util/InsertOrUpdateDb",
-		&dto.InsertOrUpdateDb{PluginId: this.pluginId, TableName: table, UpsertData: upsertData, UniqueKeys: uniqueKeys}, &vo.ApiCommonRes{})
+		&dto.InsertOrUpdateDb{PluginId: this.pluginId, TxId: txId, TableName: table, UpsertData: upsertData, UniqueKeys: uniqueKeys}, &vo.ApiCommonRes{})
 	if err != nil {
 		return errors.WithStack(err)
 	}
@@ -1095,9 +1120,14 @@ func (this *evApi) GetEveToken(ctx context.Context) (token string, err error) {
 // 返回：
 //   - err: 错误信息
 func (this *evApi) StoreSelect(ctx context.Context, dest interface{}, sql string, args ...interface{}) (err error) {
+	return this.storeSelect(ctx, "", dest, sql, args...)
+}
+
+// storeSelect 在txId指定的事务内执行StoreSelect，txId为空时不使用事务
+func (this *evApi) storeSelect(ctx context.Context, txId string, dest interface{}, sql string, args ...interface{}) (err error) {
 	data := &vo.SelectRes{}
 	data.Result = &dest
-	err = this.request(ctx, "api/plugin_util/SelectSql", &dto.SelectReq{Sql: sql, PluginId: this.pluginId, Args: args}, &vo.ApiCommonRes{Data: data}, true)
+	err = this.request(ctx, "api/plugin_util/SelectSql", &dto.SelectReq{Sql: sql, PluginId: this.pluginId, TxId: txId, Args: args}, &vo.ApiCommonRes{Data: data}, true)
 	if err != nil {
 		return errors.WithStack(err)
 	}
@@ -1105,6 +1135,52 @@ func (this *evApi) StoreSelect(ctx context.Context, dest interface{}, sql string
 	return nil
 }
 
+// StoreSelectRows 查询插件存储，返回结果及列类型元数据，数字以json.Number保留精度
+// 参数：
+//   - ctx: 上下文
+//   - sql: SQL语句
+//   - args: SQL参数
+//
+// 返回：
+//   - res: 查询结果
+//   - err: 错误信息
+func (this *evApi) StoreSelectRows(ctx context.Context, sql string, args ...interface{}) (res *vo.StoreRowsRes, err error) {
+	return this.storeSelectRows(ctx, "", sql, args...)
+}
+
+// storeSelectRows 在txId指定的事务内执行StoreSelectRows，txId为空时不使用事务
+func (this *evApi) storeSelectRows(ctx context.Context, txId string, sql string, args ...interface{}) (res *vo.StoreRowsRes, err error) {
+	res = &vo.StoreRowsRes{}
+	err = this.requestUseNumber(ctx, "api/plugin_util/SelectSql", &dto.SelectReq{Sql: sql, PluginId: this.pluginId, TxId: txId, Args: args, WithColumnTypes: true}, &vo.ApiCommonRes{Data: res})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return res, nil
+}
+
+// StoreFirstRow 查询插件存储的第一条记录，返回结果及列类型元数据，数字以json.Number保留精度
+// 参数：
+//   - ctx: 上下文
+//   - sql: SQL语句
+//   - args: SQL参数
+//
+// 返回：
+//   - res: 查询结果
+//   - err: 错误信息
+func (this *evApi) StoreFirstRow(ctx context.Context, sql string, args ...interface{}) (res *vo.StoreRowRes, err error) {
+	return this.storeFirstRow(ctx, "", sql, args...)
+}
+
+// storeFirstRow 在txId指定的事务内执行StoreFirstRow，txId为空时不使用事务
+func (this *evApi) storeFirstRow(ctx context.Context, txId string, sql string, args ...interface{}) (res *vo.StoreRowRes, err error) {
+	res = &vo.StoreRowRes{}
+	err = this.requestUseNumber(ctx, "api/plugin_util/FirstSql", &dto.SelectReq{Sql: sql, PluginId: this.pluginId, TxId: txId, Args: args, WithColumnTypes: true}, &vo.ApiCommonRes{Data: res})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return res, nil
+}
+
 // GetRoles4UserID 根据用户ID获取角色列表
 // 参数：
 //   - ctx: 上下文
@@ -1133,9 +1209,42 @@ func (this *evApi) GetRoles4UserID(ctx context.Context, userId int) (roleIds []i
 // 返回：
 //   - err: 错误信息
 func (this *evApi) StoreFirst(ctx context.Context, dest interface{}, sql string, args ...interface{}) (err error) {
+	return this.storeFirst(ctx, "", dest, sql, args...)
+}
+
+// storeFirst 在txId指定的事务内执行StoreFirst，txId为空时不使用事务
+func (this *evApi) storeFirst(ctx context.Context, txId string, dest interface{}, sql string, args ...interface{}) (err error) {
 	data := &vo.SelectRes{}
 	data.Result = &dest
-	err = this.request(ctx, "api/plugin_util/FirstSql", &dto.SelectReq{Sql: sql, PluginId: this.pluginId, Args: args}, &vo.ApiCommonRes{Data: data}, true)
+	err = this.request(ctx, "api/plugin_util/FirstSql", &dto.SelectReq{Sql: sql, PluginId: this.pluginId, TxId: txId, Args: args}, &vo.ApiCommonRes{Data: data}, true)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// storeBeginTx 开启插件存储事务，基座将事务固定在一个连接上并返回事务ID
+func (this *evApi) storeBeginTx(ctx context.Context, idleTimeout int) (txId string, err error) {
+	data := &vo.StoreBeginTxRes{}
+	err = this.request(ctx, "api/plugin_util/StoreBeginTx", &dto.StoreBeginTxReq{PluginId: this.pluginId, IdleTimeout: idleTimeout}, &vo.ApiCommonRes{Data: data})
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return data.TxId, nil
+}
+
+// storeTxCommit 提交插件存储事务
+func (this *evApi) storeTxCommit(ctx context.Context, txId string) (err error) {
+	err = this.request(ctx, "api/plugin_util/StoreTxCommit", &dto.StoreTxReq{PluginId: this.pluginId, TxId: txId}, &vo.ApiCommonRes{})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// storeTxRollback 回滚插件存储事务
+func (this *evApi) storeTxRollback(ctx context.Context, txId string) (err error) {
+	err = this.request(ctx, "api/plugin_util/StoreTxRollback", &dto.StoreTxReq{PluginId: this.pluginId, TxId: txId}, &vo.ApiCommonRes{})
 	if err != nil {
 		return errors.WithStack(err)
 	}
@@ -1243,6 +1352,44 @@ func (this *evApi) MysqlFirstSql(ctx context.Context, req *dto.MysqlSelectReq) (
 	return data.Result, nil
 }
 
+// MysqlSelectRows 执行MySQL查询语句，返回结果及列类型元数据，数字以json.Number保留精度
+// 参数：
+//   - ctx: 上下文
+//   - req: MySQL查询请求
+//
+// 返回：
+//   - res: 查询结果
+//   - err: 错误信息
+func (this *evApi) MysqlSelectRows(ctx context.Context, req *dto.MysqlSelectReq) (res *vo.MysqlSelectSqlRes, err error) {
+	res = &vo.MysqlSelectSqlRes{}
+	req.DbName = fmt.Sprintf("`%s`", req.DbName)
+	req.WithColumnTypes = true
+	err = this.requestUseNumber(ctx, "api/plugin_util/MysqlSelectSql", req, &vo.ApiCommonRes{Data: res})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return res, nil
+}
+
+// MysqlFirstRow 查询MySQL第一条记录，返回结果及列类型元数据，数字以json.Number保留精度
+// 参数：
+//   - ctx: 上下文
+//   - req: MySQL查询请求
+//
+// 返回：
+//   - res: 查询结果
+//   - err: 错误信息
+func (this *evApi) MysqlFirstRow(ctx context.Context, req *dto.MysqlSelectReq) (res *vo.MysqlFirstSqlRes, err error) {
+	res = &vo.MysqlFirstSqlRes{}
+	req.DbName = fmt.Sprintf("`%s`", req.DbName)
+	req.WithColumnTypes = true
+	err = this.requestUseNumber(ctx, "api/plugin_util/MysqlFirstSql", req, &vo.ApiCommonRes{Data: res})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return res, nil
+}
+
 func (this *evApi) MysqlDbs(ctx context.Context, req *dto.MysqlDbsReq) (dbs []string, err error) {
 	data := &vo.MysqlDbsRes{}
 	err = this.request(ctx, "api/plugin_util/MysqlFirstSql", req, &vo.ApiCommonRes{Data: data}, true)
@@ -1271,14 +1418,182 @@ func (this *evApi) MysqlTables(ctx context.Context, req *dto.MysqlTablesReq) (ta
 	return data.Tables, nil
 }
 
+// MysqlBeginTx 开启MySQL事务，基座将事务固定在一个连接上并返回事务ID
+// 参数：
+//   - ctx: 上下文
+//   - req: 开启事务请求
+//
+// 返回：
+//   - txId: 事务ID
+//   - err: 错误信息
+func (this *evApi) MysqlBeginTx(ctx context.Context, req *dto.MysqlBeginTxReq) (txId string, err error) {
+	data := &vo.MysqlBeginTxRes{}
+	req.DbName = fmt.Sprintf("`%s`", req.DbName)
+	err = this.request(ctx, "api/plugin_util/MysqlBeginTx", req, &vo.ApiCommonRes{Data: data})
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return data.TxId, nil
+}
+
+// MysqlTxExecSql 在事务内执行MySQL SQL语句
+// 参数：
+//   - ctx: 上下文
+//   - req: 事务内执行请求
+//
+// 返回：
+//   - rowsAffected: 影响的行数
+//   - err: 错误信息
+func (this *evApi) MysqlTxExecSql(ctx context.Context, req *dto.MysqlTxExecReq) (rowsAffected int64, err error) {
+	data := &vo.MysqlExecSqlRes{}
+	err = this.request(ctx, "api/plugin_util/MysqlTxExecSql", req, &vo.ApiCommonRes{Data: data})
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	return data.RowsAffected, nil
+}
+
+// MysqlTxSelectSql 在事务内查询MySQL数据，返回列名和结果
+// 参数：
+//   - ctx: 上下文
+//   - req: 事务内查询请求
+//
+// 返回：
+//   - columns: 列名列表
+//   - result: 查询结果
+//   - err: 错误信息
+func (this *evApi) MysqlTxSelectSql(ctx context.Context, req *dto.MysqlTxExecReq) (columns []string, result []map[string]interface{}, err error) {
+	data := &vo.MysqlSelectSqlRes{}
+	err = this.request(ctx, "api/plugin_util/MysqlTxSelectSql", req, &vo.ApiCommonRes{Data: data}, true)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	return data.Columns, data.Result, nil
+}
+
+// MysqlTxSelectRows 在事务内查询MySQL数据，返回有序列名、列类型元数据及结果，数字以json.Number保留精度
+// 参数：
+//   - ctx: 上下文
+//   - req: 事务内查询请求
+//
+// 返回：
+//   - res: 查询结果
+//   - err: 错误信息
+func (this *evApi) MysqlTxSelectRows(ctx context.Context, req *dto.MysqlTxExecReq) (res *vo.MysqlSelectSqlRes, err error) {
+	res = &vo.MysqlSelectSqlRes{}
+	req.WithColumnTypes = true
+	err = this.requestUseNumber(ctx, "api/plugin_util/MysqlTxSelectSql", req, &vo.ApiCommonRes{Data: res})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return res, nil
+}
+
+// MysqlTxFirstSql 在事务内查询MySQL第一条记录
+// 参数：
+//   - ctx: 上下文
+//   - req: 事务内查询请求
+//
+// 返回：
+//   - result: 查询结果
+//   - err: 错误信息
+func (this *evApi) MysqlTxFirstSql(ctx context.Context, req *dto.MysqlTxExecReq) (result map[string]interface{}, err error) {
+	data := &vo.MysqlFirstSqlRes{}
+	err = this.request(ctx, "api/plugin_util/MysqlTxFirstSql", req, &vo.ApiCommonRes{Data: data}, true)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return data.Result, nil
+}
+
+// MysqlTxCommit 提交MySQL事务
+// 参数：
+//   - ctx: 上下文
+//   - req: 事务请求
+//
+// 返回：
+//   - err: 错误信息
+func (this *evApi) MysqlTxCommit(ctx context.Context, req *dto.MysqlTxReq) (err error) {
+	err = this.request(ctx, "api/plugin_util/MysqlTxCommit", req, &vo.ApiCommonRes{})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// MysqlTxRollback 回滚MySQL事务
+// 参数：
+//   - ctx: 上下文
+//   - req: 事务请求
+//
+// 返回：
+//   - err: 错误信息
+func (this *evApi) MysqlTxRollback(ctx context.Context, req *dto.MysqlTxReq) (err error) {
+	err = this.request(ctx, "api/plugin_util/MysqlTxRollback", req, &vo.ApiCommonRes{})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// MysqlCursorOpen 打开MySQL服务端游标
+// 参数：
+//   - ctx: 上下文
+//   - req: 打开游标请求
+//
+// 返回：
+//   - res: 游标ID及列信息
+//   - err: 错误信息
+func (this *evApi) MysqlCursorOpen(ctx context.Context, req *dto.MysqlCursorOpenReq) (res *vo.MysqlCursorOpenRes, err error) {
+	res = &vo.MysqlCursorOpenRes{}
+	req.DbName = fmt.Sprintf("`%s`", req.DbName)
+	err = this.request(ctx, "api/plugin_util/MysqlCursorOpen", req, &vo.ApiCommonRes{Data: res}, true)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return res, nil
+}
+
+// MysqlCursorFetch 从MySQL服务端游标拉取一批数据，数字以json.Number保留精度
+// 参数：
+//   - ctx: 上下文
+//   - req: 拉取请求
+//
+// 返回：
+//   - res: 本批数据
+//   - err: 错误信息
+func (this *evApi) MysqlCursorFetch(ctx context.Context, req *dto.MysqlCursorFetchReq) (res *vo.MysqlCursorFetchRes, err error) {
+	res = &vo.MysqlCursorFetchRes{}
+	err = this.requestUseNumber(ctx, "api/plugin_util/MysqlCursorFetch", req, &vo.ApiCommonRes{Data: res})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return res, nil
+}
+
+// MysqlCursorClose 关闭MySQL服务端游标
+// 参数：
+//   - ctx: 上下文
+//   - req: 关闭游标请求
+//
+// 返回：
+//   - err: 错误信息
+func (this *evApi) MysqlCursorClose(ctx context.Context, req *dto.MysqlCursorCloseReq) (err error) {
+	err = this.request(ctx, "api/plugin_util/MysqlCursorClose", req, &vo.ApiCommonRes{})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
 // RedisExecCommand 执行Redis命令
 // 参数：
 //   - ctx: 上下文
 //   - req: Redis执行请求
 //
 // 返回：
-//   - data: 执行结果
-//   - err: 错误信息
+//   - data: 执行结果，整数回复为int64，不会因经过JSON传输而丢失精度
+//   - err: 错误信息，nil回复返回vo.ErrRedisNil
 func (this *evApi) RedisExecCommand(ctx context.Context, req *dto.RedisExecReq) (data interface{}, err error) {
 
 	result, err := this.requestProtobuf(ctx, "api/plugin_util/RedisExecCommand", req)
@@ -1286,19 +1601,80 @@ func (this *evApi) RedisExecCommand(ctx context.Context, req *dto.RedisExecReq)
 		return data, err
 	}
 
-	if result.StatusErr() != nil {
-		return data, result.StatusErr()
+	if err = result.StatusErr(); err != nil {
+		// 基座以状态错误返回nil回复，错误消息与vo.ErrRedisNil一致
+		var status struct {
+			Msg string `json:"msg"`
+		}
+		if json2.Unmarshal(result.ResByte(), &status) == nil && status.Msg == vo.ErrRedisNil.Error() {
+			return data, vo.ErrRedisNil
+		}
+		return data, err
 	}
 
 	res := map[string]interface{}{}
+	dec := json2.NewDecoder(bytes.NewReader(result.ResByte()))
+	dec.UseNumber()
+	if err = dec.Decode(&res); err != nil {
+		return data, errors.WithStack(err)
+	}
 
-	err = json2.Unmarshal(result.ResByte(), &res)
+	return redisNumber(res["data"]), nil
+}
 
+// RedisBatchExec 通过pipeline批量执行Redis命令
+// 参数：
+//   - ctx: 上下文
+//   - req: Redis批量执行请求
+//
+// 返回：
+//   - replies: 与命令一一对应的执行结果
+//   - err: 错误信息
+func (this *evApi) RedisBatchExec(ctx context.Context, req *dto.RedisBatchExecReq) (replies []vo.RedisReply, err error) {
+	result, err := this.requestProtobuf(ctx, "api/plugin_util/RedisBatchExec", req)
 	if err != nil {
-		return data, err
+		return nil, err
+	}
+
+	if result.StatusErr() != nil {
+		return nil, result.StatusErr()
+	}
+
+	res := &vo.RedisBatchExecRes{}
+	dec := json2.NewDecoder(bytes.NewReader(result.ResByte()))
+	dec.UseNumber()
+	if err = dec.Decode(res); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if len(res.Data) != len(req.Args) {
+		return nil, errors.Errorf("Redis批量执行结果数量不匹配：命令%d条，结果%d条", len(req.Args), len(res.Data))
 	}
 
-	return res["data"], nil
+	for i := range res.Data {
+		res.Data[i].Value = redisNumber(res.Data[i].Value)
+	}
+	return res.Data, nil
+}
+
+// redisNumber 将json.Number还原为int64（Redis整数回复）或float64（RESP3浮点回复）
+func redisNumber(v interface{}) interface{} {
+	switch val := v.(type) {
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			return i
+		}
+		f, _ := val.Float64()
+		return f
+	case []interface{}:
+		for i := range val {
+			val[i] = redisNumber(val[i])
+		}
+	case map[string]interface{}:
+		for k := range val {
+			val[k] = redisNumber(val[k])
+		}
+	}
+	return v
 }
 
 // ExecMongoCommand 执行MongoDB命令
@@ -1311,7 +1687,7 @@ func (this *evApi) RedisExecCommand(ctx context.Context, req *dto.RedisExecReq)
 //   - err: 错误信息
 func (this *evApi) ExecMongoCommand(ctx context.Context, req *dto.MongoExecReq) (data bson.M, err error) {
 
-	res, err := this.requestProtobuf(ctx, "api/plugin_util/MongoExecCommand", req)
+	res, err := this.requestProtobuf(ctx, "api/plugin_util/MongoExecCommand", mongoReq{req})
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -1319,9 +1695,7 @@ func (this *evApi) ExecMongoCommand(ctx context.Context, req *dto.MongoExecReq)
 		return nil, res.StatusErr()
 	}
 
-	data = map[string]interface{}{}
-
-	err = json2.Unmarshal(res.ResByte(), &data)
+	err = bson.UnmarshalExtJSON(res.ResByte(), true, &data)
 
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -1330,6 +1704,21 @@ func (this *evApi) ExecMongoCommand(ctx context.Context, req *dto.MongoExecReq)
 	return data, nil
 }
 
+// ExecMongoCommandOrdered 执行MongoDB命令，结果保持字段顺序，嵌套文档同样为bson.D
+// 参数：
+//   - ctx: 上下文
+//   - req: MongoDB执行请求
+//
+// 返回：
+//   - data: 执行结果
+//   - err: 错误信息
+func (this *evApi) ExecMongoCommandOrdered(ctx context.Context, req *dto.MongoExecReq) (data bson.D, err error) {
+	if err = this.requestExtJSON(ctx, "api/plugin_util/MongoExecCommand", req, &data); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return data, nil
+}
+
 // ShowMongoDbs 显示MongoDB数据库列表
 // 参数：
 //   - ctx: 上下文
@@ -1340,7 +1729,7 @@ func (this *evApi) ExecMongoCommand(ctx context.Context, req *dto.MongoExecReq)
 //   - err: 错误信息
 func (this *evApi) ShowMongoDbs(ctx context.Context, req *dto.ShowMongoDbsReq) (dbList []string, err error) {
 	res := &vo.ApiCommonRes{Data: dbList}
-	err = this.request(ctx, "api/plugin_util/ShowMongoDbs", req, res)
+	err = this.request(ctx, "api/plugin_util/ShowMongoDbs", mongoReq{req}, res)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -1351,7 +1740,7 @@ func (this *evApi) ShowMongoDbs(ctx context.Context, req *dto.ShowMongoDbsReq) (
 // FindMongoDocuments 查找MongoDB集合
 func (this *evApi) GetMongoCollections(ctx context.Context, req *dto.GetMongoCollectionsReq) (dbList []string, err error) {
 	res := &vo.ApiCommonRes{Data: dbList}
-	err = this.request(ctx, "api/plugin_util/GetMongoCollections", req, res)
+	err = this.request(ctx, "api/plugin_util/GetMongoCollections", mongoReq{req}, res)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -1361,7 +1750,7 @@ func (this *evApi) GetMongoCollections(ctx context.Context, req *dto.GetMongoCol
 
 // FindMongoDocuments 查找MongoDB文档
 func (this *evApi) FindMongoDocuments(ctx context.Context, req *dto.FindMongoDocumentsReq) (data []bson.M, err error) {
-	res, err := this.requestProtobuf(ctx, "api/plugin_util/FindMongoDocuments", req)
+	res, err := this.requestProtobuf(ctx, "api/plugin_util/FindMongoDocuments", mongoReq{req})
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -1369,9 +1758,7 @@ func (this *evApi) FindMongoDocuments(ctx context.Context, req *dto.FindMongoDoc
 		return nil, res.StatusErr()
 	}
 
-	data = []bson.M{}
-
-	err = json2.Unmarshal(res.ResByte(), &data)
+	err = bson.UnmarshalExtJSON(res.ResByte(), true, &data)
 
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -1383,16 +1770,20 @@ func (this *evApi) FindMongoDocuments(ctx context.Context, req *dto.FindMongoDoc
 // UpdateMongoDocument 更新MongoDB文档
 func (this *evApi) UpdateMongoDocument(ctx context.Context, req *dto.UpdateMongoDocumentReq) (res *vo.MongoUpdateRes, err error) {
 	result := &vo.ApiCommonRes{Data: res}
-	err = this.request(ctx, "api/plugin_util/UpdateMongoDocument", req, result)
+	err = this.request(ctx, "api/plugin_util/UpdateMongoDocument", mongoReq{req}, result)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 	resMap := cast.ToStringMap(result.Data)
+	upsertedID, err := decodeExtJSONValue(resMap["UpsertedID"])
+	if err != nil {
+		return nil, err
+	}
 	res = &vo.MongoUpdateRes{
 		MatchedCount:  cast.ToInt64(resMap["MatchedCount"]),
 		ModifiedCount: cast.ToInt64(resMap["ModifiedCount"]),
 		UpsertedCount: cast.ToInt64(resMap["UpsertedCount"]),
-		UpsertedID:    resMap["UpsertedID"],
+		UpsertedID:    upsertedID,
 	}
 	return res, nil
 }
@@ -1400,7 +1791,7 @@ func (this *evApi) UpdateMongoDocument(ctx context.Context, req *dto.UpdateMongo
 // DeleteMongoDocument 删除MongoDB文档 返回删除条数
 func (this *evApi) DeleteMongoDocument(ctx context.Context, req *dto.DeleteMongoDocumentReq) (res int64, err error) {
 	result := &vo.ApiCommonRes{Data: res}
-	err = this.request(ctx, "api/plugin_util/DeleteMongoDocument", req, result)
+	err = this.request(ctx, "api/plugin_util/DeleteMongoDocument", mongoReq{req}, result)
 	if err != nil {
 		return 0, errors.WithStack(err)
 	}
@@ -1411,29 +1802,38 @@ func (this *evApi) DeleteMongoDocument(ctx context.Context, req *dto.DeleteMongo
 // InsertMongoDocument 插入MongoDB文档 返回插入后的id
 func (this *evApi) InsertMongoDocument(ctx context.Context, req *dto.InsertMongoDocumentReq) (res string, err error) {
 	result := &vo.ApiCommonRes{Data: res}
-	err = this.request(ctx, "api/plugin_util/InsertMongoDocument", req, result)
+	err = this.request(ctx, "api/plugin_util/InsertMongoDocument", mongoReq{req}, result)
 	if err != nil {
 		return "", errors.WithStack(err)
 	}
 
-	return cast.ToString(result.Data), nil
+	return extJSONIdString(result.Data)
 }
 
 // InsertManyMongoDocuments 批量插入MongoDB文档 返回插入的id列表
 func (this *evApi) InsertManyMongoDocuments(ctx context.Context, req *dto.InsertManyMongoDocumentsReq) (res []string, err error) {
 	result := &vo.ApiCommonRes{Data: res}
-	err = this.request(ctx, "api/plugin_util/InsertManyMongoDocuments", req, result)
+	err = this.request(ctx, "api/plugin_util/InsertManyMongoDocuments", mongoReq{req}, result)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
-	return cast.ToStringSlice(result.Data), nil
+	ids := cast.ToSlice(result.Data)
+	res = make([]string, 0, len(ids))
+	for _, id := range ids {
+		idStr, err := extJSONIdString(id)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, idStr)
+	}
+	return res, nil
 }
 
 // DeleteManyMongoDocuments 批量删除MongoDB文档 返回删除条数
 func (this *evApi) DeleteManyMongoDocuments(ctx context.Context, req *dto.DeleteManyMongoDocumentsReq) (res int64, err error) {
 	result := &vo.ApiCommonRes{Data: res}
-	err = this.request(ctx, "api/plugin_util/DeleteManyMongoDocuments", req, result)
+	err = this.request(ctx, "api/plugin_util/DeleteManyMongoDocuments", mongoReq{req}, result)
 	if err != nil {
 		return 0, errors.WithStack(err)
 	}
@@ -1444,7 +1844,7 @@ func (this *evApi) DeleteManyMongoDocuments(ctx context.Context, req *dto.Delete
 // CountMongoDocuments 统计MongoDB文档数量 返回条数
 func (this *evApi) CountMongoDocuments(ctx context.Context, req *dto.CountMongoDocumentsReq) (res int64, err error) {
 	result := &vo.ApiCommonRes{}
-	err = this.request(ctx, "api/plugin_util/CountMongoDocuments", req, result)
+	err = this.request(ctx, "api/plugin_util/CountMongoDocuments", mongoReq{req}, result)
 	if err != nil {
 		return 0, errors.WithStack(err)
 	}
@@ -1453,7 +1853,7 @@ func (this *evApi) CountMongoDocuments(ctx context.Context, req *dto.CountMongoD
 
 // AggregateMongoDocuments 聚合查询MongoDB文档
 func (this *evApi) AggregateMongoDocuments(ctx context.Context, req *dto.AggregateMongoDocumentsReq) (data []bson.M, err error) {
-	res, err := this.requestProtobuf(ctx, "api/plugin_util/AggregateMongoDocuments", req)
+	res, err := this.requestProtobuf(ctx, "api/plugin_util/AggregateMongoDocuments", mongoReq{req})
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -1461,9 +1861,7 @@ func (this *evApi) AggregateMongoDocuments(ctx context.Context, req *dto.Aggrega
 		return nil, res.StatusErr()
 	}
 
-	data = []bson.M{}
-
-	err = json2.Unmarshal(res.ResByte(), &data)
+	err = bson.UnmarshalExtJSON(res.ResByte(), true, &data)
 
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -1472,6 +1870,114 @@ func (this *evApi) AggregateMongoDocuments(ctx context.Context, req *dto.Aggrega
 	return data, nil
 }
 
+// MongoFindCursor 打开MongoDB查询游标，返回第一批数据
+// 参数：
+//   - ctx: 上下文
+//   - req: 打开游标请求
+//
+// 返回：
+//   - res: 游标ID和第一批数据
+//   - err: 错误信息
+func (this *evApi) MongoFindCursor(ctx context.Context, req *dto.MongoFindCursorReq) (res *vo.MongoCursorRes, err error) {
+	res = &vo.MongoCursorRes{}
+	if err = this.requestExtJSON(ctx, "api/plugin_util/MongoFindCursor", req, res); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return res, nil
+}
+
+// MongoAggregateCursor 打开MongoDB聚合游标，返回第一批数据
+// 参数：
+//   - ctx: 上下文
+//   - req: 打开游标请求
+//
+// 返回：
+//   - res: 游标ID和第一批数据
+//   - err: 错误信息
+func (this *evApi) MongoAggregateCursor(ctx context.Context, req *dto.MongoAggregateCursorReq) (res *vo.MongoCursorRes, err error) {
+	res = &vo.MongoCursorRes{}
+	if err = this.requestExtJSON(ctx, "api/plugin_util/MongoAggregateCursor", req, res); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return res, nil
+}
+
+// MongoCursorGetMore 从MongoDB游标拉取下一批数据
+// 参数：
+//   - ctx: 上下文
+//   - req: 拉取请求
+//
+// 返回：
+//   - res: 本批数据
+//   - err: 错误信息
+func (this *evApi) MongoCursorGetMore(ctx context.Context, req *dto.MongoCursorGetMoreReq) (res *vo.MongoCursorRes, err error) {
+	res = &vo.MongoCursorRes{}
+	if err = this.requestExtJSON(ctx, "api/plugin_util/MongoCursorGetMore", req, res); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return res, nil
+}
+
+// MongoCursorKill 关闭MongoDB游标
+// 参数：
+//   - ctx: 上下文
+//   - req: 关闭游标请求
+//
+// 返回：
+//   - err: 错误信息
+func (this *evApi) MongoCursorKill(ctx context.Context, req *dto.MongoCursorKillReq) (err error) {
+	if err = this.requestExtJSON(ctx, "api/plugin_util/MongoCursorKill", req, nil); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// MongoWatchOpen 打开MongoDB变更流，返回第一批事件
+// 参数：
+//   - ctx: 上下文
+//   - req: 打开变更流请求
+//
+// 返回：
+//   - res: 变更流ID和第一批事件
+//   - err: 错误信息
+func (this *evApi) MongoWatchOpen(ctx context.Context, req *dto.MongoWatchReq) (res *vo.MongoChangeStreamRes, err error) {
+	res = &vo.MongoChangeStreamRes{}
+	if err = this.requestExtJSON(ctx, "api/plugin_util/MongoWatchOpen", req, res); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return res, nil
+}
+
+// MongoWatchNext 从MongoDB变更流拉取下一批事件，没有事件时最多等待MaxAwaitTimeMS后返回空批次
+// 参数：
+//   - ctx: 上下文
+//   - req: 拉取请求
+//
+// 返回：
+//   - res: 本批事件
+//   - err: 错误信息
+func (this *evApi) MongoWatchNext(ctx context.Context, req *dto.MongoWatchNextReq) (res *vo.MongoChangeStreamRes, err error) {
+	res = &vo.MongoChangeStreamRes{}
+	if err = this.requestExtJSON(ctx, "api/plugin_util/MongoWatchNext", req, res); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return res, nil
+}
+
+// MongoWatchClose 关闭MongoDB变更流
+// 参数：
+//   - ctx: 上下文
+//   - req: 关闭变更流请求
+//
+// 返回：
+//   - err: 错误信息
+func (this *evApi) MongoWatchClose(ctx context.Context, req *dto.MongoWatchCloseReq) (err error) {
+	if err = this.requestExtJSON(ctx, "api/plugin_util/MongoWatchClose", req, nil); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
 func (this *evApi) BatchInsertData(ctx context.Context, req *dto.BatchInsertDataReq) (err error) {
 	err = this.request(ctx, "api/plugin_util/BatchInsertData", req, &vo.ApiCommonRes{})
 	if err != nil {
@@ -1526,6 +2032,43 @@ func (this *evApi) request(ctx context.Context, api API, requestData interface{}
 	return nil
 }
 
+// requestUseNumber 发送HTTP请求的内部方法，响应中的数字解码为json.Number以避免大整数丢失精度
+// 参数：
+//   - ctx: 上下文
+//   - api: API路径
+//   - requestData: 请求数据
+//   - result: *vo.ApiCommonRes
+//
+// 返回：
+//   - error: 错误信息
+func (this *evApi) requestUseNumber(ctx context.Context, api API, requestData interface{}, result *vo.ApiCommonRes) error {
+	requestDataJSON, err := json2.Marshal(requestData)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	t1 := time.Now()
+	res, err := this.SendRequest(ctx, api, "POST", requestDataJSON)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if this.debug {
+		logger.DefaultLogger.Info("debug network",
+			"api", api,
+			"reqBody", string(requestDataJSON),
+			"resBody", string(res),
+			"lose time", api, time.Now().Sub(t1).String())
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(res))
+	dec.UseNumber()
+	if err = dec.Decode(result); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return result.Error()
+}
+
 // requestProtobuf 发送Protobuf格式HTTP请求的内部方法
 // 参数：
 //   - ctx: 上下文
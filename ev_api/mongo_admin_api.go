@@ -0,0 +1,300 @@
+// ev_api包提供EVE API的接口和实现
+//
+// mongo_admin_api.go 文件提供MongoDB索引和集合的管理方法，
+// 内部将类型化的选项转换为对应的数据库命令执行。
+package ev_api
+
+// 导入所需的包
+import (
+	// 上下文包
+	"context"
+	// 格式化包
+	"fmt"
+	// 字符串处理包
+	"strings"
+	// 时间处理包
+	"time"
+
+	// MongoDB BSON包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/bson"
+	// 数据传输对象包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/dto"
+	// 视图对象包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/vo"
+	// 错误处理包
+	"github.com/pkg/errors"
+)
+
+// MongoCommandTimeout MongoDB管理命令的超时时间
+var MongoCommandTimeout = 60 * time.Second
+
+// mongoRunCommand 执行MongoDB命令，命令返回ok为0时转换为错误
+func (this *EvApiAdapter) mongoRunCommand(ctx context.Context, dbName string, command bson.D) (bson.D, error) {
+	res, err := GetEvApi().ExecMongoCommandOrdered(ctx, &dto.MongoExecReq{
+		EsConnectData: this.buildEsConnectData(),
+		DbName:        dbName,
+		Command:       command,
+		Timeout:       MongoCommandTimeout,
+	})
+	if err != nil {
+		return nil, err
+	}
+	m := res.Map()
+	if isMongoZero(m["ok"]) {
+		return nil, errors.Errorf("执行%s失败: %v", command[0].Key, m["errmsg"])
+	}
+	return res, nil
+}
+
+// isMongoZero 判断命令结果中的ok字段是否为0
+func isMongoZero(v interface{}) bool {
+	switch n := v.(type) {
+	case int32:
+		return n == 0
+	case int64:
+		return n == 0
+	case float64:
+		return n == 0
+	case bool:
+		return !n
+	}
+	return false
+}
+
+// MongoIndexName 按官方驱动的规则生成索引名称，例如 {a:1,b:-1} => a_1_b_-1
+// 参数：
+//   - keys: 索引键
+//
+// 返回：
+//   - string: 索引名称
+func MongoIndexName(keys bson.D) string {
+	parts := make([]string, 0, len(keys)*2)
+	for _, e := range keys {
+		parts = append(parts, e.Key, fmt.Sprint(e.Value))
+	}
+	return strings.Join(parts, "_")
+}
+
+// indexSpec 将索引定义转换为createIndexes命令中的索引文档
+func indexSpec(model dto.MongoIndexModel) (bson.D, error) {
+	if len(model.Keys) == 0 {
+		return nil, errors.New("索引键不能为空")
+	}
+	name := model.Name
+	if name == "" {
+		name = MongoIndexName(model.Keys)
+	}
+	spec := bson.D{{Key: "key", Value: model.Keys}, {Key: "name", Value: name}}
+	if model.Unique {
+		spec = append(spec, bson.E{Key: "unique", Value: true})
+	}
+	if model.Sparse {
+		spec = append(spec, bson.E{Key: "sparse", Value: true})
+	}
+	if model.Hidden {
+		spec = append(spec, bson.E{Key: "hidden", Value: true})
+	}
+	if model.ExpireAfterSeconds != nil {
+		spec = append(spec, bson.E{Key: "expireAfterSeconds", Value: *model.ExpireAfterSeconds})
+	}
+	if model.PartialFilterExpression != nil {
+		spec = append(spec, bson.E{Key: "partialFilterExpression", Value: model.PartialFilterExpression})
+	}
+	if model.Weights != nil {
+		spec = append(spec, bson.E{Key: "weights", Value: model.Weights})
+	}
+	if model.DefaultLanguage != "" {
+		spec = append(spec, bson.E{Key: "default_language", Value: model.DefaultLanguage})
+	}
+	if model.LanguageOverride != "" {
+		spec = append(spec, bson.E{Key: "language_override", Value: model.LanguageOverride})
+	}
+	if model.SphereVersion > 0 {
+		spec = append(spec, bson.E{Key: "2dsphereIndexVersion", Value: model.SphereVersion})
+	}
+	if model.Collation != nil {
+		spec = append(spec, bson.E{Key: "collation", Value: model.Collation})
+	}
+	return spec, nil
+}
+
+// MongoListIndexes 获取集合的索引列表
+// 参数：
+//   - ctx: 上下文
+//   - dbName: 数据库名称
+//   - collectionName: 集合名称
+//
+// 返回：
+//   - indexes: 索引列表
+//   - err: 错误信息
+func (this *EvApiAdapter) MongoListIndexes(ctx context.Context, dbName, collectionName string) (indexes []*vo.MongoIndex, err error) {
+	res, err := this.mongoRunCommand(ctx, dbName, bson.D{{Key: "listIndexes", Value: collectionName}})
+	if err != nil {
+		return nil, err
+	}
+	var result struct {
+		Cursor struct {
+			FirstBatch []*vo.MongoIndex `bson:"firstBatch"`
+		} `bson:"cursor"`
+	}
+	if err = bson.Unmarshal(res, &result); err != nil {
+		return nil, err
+	}
+	return result.Cursor.FirstBatch, nil
+}
+
+// MongoCreateIndexes 创建索引，支持唯一、TTL、部分、文本和2dsphere等索引
+// 参数：
+//   - ctx: 上下文
+//   - dbName: 数据库名称
+//   - collectionName: 集合名称
+//   - models: 索引定义
+//
+// 返回：
+//   - names: 创建的索引名称
+//   - err: 错误信息
+func (this *EvApiAdapter) MongoCreateIndexes(ctx context.Context, dbName, collectionName string, models ...dto.MongoIndexModel) (names []string, err error) {
+	if len(models) == 0 {
+		return nil, nil
+	}
+	specs := make(bson.A, 0, len(models))
+	names = make([]string, 0, len(models))
+	for i, model := range models {
+		spec, err := indexSpec(model)
+		if err != nil {
+			return nil, errors.Wrapf(err, "第%d个索引", i)
+		}
+		specs = append(specs, spec)
+		names = append(names, spec.Map()["name"].(string))
+	}
+	_, err = this.mongoRunCommand(ctx, dbName, bson.D{
+		{Key: "createIndexes", Value: collectionName},
+		{Key: "indexes", Value: specs},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+// MongoDropIndex 删除索引
+// 参数：
+//   - ctx: 上下文
+//   - dbName: 数据库名称
+//   - collectionName: 集合名称
+//   - indexName: 索引名称，为 * 时删除_id以外的全部索引
+//
+// 返回：
+//   - err: 错误信息
+func (this *EvApiAdapter) MongoDropIndex(ctx context.Context, dbName, collectionName, indexName string) error {
+	_, err := this.mongoRunCommand(ctx, dbName, bson.D{
+		{Key: "dropIndexes", Value: collectionName},
+		{Key: "index", Value: indexName},
+	})
+	return err
+}
+
+// MongoCreateCollection 创建集合，支持固定集合、文档校验和时间序列集合
+// 参数：
+//   - ctx: 上下文
+//   - dbName: 数据库名称
+//   - collectionName: 集合名称
+//   - opts: 创建选项
+//
+// 返回：
+//   - err: 错误信息
+func (this *EvApiAdapter) MongoCreateCollection(ctx context.Context, dbName, collectionName string, opts dto.MongoCreateCollectionOptions) error {
+	command := bson.D{{Key: "create", Value: collectionName}}
+	if opts.Capped {
+		if opts.SizeInBytes <= 0 {
+			return errors.New("固定集合必须指定SizeInBytes")
+		}
+		command = append(command, bson.E{Key: "capped", Value: true}, bson.E{Key: "size", Value: opts.SizeInBytes})
+		if opts.MaxDocuments > 0 {
+			command = append(command, bson.E{Key: "max", Value: opts.MaxDocuments})
+		}
+	}
+	if opts.Validator != nil {
+		command = append(command, bson.E{Key: "validator", Value: opts.Validator})
+	}
+	if opts.ValidationLevel != "" {
+		command = append(command, bson.E{Key: "validationLevel", Value: opts.ValidationLevel})
+	}
+	if opts.ValidationAction != "" {
+		command = append(command, bson.E{Key: "validationAction", Value: opts.ValidationAction})
+	}
+	if opts.TimeSeries != nil {
+		if opts.TimeSeries.TimeField == "" {
+			return errors.New("时间序列集合必须指定TimeField")
+		}
+		ts := bson.D{{Key: "timeField", Value: opts.TimeSeries.TimeField}}
+		if opts.TimeSeries.MetaField != "" {
+			ts = append(ts, bson.E{Key: "metaField", Value: opts.TimeSeries.MetaField})
+		}
+		if opts.TimeSeries.Granularity != "" {
+			ts = append(ts, bson.E{Key: "granularity", Value: opts.TimeSeries.Granularity})
+		}
+		command = append(command, bson.E{Key: "timeseries", Value: ts})
+	}
+	if opts.ExpireAfterSeconds != nil {
+		command = append(command, bson.E{Key: "expireAfterSeconds", Value: *opts.ExpireAfterSeconds})
+	}
+	if opts.Collation != nil {
+		command = append(command, bson.E{Key: "collation", Value: opts.Collation})
+	}
+	_, err := this.mongoRunCommand(ctx, dbName, command)
+	return err
+}
+
+// MongoDropCollection 删除集合
+// 参数：
+//   - ctx: 上下文
+//   - dbName: 数据库名称
+//   - collectionName: 集合名称
+//
+// 返回：
+//   - err: 错误信息
+func (this *EvApiAdapter) MongoDropCollection(ctx context.Context, dbName, collectionName string) error {
+	_, err := this.mongoRunCommand(ctx, dbName, bson.D{{Key: "drop", Value: collectionName}})
+	return err
+}
+
+// MongoRenameCollection 重命名集合，命令在admin库上执行
+// 参数：
+//   - ctx: 上下文
+//   - dbName: 数据库名称
+//   - collectionName: 原集合名称
+//   - newName: 新集合名称
+//   - dropTarget: 新集合已存在时是否先删除
+//
+// 返回：
+//   - err: 错误信息
+func (this *EvApiAdapter) MongoRenameCollection(ctx context.Context, dbName, collectionName, newName string, dropTarget bool) error {
+	_, err := this.mongoRunCommand(ctx, "admin", bson.D{
+		{Key: "renameCollection", Value: dbName + "." + collectionName},
+		{Key: "to", Value: dbName + "." + newName},
+		{Key: "dropTarget", Value: dropTarget},
+	})
+	return err
+}
+
+// MongoCollStats 获取集合的统计信息
+// 参数：
+//   - ctx: 上下文
+//   - dbName: 数据库名称
+//   - collectionName: 集合名称
+//
+// 返回：
+//   - stats: 统计信息
+//   - err: 错误信息
+func (this *EvApiAdapter) MongoCollStats(ctx context.Context, dbName, collectionName string) (stats *vo.MongoCollStats, err error) {
+	res, err := this.mongoRunCommand(ctx, dbName, bson.D{{Key: "collStats", Value: collectionName}})
+	if err != nil {
+		return nil, err
+	}
+	stats = &vo.MongoCollStats{}
+	if err = bson.Unmarshal(res, stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
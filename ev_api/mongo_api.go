@@ -0,0 +1,152 @@
+// ev_api包提供EVE API的接口和实现
+//
+// mongo_api.go 文件提供基于结构体的MongoDB泛型方法，
+// 结构体与文档之间按bson标签转换，规则见bson.Marshal和bson.Unmarshal。
+package ev_api
+
+// 导入所需的包
+import (
+	// 上下文包
+	"context"
+
+	// MongoDB BSON包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/bson"
+	// 错误处理包
+	"github.com/pkg/errors"
+)
+
+// ErrNoDocuments 查询结果中没有文档
+var ErrNoDocuments = errors.New("mongo: no documents in result")
+
+// toBsonM 将过滤条件、更新文档等转换为bson.M，支持bson.M、bson.D、map和带bson标签的结构体
+func toBsonM(v interface{}) (bson.M, error) {
+	switch val := v.(type) {
+	case nil:
+		return nil, nil
+	case bson.M:
+		return val, nil
+	}
+	return bson.MarshalM(v)
+}
+
+// decodeDocs 将文档列表解码为[]T
+func decodeDocs[T any](docs []bson.M) ([]T, error) {
+	res := make([]T, len(docs))
+	for i, doc := range docs {
+		if err := bson.Unmarshal(doc, &res[i]); err != nil {
+			return nil, errors.Wrapf(err, "解码第%d个文档失败", i)
+		}
+	}
+	return res, nil
+}
+
+// MongoFind 查询MongoDB文档并解码为[]T
+// 参数：
+//   - ctx: 上下文
+//   - api: 数据源适配器
+//   - dbName: 数据库名称
+//   - collectionName: 集合名称
+//   - filter: 过滤条件，可以是bson.M、bson.D或带bson标签的结构体
+//   - sort: 排序条件
+//   - skip: 跳过的文档数
+//   - limit: 返回的最大文档数
+//
+// 返回：
+//   - []T: 查询结果
+//   - error: 错误信息
+func MongoFind[T any](ctx context.Context, api *EvApiAdapter, dbName, collectionName string, filter interface{}, sort bson.D, skip, limit int64) ([]T, error) {
+	filterM, err := toBsonM(filter)
+	if err != nil {
+		return nil, err
+	}
+	docs, err := api.MongoFindDocuments(ctx, dbName, collectionName, nil, filterM, sort, skip, limit)
+	if err != nil {
+		return nil, err
+	}
+	return decodeDocs[T](docs)
+}
+
+// MongoFindOne 查询第一条MongoDB文档并解码为T，没有文档时返回ErrNoDocuments
+// 参数：
+//   - ctx: 上下文
+//   - api: 数据源适配器
+//   - dbName: 数据库名称
+//   - collectionName: 集合名称
+//   - filter: 过滤条件，可以是bson.M、bson.D或带bson标签的结构体
+//   - sort: 排序条件
+//
+// 返回：
+//   - T: 查询结果
+//   - error: 错误信息
+func MongoFindOne[T any](ctx context.Context, api *EvApiAdapter, dbName, collectionName string, filter interface{}, sort bson.D) (T, error) {
+	var res T
+	docs, err := MongoFind[T](ctx, api, dbName, collectionName, filter, sort, 0, 1)
+	if err != nil {
+		return res, err
+	}
+	if len(docs) == 0 {
+		return res, ErrNoDocuments
+	}
+	return docs[0], nil
+}
+
+// MongoAggregate 执行MongoDB聚合管道并将结果解码为[]T
+// 参数：
+//   - ctx: 上下文
+//   - api: 数据源适配器
+//   - dbName: 数据库名称
+//   - collectionName: 集合名称
+//   - pipeline: 聚合管道
+//
+// 返回：
+//   - []T: 聚合结果
+//   - error: 错误信息
+func MongoAggregate[T any](ctx context.Context, api *EvApiAdapter, dbName, collectionName string, pipeline bson.Pipeline) ([]T, error) {
+	docs, err := api.MongoAggregateDocuments(ctx, dbName, collectionName, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	return decodeDocs[T](docs)
+}
+
+// MongoInsertOne 将结构体编码为文档后插入MongoDB
+// 参数：
+//   - ctx: 上下文
+//   - api: 数据源适配器
+//   - dbName: 数据库名称
+//   - collectionName: 集合名称
+//   - doc: 要插入的文档
+//
+// 返回：
+//   - string: 插入后的文档ID
+//   - error: 错误信息
+func MongoInsertOne[T any](ctx context.Context, api *EvApiAdapter, dbName, collectionName string, doc T) (string, error) {
+	m, err := bson.MarshalM(doc)
+	if err != nil {
+		return "", err
+	}
+	return api.MongoInsertDocument(ctx, dbName, collectionName, m)
+}
+
+// MongoInsertMany 将结构体列表编码为文档后批量插入MongoDB
+// 参数：
+//   - ctx: 上下文
+//   - api: 数据源适配器
+//   - dbName: 数据库名称
+//   - collectionName: 集合名称
+//   - docs: 要插入的文档列表
+//
+// 返回：
+//   - []string: 插入后的文档ID列表
+//   - error: 错误信息
+func MongoInsertMany[T any](ctx context.Context, api *EvApiAdapter, dbName, collectionName string, docs []T) ([]string, error) {
+	ms := make([]bson.M, 0, len(docs))
+	for i, doc := range docs {
+		m, err := bson.MarshalM(doc)
+		if err != nil {
+			return nil, errors.Wrapf(err, "编码第%d个文档失败", i)
+		}
+		ms = append(ms, m)
+	}
+	return api.MongoInsertManyDocuments(ctx, dbName, collectionName, ms)
+}
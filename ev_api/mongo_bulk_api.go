@@ -0,0 +1,355 @@
+// ev_api包提供EVE API的接口和实现
+//
+// mongo_bulk_api.go 文件提供MongoDB批量写入方法，
+// 写入模型按操作类型分批转换为insert、update和delete命令执行。
+package ev_api
+
+// 导入所需的包
+import (
+	// 上下文包
+	"context"
+	// 格式化包
+	"fmt"
+	// 排序包
+	"sort"
+	// 字符串处理包
+	"strings"
+
+	// MongoDB BSON包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/bson"
+	// 数据传输对象包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/dto"
+	// MongoDB基础类型包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/primitive"
+	// 视图对象包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/vo"
+	// 错误处理包
+	"github.com/pkg/errors"
+)
+
+// MongoBulkWriteBatchSize 批量写入时单个命令包含的最大操作数
+var MongoBulkWriteBatchSize = 1000
+
+// MongoBulkWriteException 批量写入存在写入错误或写关注错误
+type MongoBulkWriteException struct {
+	// 各操作的写入错误
+	WriteErrors []vo.MongoWriteError
+	// 写关注错误
+	WriteConcernErrors []vo.MongoWriteConcernError
+}
+
+// Error 实现error接口
+func (this *MongoBulkWriteException) Error() string {
+	msgs := make([]string, 0, len(this.WriteErrors)+len(this.WriteConcernErrors))
+	for _, e := range this.WriteErrors {
+		msgs = append(msgs, fmt.Sprintf("第%d个操作: (%d) %s", e.Index, e.Code, e.Message))
+	}
+	for _, e := range this.WriteConcernErrors {
+		msgs = append(msgs, fmt.Sprintf("写关注: (%d) %s", e.Code, e.Message))
+	}
+	return "批量写入失败: " + strings.Join(msgs, "; ")
+}
+
+// bulkBatch 同一命令中执行的一批操作
+type bulkBatch struct {
+	// 命令名称：insert、update、delete
+	command string
+	// 命令中的文档，insert为待插入文档，update和delete为语句
+	docs bson.A
+	// 每个文档对应的写入模型下标
+	indexes []int
+	// insert命令中每个文档的_id
+	ids []interface{}
+}
+
+// bulkDocsField 命令名称 => 命令中存放文档的字段名
+var bulkDocsField = map[string]string{
+	"insert": "documents",
+	"update": "updates",
+	"delete": "deletes",
+}
+
+// bulkCommandRes insert、update、delete命令的返回结果
+type bulkCommandRes struct {
+	// 受影响的文档数，update命令中包含upsert插入的文档
+	N int64 `bson:"n"`
+	// 修改的文档数
+	NModified int64 `bson:"nModified"`
+	// upsert插入的文档
+	Upserted []struct {
+		// 批次内下标
+		Index int `bson:"index"`
+		// 文档ID
+		Id interface{} `bson:"_id"`
+	} `bson:"upserted"`
+	// 写入错误，下标为批次内下标
+	WriteErrors []vo.MongoWriteError `bson:"writeErrors"`
+	// 写关注错误
+	WriteConcernError *vo.MongoWriteConcernError `bson:"writeConcernError"`
+}
+
+// commandOf 返回写入操作对应的命令名称
+func commandOf(op dto.MongoWriteOp) string {
+	switch op {
+	case dto.MongoInsertOne:
+		return "insert"
+	case dto.MongoUpdateOne, dto.MongoUpdateMany, dto.MongoReplaceOne:
+		return "update"
+	case dto.MongoDeleteOne, dto.MongoDeleteMany:
+		return "delete"
+	}
+	return ""
+}
+
+// firstKey 返回更新文档的第一个键，用于区分更新操作符文档与替换文档
+func firstKey(v interface{}) (string, bool) {
+	switch doc := v.(type) {
+	case bson.D:
+		if len(doc) > 0 {
+			return doc[0].Key, true
+		}
+	case bson.M:
+		for k := range doc {
+			return k, true
+		}
+	}
+	return "", false
+}
+
+// writeStatement 将写入模型转换为命令中的文档，insert返回文档及其_id
+func writeStatement(model dto.MongoWriteModel) (stmt interface{}, id interface{}, err error) {
+	switch model.Op {
+	case dto.MongoInsertOne:
+		if model.Document == nil {
+			return nil, nil, errors.New("insertOne缺少Document")
+		}
+		id, ok := model.Document["_id"]
+		if !ok {
+			// 与官方驱动一致在客户端生成_id，同时避免修改调用方的文档
+			id = primitive.NewObjectID()
+		}
+		doc := make(bson.D, 0, len(model.Document)+1)
+		doc = append(doc, bson.E{Key: "_id", Value: id})
+		keys := make([]string, 0, len(model.Document))
+		for k := range model.Document {
+			if k != "_id" {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			doc = append(doc, bson.E{Key: k, Value: model.Document[k]})
+		}
+		return doc, id, nil
+	case dto.MongoUpdateOne, dto.MongoUpdateMany, dto.MongoReplaceOne:
+		update := model.Update
+		if model.Op == dto.MongoReplaceOne {
+			if model.Replacement == nil {
+				return nil, nil, errors.New("replaceOne缺少Replacement")
+			}
+			if k, ok := firstKey(model.Replacement); ok && strings.HasPrefix(k, "$") {
+				return nil, nil, errors.New("replaceOne的Replacement不能包含更新操作符")
+			}
+			update = model.Replacement
+		} else {
+			switch u := update.(type) {
+			case nil:
+				return nil, nil, errors.Errorf("%s缺少Update", model.Op)
+			case bson.Pipeline, []bson.D, []bson.M, bson.A:
+			default:
+				if k, ok := firstKey(u); !ok || !strings.HasPrefix(k, "$") {
+					return nil, nil, errors.Errorf("%s的Update必须是更新操作符文档或聚合管道", model.Op)
+				}
+			}
+		}
+		stmt := bson.D{
+			{Key: "q", Value: filterOrEmpty(model.Filter)},
+			{Key: "u", Value: update},
+			{Key: "multi", Value: model.Op == dto.MongoUpdateMany},
+			{Key: "upsert", Value: model.Upsert},
+		}
+		if len(model.ArrayFilters) > 0 {
+			if model.Op == dto.MongoReplaceOne {
+				return nil, nil, errors.New("replaceOne不支持ArrayFilters")
+			}
+			stmt = append(stmt, bson.E{Key: "arrayFilters", Value: model.ArrayFilters})
+		}
+		if model.Collation != nil {
+			stmt = append(stmt, bson.E{Key: "collation", Value: model.Collation})
+		}
+		if model.Hint != nil {
+			stmt = append(stmt, bson.E{Key: "hint", Value: model.Hint})
+		}
+		return stmt, nil, nil
+	case dto.MongoDeleteOne, dto.MongoDeleteMany:
+		limit := int32(0)
+		if model.Op == dto.MongoDeleteOne {
+			limit = 1
+		}
+		stmt := bson.D{
+			{Key: "q", Value: filterOrEmpty(model.Filter)},
+			{Key: "limit", Value: limit},
+		}
+		if model.Collation != nil {
+			stmt = append(stmt, bson.E{Key: "collation", Value: model.Collation})
+		}
+		if model.Hint != nil {
+			stmt = append(stmt, bson.E{Key: "hint", Value: model.Hint})
+		}
+		return stmt, nil, nil
+	}
+	return nil, nil, errors.Errorf("未知的写入操作类型: %q", model.Op)
+}
+
+// filterOrEmpty 过滤条件为nil时返回空文档
+func filterOrEmpty(filter bson.M) bson.M {
+	if filter == nil {
+		return bson.M{}
+	}
+	return filter
+}
+
+// splitBulkBatches 将写入模型分批：有序执行时按相邻的同类操作分批以保持顺序，
+// 无序执行时同类操作合并后按insert、update、delete的顺序分批
+func splitBulkBatches(models []dto.MongoWriteModel, ordered bool) ([]*bulkBatch, error) {
+	var batches []*bulkBatch
+	open := map[string]*bulkBatch{}
+	var last *bulkBatch
+	for i, model := range models {
+		stmt, id, err := writeStatement(model)
+		if err != nil {
+			return nil, errors.Wrapf(err, "第%d个操作", i)
+		}
+		command := commandOf(model.Op)
+		var batch *bulkBatch
+		if ordered {
+			batch = last
+		} else {
+			batch = open[command]
+		}
+		if batch == nil || batch.command != command || len(batch.docs) >= MongoBulkWriteBatchSize {
+			batch = &bulkBatch{command: command}
+			batches = append(batches, batch)
+			open[command] = batch
+			last = batch
+		}
+		batch.docs = append(batch.docs, stmt)
+		batch.indexes = append(batch.indexes, i)
+		batch.ids = append(batch.ids, id)
+	}
+	if !ordered {
+		order := map[string]int{"insert": 0, "update": 1, "delete": 2}
+		sort.SliceStable(batches, func(i, j int) bool {
+			return order[batches[i].command] < order[batches[j].command]
+		})
+	}
+	return batches, nil
+}
+
+// MongoBulkWrite 批量执行插入、更新、替换和删除操作
+// 有序执行时遇到第一个写入错误即停止，无序执行时跳过出错的操作继续执行。
+// 存在写入错误或写关注错误时，返回已执行部分的结果及*MongoBulkWriteException
+// 参数：
+//   - ctx: 上下文
+//   - dbName: 数据库名称
+//   - collectionName: 集合名称
+//   - models: 写入模型列表
+//   - opts: 批量写入选项
+//
+// 返回：
+//   - result: 批量写入结果
+//   - err: 错误信息
+func (this *EvApiAdapter) MongoBulkWrite(ctx context.Context, dbName, collectionName string, models []dto.MongoWriteModel, opts dto.MongoBulkWriteOptions) (result *vo.MongoBulkWriteResult, err error) {
+	if len(models) == 0 {
+		return nil, errors.New("写入模型不能为空")
+	}
+	ordered := !opts.Unordered
+	batches, err := splitBulkBatches(models, ordered)
+	if err != nil {
+		return nil, err
+	}
+
+	result = &vo.MongoBulkWriteResult{
+		InsertedIds: map[int]interface{}{},
+		UpsertedIds: map[int]interface{}{},
+	}
+	for _, batch := range batches {
+		command := bson.D{
+			{Key: batch.command, Value: collectionName},
+			{Key: bulkDocsField[batch.command], Value: batch.docs},
+			{Key: "ordered", Value: ordered},
+		}
+		if opts.BypassDocumentValidation && batch.command != "delete" {
+			command = append(command, bson.E{Key: "bypassDocumentValidation", Value: true})
+		}
+		res, err := this.mongoRunCommand(ctx, dbName, command)
+		if err != nil {
+			return result, errors.Wrapf(err, "第%d个操作所在批次", batch.indexes[0])
+		}
+		var cmdRes bulkCommandRes
+		if err = bson.Unmarshal(res, &cmdRes); err != nil {
+			return result, err
+		}
+		if err = mergeBulkResult(result, batch, &cmdRes, ordered); err != nil {
+			return result, err
+		}
+		if ordered && len(cmdRes.WriteErrors) > 0 {
+			break
+		}
+	}
+
+	sort.Slice(result.WriteErrors, func(i, j int) bool {
+		return result.WriteErrors[i].Index < result.WriteErrors[j].Index
+	})
+	if result.HasErrors() {
+		return result, &MongoBulkWriteException{
+			WriteErrors:        result.WriteErrors,
+			WriteConcernErrors: result.WriteConcernErrors,
+		}
+	}
+	return result, nil
+}
+
+// mergeBulkResult 将单个批次的命令结果合并到批量写入结果，批次内下标转换为写入模型下标
+func mergeBulkResult(result *vo.MongoBulkWriteResult, batch *bulkBatch, res *bulkCommandRes, ordered bool) error {
+	failed := map[int]bool{}
+	stopAt := len(batch.docs)
+	for _, we := range res.WriteErrors {
+		if we.Index < 0 || we.Index >= len(batch.indexes) {
+			return errors.Errorf("写入错误的下标越界: %d", we.Index)
+		}
+		failed[we.Index] = true
+		if ordered && we.Index < stopAt {
+			stopAt = we.Index
+		}
+		we.Index = batch.indexes[we.Index]
+		result.WriteErrors = append(result.WriteErrors, we)
+	}
+	if res.WriteConcernError != nil {
+		result.WriteConcernErrors = append(result.WriteConcernErrors, *res.WriteConcernError)
+	}
+
+	switch batch.command {
+	case "insert":
+		result.InsertedCount += res.N
+		for i := 0; i < stopAt; i++ {
+			if failed[i] {
+				continue
+			}
+			result.InsertedIds[batch.indexes[i]] = batch.ids[i]
+		}
+	case "update":
+		result.MatchedCount += res.N - int64(len(res.Upserted))
+		result.ModifiedCount += res.NModified
+		result.UpsertedCount += int64(len(res.Upserted))
+		for _, up := range res.Upserted {
+			if up.Index < 0 || up.Index >= len(batch.indexes) {
+				return errors.Errorf("upsert结果的下标越界: %d", up.Index)
+			}
+			result.UpsertedIds[batch.indexes[up.Index]] = up.Id
+		}
+	case "delete":
+		result.DeletedCount += res.N
+	}
+	return nil
+}
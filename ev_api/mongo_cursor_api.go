@@ -0,0 +1,463 @@
+// ev_api包提供EVE API的接口和实现
+//
+// mongo_cursor_api.go 文件提供MongoDB服务端游标和基于排序键的分页，
+// 用于流式读取大集合和大聚合结果，避免skip带来的深分页性能问题。
+package ev_api
+
+// 导入所需的包
+import (
+	// 上下文包
+	"context"
+	// Base64编码包
+	"encoding/base64"
+	// IO包
+	"io"
+	// 字符串处理包
+	"strings"
+	// 并发控制包
+	"sync"
+	// 时间处理包
+	"time"
+
+	// MongoDB BSON包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/bson"
+	// 数据传输对象包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/dto"
+	// 视图对象包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/vo"
+	// 错误处理包
+	"github.com/pkg/errors"
+)
+
+// MongoCursorIdleTimeout MongoDB游标的空闲超时时间，超时未拉取时基座自动关闭游标
+var MongoCursorIdleTimeout = 60 * time.Second
+
+// MongoCursorBatchSize MongoDB游标默认每批拉取的文档数
+const MongoCursorBatchSize = 1000
+
+// MongoFindOptions MongoDB查询游标选项
+type MongoFindOptions struct {
+	// 投影
+	Projection bson.M
+	// 排序条件
+	Sort bson.D
+	// 跳过的文档数
+	Skip int64
+	// 返回的最大文档数，0表示不限制
+	Limit int64
+	// 每批拉取的文档数，小于等于0时使用MongoCursorBatchSize
+	BatchSize int32
+}
+
+// MongoAggregateOptions MongoDB聚合游标选项
+type MongoAggregateOptions struct {
+	// 每批拉取的文档数，小于等于0时使用MongoCursorBatchSize
+	BatchSize int32
+	// 是否允许使用磁盘临时文件，用于超出内存限制的大聚合
+	AllowDiskUse bool
+}
+
+// MongoCursor MongoDB服务端游标，由基座按游标ID保持打开，通过getMore分批读取
+type MongoCursor struct {
+	connectData dto.EsConnectData
+	cursorId    string
+	batchSize   int32
+	firstBatch  []bson.M
+	lock        sync.Mutex
+	done        bool
+	closed      bool
+}
+
+// batchSizeOrDefault 返回有效的批大小
+func batchSizeOrDefault(batchSize int32) int32 {
+	if batchSize <= 0 {
+		return MongoCursorBatchSize
+	}
+	return batchSize
+}
+
+// newMongoCursor 根据打开游标的结果创建游标
+func newMongoCursor(connectData dto.EsConnectData, batchSize int32, res *vo.MongoCursorRes) *MongoCursor {
+	return &MongoCursor{
+		connectData: connectData,
+		cursorId:    res.CursorId,
+		batchSize:   batchSize,
+		firstBatch:  res.Batch,
+		done:        res.Done,
+		closed:      res.Done,
+	}
+}
+
+// MongoOpenFindCursor 打开MongoDB查询游标
+// 使用完毕后必须调用Close，结果集读完时基座会自动关闭游标
+// 参数：
+//   - ctx: 上下文
+//   - dbName: 数据库名称
+//   - collectionName: 集合名称
+//   - filter: 过滤条件，可以是bson.M、bson.D或带bson标签的结构体
+//   - opts: 查询选项
+//
+// 返回：
+//   - *MongoCursor: 游标
+//   - err: 错误信息
+func (this *EvApiAdapter) MongoOpenFindCursor(ctx context.Context, dbName, collectionName string, filter interface{}, opts MongoFindOptions) (*MongoCursor, error) {
+	filterM, err := toBsonM(filter)
+	if err != nil {
+		return nil, err
+	}
+	connectData := this.buildEsConnectData()
+	batchSize := batchSizeOrDefault(opts.BatchSize)
+	res, err := GetEvApi().MongoFindCursor(ctx, &dto.MongoFindCursorReq{
+		EsConnectData:  connectData,
+		DbName:         dbName,
+		CollectionName: collectionName,
+		Filter:         filterM,
+		Projection:     opts.Projection,
+		Sort:           opts.Sort,
+		Skip:           opts.Skip,
+		Limit:          opts.Limit,
+		BatchSize:      batchSize,
+		IdleTimeout:    int(MongoCursorIdleTimeout.Seconds()),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newMongoCursor(connectData, batchSize, res), nil
+}
+
+// MongoOpenAggregateCursor 打开MongoDB聚合游标
+// 使用完毕后必须调用Close，结果集读完时基座会自动关闭游标
+// 参数：
+//   - ctx: 上下文
+//   - dbName: 数据库名称
+//   - collectionName: 集合名称
+//   - pipeline: 聚合管道
+//   - opts: 聚合选项
+//
+// 返回：
+//   - *MongoCursor: 游标
+//   - err: 错误信息
+func (this *EvApiAdapter) MongoOpenAggregateCursor(ctx context.Context, dbName, collectionName string, pipeline bson.Pipeline, opts MongoAggregateOptions) (*MongoCursor, error) {
+	connectData := this.buildEsConnectData()
+	batchSize := batchSizeOrDefault(opts.BatchSize)
+	res, err := GetEvApi().MongoAggregateCursor(ctx, &dto.MongoAggregateCursorReq{
+		EsConnectData:  connectData,
+		DbName:         dbName,
+		CollectionName: collectionName,
+		Pipeline:       pipeline,
+		BatchSize:      batchSize,
+		AllowDiskUse:   opts.AllowDiskUse,
+		IdleTimeout:    int(MongoCursorIdleTimeout.Seconds()),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newMongoCursor(connectData, batchSize, res), nil
+}
+
+// MongoIterDocuments 以迭代器的方式逐条读取查询结果，内部通过服务端游标分批拉取
+// 迭代结束、调用方提前退出或ctx被取消时自动关闭游标，出错时以err返回后结束迭代
+// Go 1.23及以上版本可直接使用 for doc, err := range api.MongoIterDocuments(...)
+// 参数：
+//   - ctx: 上下文
+//   - dbName: 数据库名称
+//   - collectionName: 集合名称
+//   - filter: 过滤条件
+//   - opts: 查询选项
+//
+// 返回：
+//   - 迭代器函数
+func (this *EvApiAdapter) MongoIterDocuments(ctx context.Context, dbName, collectionName string, filter interface{}, opts MongoFindOptions) func(yield func(doc bson.M, err error) bool) {
+	return iterMongoCursor(ctx, func() (*MongoCursor, error) {
+		return this.MongoOpenFindCursor(ctx, dbName, collectionName, filter, opts)
+	})
+}
+
+// MongoIterAggregate 以迭代器的方式逐条读取聚合结果，规则同MongoIterDocuments
+// 参数：
+//   - ctx: 上下文
+//   - dbName: 数据库名称
+//   - collectionName: 集合名称
+//   - pipeline: 聚合管道
+//   - opts: 聚合选项
+//
+// 返回：
+//   - 迭代器函数
+func (this *EvApiAdapter) MongoIterAggregate(ctx context.Context, dbName, collectionName string, pipeline bson.Pipeline, opts MongoAggregateOptions) func(yield func(doc bson.M, err error) bool) {
+	return iterMongoCursor(ctx, func() (*MongoCursor, error) {
+		return this.MongoOpenAggregateCursor(ctx, dbName, collectionName, pipeline, opts)
+	})
+}
+
+// MongoIter 以迭代器的方式逐条读取查询结果并解码为T，规则同MongoIterDocuments
+// 参数：
+//   - ctx: 上下文
+//   - api: 数据源适配器
+//   - dbName: 数据库名称
+//   - collectionName: 集合名称
+//   - filter: 过滤条件
+//   - opts: 查询选项
+//
+// 返回：
+//   - 迭代器函数
+func MongoIter[T any](ctx context.Context, api *EvApiAdapter, dbName, collectionName string, filter interface{}, opts MongoFindOptions) func(yield func(doc T, err error) bool) {
+	return func(yield func(doc T, err error) bool) {
+		api.MongoIterDocuments(ctx, dbName, collectionName, filter, opts)(func(doc bson.M, err error) bool {
+			var v T
+			if err == nil {
+				err = bson.Unmarshal(doc, &v)
+			}
+			return yield(v, err) && err == nil
+		})
+	}
+}
+
+// iterMongoCursor 打开游标并逐条迭代
+func iterMongoCursor(ctx context.Context, open func() (*MongoCursor, error)) func(yield func(doc bson.M, err error) bool) {
+	return func(yield func(doc bson.M, err error) bool) {
+		cursor, err := open()
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		defer cursor.Close(context.Background())
+
+		for {
+			docs, err := cursor.Next(ctx)
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for _, doc := range docs {
+				if !yield(doc, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// CursorId 返回游标ID
+func (this *MongoCursor) CursorId() string {
+	return this.cursorId
+}
+
+// Next 拉取下一批文档，第一次调用返回打开游标时的第一批数据，结果集读完后返回io.EOF
+// 参数：
+//   - ctx: 上下文，被取消时返回ctx.Err()
+//
+// 返回：
+//   - docs: 本批文档
+//   - err: 错误信息
+func (this *MongoCursor) Next(ctx context.Context) (docs []bson.M, err error) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+	if this.firstBatch != nil {
+		docs, this.firstBatch = this.firstBatch, nil
+		if len(docs) > 0 {
+			return docs, nil
+		}
+	}
+	if this.done {
+		return nil, io.EOF
+	}
+
+	res, err := GetEvApi().MongoCursorGetMore(ctx, &dto.MongoCursorGetMoreReq{
+		EsConnectData: this.connectData,
+		CursorId:      this.cursorId,
+		BatchSize:     this.batchSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if res.Done {
+		this.done = true
+		this.closed = true
+	}
+	if len(res.Batch) == 0 {
+		if this.done {
+			return nil, io.EOF
+		}
+		// 可追加游标等场景下服务端可能返回空批次，此时游标仍然有效
+		return []bson.M{}, nil
+	}
+	return res.Batch, nil
+}
+
+// Close 关闭游标，对应killCursors，可重复调用
+func (this *MongoCursor) Close(ctx context.Context) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	this.done = true
+	this.firstBatch = nil
+	if this.closed {
+		return nil
+	}
+	this.closed = true
+	return GetEvApi().MongoCursorKill(ctx, &dto.MongoCursorKillReq{
+		EsConnectData: this.connectData,
+		CursorId:      this.cursorId,
+	})
+}
+
+// MongoPageToken 基于排序键的分页位置，记录上一页最后一条文档的排序键和_id
+type MongoPageToken struct {
+	// 排序键的值
+	Key interface{}
+	// _id的值，排序键不唯一时用于确定先后顺序
+	Id interface{}
+}
+
+// Encode 将分页位置编码为字符串，便于返回给前端
+// 返回：
+//   - string: 编码后的分页位置
+//   - error: 错误信息
+func (this *MongoPageToken) Encode() (string, error) {
+	b, err := bson.MarshalExtJSON(bson.D{{Key: "k", Value: this.Key}, {Key: "i", Value: this.Id}}, true, false)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// ParseMongoPageToken 解析Encode编码的分页位置，s为空时返回nil
+// 参数：
+//   - s: 编码后的分页位置
+//
+// 返回：
+//   - *MongoPageToken: 分页位置
+//   - error: 错误信息
+func ParseMongoPageToken(s string) (*MongoPageToken, error) {
+	if s == "" {
+		return nil, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.Wrap(err, "无效的分页位置")
+	}
+	var doc bson.M
+	if err = bson.UnmarshalExtJSON(b, true, &doc); err != nil {
+		return nil, errors.Wrap(err, "无效的分页位置")
+	}
+	return &MongoPageToken{Key: doc["k"], Id: doc["i"]}, nil
+}
+
+// MongoKeysetOptions 基于排序键分页的选项
+type MongoKeysetOptions struct {
+	// 排序键，支持以.分隔的嵌套字段，为空时使用_id
+	// 排序键可以为null或缺失，其余的值必须是同一种类型，混合类型时$gt、$lt按类型比较会跳过其他类型的文档
+	SortKey string
+	// 是否降序
+	Desc bool
+	// 每页文档数，小于等于0时为MongoCursorBatchSize
+	PageSize int64
+	// 投影，需要包含排序键和_id
+	Projection bson.M
+}
+
+// MongoKeysetPage 基于排序键读取一页文档，按 排序键,_id 排序以保证排序键重复时分页仍然稳定
+// 与skip分页不同，每一页都通过索引定位，性能不随页码增大而下降，建议为 排序键,_id 建立复合索引
+// 参数：
+//   - ctx: 上下文
+//   - dbName: 数据库名称
+//   - collectionName: 集合名称
+//   - filter: 过滤条件，可以是bson.M、bson.D或带bson标签的结构体
+//   - opts: 分页选项
+//   - after: 上一页返回的分页位置，为nil时读取第一页
+//
+// 返回：
+//   - docs: 本页文档
+//   - next: 下一页的分页位置，没有更多数据时为nil
+//   - err: 错误信息
+func (this *EvApiAdapter) MongoKeysetPage(ctx context.Context, dbName, collectionName string, filter interface{}, opts MongoKeysetOptions, after *MongoPageToken) (docs []bson.M, next *MongoPageToken, err error) {
+	filterM, err := toBsonM(filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	sortKey := opts.SortKey
+	if sortKey == "" {
+		sortKey = "_id"
+	}
+	pageSize := opts.PageSize
+	if pageSize <= 0 {
+		pageSize = MongoCursorBatchSize
+	}
+	dir, cmp := 1, "$gt"
+	if opts.Desc {
+		dir, cmp = -1, "$lt"
+	}
+
+	sort := bson.D{{Key: sortKey, Value: dir}}
+	if sortKey != "_id" {
+		sort = append(sort, bson.E{Key: "_id", Value: dir})
+	}
+
+	if after != nil {
+		var position bson.M
+		if sortKey == "_id" {
+			position = bson.M{"_id": bson.M{cmp: after.Id}}
+		} else {
+			position = keysetPosition(sortKey, cmp, opts.Desc, after)
+		}
+		if len(filterM) == 0 {
+			filterM = position
+		} else {
+			filterM = bson.M{"$and": bson.A{filterM, position}}
+		}
+	}
+
+	docs, err = this.MongoFindDocuments(ctx, dbName, collectionName, opts.Projection, filterM, sort, 0, pageSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	if int64(len(docs)) < pageSize {
+		return docs, nil, nil
+	}
+
+	last := docs[len(docs)-1]
+	next = &MongoPageToken{Key: lookupPath(last, sortKey), Id: last["_id"]}
+	return docs, next, nil
+}
+
+// keysetPosition 生成排序键在after之后的过滤条件
+// null和缺失的排序键排在所有值之前，而$gt、$lt受类型括号限制不会匹配null，因此需要单独处理：
+// 升序时null之后是全部非null的值，降序时非null的值之后是全部null
+func keysetPosition(sortKey, cmp string, desc bool, after *MongoPageToken) bson.M {
+	sameKey := bson.M{sortKey: after.Key, "_id": bson.M{cmp: after.Id}}
+	if after.Key == nil {
+		sameKey[sortKey] = nil
+		if desc {
+			return sameKey
+		}
+		return bson.M{"$or": bson.A{bson.M{sortKey: bson.M{"$ne": nil}}, sameKey}}
+	}
+	position := bson.A{bson.M{sortKey: bson.M{cmp: after.Key}}, sameKey}
+	if desc {
+		position = append(position, bson.M{sortKey: nil})
+	}
+	return bson.M{"$or": position}
+}
+
+// lookupPath 按以.分隔的路径取文档中的字段值
+func lookupPath(doc bson.M, path string) interface{} {
+	var cur interface{} = doc
+	for _, key := range strings.Split(path, ".") {
+		switch m := cur.(type) {
+		case bson.M:
+			cur = m[key]
+		case bson.D:
+			cur = m.Map()[key]
+		default:
+			return nil
+		}
+	}
+	return cur
+}
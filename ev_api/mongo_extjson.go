@@ -0,0 +1,126 @@
+// ev_api包提供EVE API的接口和实现
+//
+// mongo_extjson.go 文件实现了MongoDB请求和响应的扩展JSON编解码，
+// 使ObjectID、DateTime、Decimal128等BSON类型在插件与基座之间无损传输。
+package ev_api
+
+// 导入所需的包
+import (
+	// 字节处理包
+	"bytes"
+	// 上下文包
+	"context"
+	// 反射包
+	"reflect"
+	// 字符串处理包
+	"strings"
+
+	// MongoDB BSON包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/bson"
+	// MongoDB原始类型包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/primitive"
+	// 高性能JSON包
+	json2 "github.com/goccy/go-json"
+	// 错误处理包
+	"github.com/pkg/errors"
+	// 类型转换包
+	"github.com/spf13/cast"
+)
+
+// extJSONTypes 需要按扩展JSON编码的请求字段类型
+var extJSONTypes = map[reflect.Type]bool{
+	reflect.TypeOf(bson.M{}):                    true,
+	reflect.TypeOf(bson.D{}):                    true,
+	reflect.TypeOf(bson.A{}):                    true,
+	reflect.TypeOf(bson.Pipeline{}):             true,
+	reflect.TypeOf([]bson.M{}):                  true,
+	reflect.TypeOf([]bson.D{}):                  true,
+	reflect.TypeOf([]interface{}{}):             true,
+	reflect.TypeOf((*interface{})(nil)).Elem():  true,
+	reflect.TypeOf((*primitive.Timestamp)(nil)): true,
+}
+
+// mongoReq MongoDB请求包装
+// BSON相关字段（文档、管道、文档ID等）按规范扩展JSON编码，其余字段保持普通JSON，
+// 并附带 "ext_json":true 告知基座按扩展JSON解析请求并以规范扩展JSON返回结果
+type mongoReq struct {
+	req interface{}
+}
+
+// MarshalJSON 编码请求
+func (this mongoReq) MarshalJSON() ([]byte, error) {
+	rv := reflect.Indirect(reflect.ValueOf(this.req))
+	rt := rv.Type()
+
+	var buf bytes.Buffer
+	buf.WriteString(`{"ext_json":true`)
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		var b []byte
+		var err error
+		if extJSONTypes[field.Type] {
+			b, err = bson.MarshalExtJSON(rv.Field(i).Interface(), true, false)
+		} else {
+			b, err = json2.Marshal(rv.Field(i).Interface())
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "编码请求字段 %s 失败", name)
+		}
+
+		key, _ := json2.Marshal(name)
+		buf.WriteByte(',')
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(b)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// decodeExtJSONValue 将通用JSON解码得到的值按扩展JSON还原为BSON类型
+func decodeExtJSONValue(v interface{}) (interface{}, error) {
+	b, err := json2.Marshal(v)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var res interface{}
+	if err = bson.UnmarshalExtJSON(b, true, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// extJSONIdString 将扩展JSON表示的文档ID转换为字符串，ObjectID转换为十六进制字符串
+func extJSONIdString(v interface{}) (string, error) {
+	id, err := decodeExtJSONValue(v)
+	if err != nil {
+		return "", err
+	}
+	if oid, ok := id.(primitive.ObjectID); ok {
+		return oid.Hex(), nil
+	}
+	return cast.ToStringE(id)
+}
+
+// requestExtJSON 发送MongoDB请求，并将规范扩展JSON格式的响应解码到result，result为nil时忽略响应内容
+func (this *evApi) requestExtJSON(ctx context.Context, api API, req interface{}, result interface{}) error {
+	res, err := this.requestProtobuf(ctx, api, mongoReq{req})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if res.StatusErr() != nil {
+		return res.StatusErr()
+	}
+	if result == nil {
+		return nil
+	}
+	return bson.UnmarshalExtJSON(res.ResByte(), true, result)
+}
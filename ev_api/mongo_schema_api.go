@@ -0,0 +1,443 @@
+// ev_api包提供EVE API的接口和实现
+//
+// mongo_schema_api.go 文件通过抽样推断MongoDB集合的结构，
+// 并提供将推断结果转换为ES映射的方法。
+package ev_api
+
+// 导入所需的包
+import (
+	// 上下文包
+	"context"
+	// 格式化包
+	"fmt"
+	// 排序包
+	"sort"
+	// 字符串处理包
+	"strings"
+	// 时间处理包
+	"time"
+
+	// MongoDB BSON包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/bson"
+	// 数据传输对象包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/dto"
+	// MongoDB基础类型包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/primitive"
+	// 视图对象包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/vo"
+)
+
+// mongoTypeName 返回值对应的BSON类型名称
+func mongoTypeName(v interface{}) string {
+	switch v.(type) {
+	case nil, primitive.Null:
+		return "null"
+	case float64, float32:
+		return "double"
+	case string:
+		return "string"
+	case bson.D, bson.M, map[string]interface{}:
+		return "object"
+	case bson.A, []interface{}, []bson.D, []bson.M:
+		return "array"
+	case primitive.Binary, []byte:
+		return "binData"
+	case primitive.Undefined:
+		return "undefined"
+	case primitive.ObjectID:
+		return "objectId"
+	case bool:
+		return "bool"
+	case primitive.DateTime, time.Time:
+		return "date"
+	case primitive.Regex:
+		return "regex"
+	case primitive.DBPointer:
+		return "dbPointer"
+	case primitive.JavaScript:
+		return "javascript"
+	case primitive.Symbol:
+		return "symbol"
+	case primitive.CodeWithScope:
+		return "javascriptWithScope"
+	case int32, int8, int16, uint8, uint16:
+		return "int"
+	case primitive.Timestamp:
+		return "timestamp"
+	case int64, int, uint32, uint, uint64:
+		return "long"
+	case primitive.Decimal128:
+		return "decimal"
+	case primitive.MinKey:
+		return "minKey"
+	case primitive.MaxKey:
+		return "maxKey"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// schemaFieldStats 推断过程中单个字段的统计信息
+type schemaFieldStats struct {
+	// 字段信息
+	field *vo.MongoSchemaField
+	// 类型名称 => 出现次数
+	types map[string]int
+	// 数组元素类型名称 => 出现次数
+	arrayTypes map[string]int
+	// 已记录的示例值，用于去重
+	examples map[string]bool
+	// 最后一次计入Count的文档序号，避免数组中的重复字段被重复计数
+	lastDoc int
+}
+
+// schemaInferrer 结构推断器
+type schemaInferrer struct {
+	// 字段路径 => 统计信息
+	stats map[string]*schemaFieldStats
+	// 每个字段保留的示例值数量
+	maxExamples int
+	// 当前文档序号
+	doc int
+}
+
+// fieldStats 返回路径对应的统计信息，不存在时创建
+func (this *schemaInferrer) fieldStats(path, name string) *schemaFieldStats {
+	s, ok := this.stats[path]
+	if !ok {
+		s = &schemaFieldStats{
+			field:      &vo.MongoSchemaField{Path: path, Name: name},
+			types:      map[string]int{},
+			arrayTypes: map[string]int{},
+			examples:   map[string]bool{},
+			lastDoc:    -1,
+		}
+		this.stats[path] = s
+	}
+	if s.lastDoc != this.doc {
+		s.lastDoc = this.doc
+		s.field.Count++
+	}
+	return s
+}
+
+// walkDoc 遍历文档中的字段
+func (this *schemaInferrer) walkDoc(prefix string, doc interface{}) {
+	switch d := doc.(type) {
+	case bson.D:
+		for _, e := range d {
+			this.walkValue(joinSchemaPath(prefix, e.Key), e.Key, e.Value)
+		}
+	case bson.M:
+		for k, v := range d {
+			this.walkValue(joinSchemaPath(prefix, k), k, v)
+		}
+	case map[string]interface{}:
+		for k, v := range d {
+			this.walkValue(joinSchemaPath(prefix, k), k, v)
+		}
+	}
+}
+
+// walkValue 记录字段值，并递归处理嵌套文档和数组
+func (this *schemaInferrer) walkValue(path, name string, v interface{}) {
+	s := this.fieldStats(path, name)
+	typ := mongoTypeName(v)
+	s.types[typ]++
+	switch typ {
+	case "object":
+		this.walkDoc(path, v)
+	case "array":
+		this.walkArray(s, path, v)
+	default:
+		this.addExample(s, v)
+	}
+}
+
+// walkArray 记录数组元素，数组中的文档按同一路径展开，嵌套数组的元素视为外层数组的元素
+func (this *schemaInferrer) walkArray(s *schemaFieldStats, path string, v interface{}) {
+	var items []interface{}
+	switch a := v.(type) {
+	case bson.A:
+		items = a
+	case []interface{}:
+		items = a
+	case []bson.D:
+		for _, d := range a {
+			items = append(items, d)
+		}
+	case []bson.M:
+		for _, m := range a {
+			items = append(items, m)
+		}
+	}
+	for _, item := range items {
+		typ := mongoTypeName(item)
+		switch typ {
+		case "object":
+			s.arrayTypes[typ]++
+			this.walkDoc(path, item)
+		case "array":
+			this.walkArray(s, path, item)
+		default:
+			s.arrayTypes[typ]++
+			this.addExample(s, item)
+		}
+	}
+}
+
+// addExample 记录不重复的示例值
+func (this *schemaInferrer) addExample(s *schemaFieldStats, v interface{}) {
+	if v == nil || len(s.field.Examples) >= this.maxExamples {
+		return
+	}
+	key := fmt.Sprintf("%T:%v", v, v)
+	if s.examples[key] {
+		return
+	}
+	s.examples[key] = true
+	s.field.Examples = append(s.field.Examples, v)
+}
+
+// joinSchemaPath 拼接字段路径
+func joinSchemaPath(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+// schemaTypes 将类型计数转换为按出现次数倒序的类型频率
+func schemaTypes(counts map[string]int) []*vo.MongoSchemaType {
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
+	types := make([]*vo.MongoSchemaType, 0, len(counts))
+	for typ, n := range counts {
+		types = append(types, &vo.MongoSchemaType{
+			Type:    typ,
+			Count:   n,
+			Percent: float64(n) * 100 / float64(total),
+		})
+	}
+	sort.Slice(types, func(i, j int) bool {
+		if types[i].Count != types[j].Count {
+			return types[i].Count > types[j].Count
+		}
+		return types[i].Type < types[j].Type
+	})
+	return types
+}
+
+// InferMongoSchema 根据文档推断结构，统计各字段路径的类型频率、出现比例和示例值
+// 参数：
+//   - docs: 样本文档
+//   - maxExamples: 每个字段保留的示例值数量
+//
+// 返回：
+//   - *vo.MongoSchema: 推断出的结构，Collection为空
+func InferMongoSchema(docs []bson.M, maxExamples int) *vo.MongoSchema {
+	inferrer := &schemaInferrer{stats: map[string]*schemaFieldStats{}, maxExamples: maxExamples}
+	for i, doc := range docs {
+		inferrer.doc = i
+		inferrer.walkDoc("", doc)
+	}
+
+	schema := &vo.MongoSchema{SampleSize: len(docs), Fields: make([]*vo.MongoSchemaField, 0, len(inferrer.stats))}
+	for _, s := range inferrer.stats {
+		s.field.Presence = float64(s.field.Count) * 100 / float64(len(docs))
+		s.field.Types = schemaTypes(s.types)
+		s.field.ArrayTypes = schemaTypes(s.arrayTypes)
+		schema.Fields = append(schema.Fields, s.field)
+	}
+	sort.Slice(schema.Fields, func(i, j int) bool {
+		return schema.Fields[i].Path < schema.Fields[j].Path
+	})
+	return schema
+}
+
+// MongoInferSchema 通过$sample抽样推断MongoDB集合的结构
+// 参数：
+//   - ctx: 上下文
+//   - dbName: 数据库名称
+//   - collectionName: 集合名称
+//   - opts: 推断选项
+//
+// 返回：
+//   - schema: 推断出的结构
+//   - err: 错误信息
+func (this *EvApiAdapter) MongoInferSchema(ctx context.Context, dbName, collectionName string, opts dto.MongoSchemaOptions) (schema *vo.MongoSchema, err error) {
+	if opts.SampleSize <= 0 {
+		opts.SampleSize = 1000
+	}
+	if opts.MaxExamples <= 0 {
+		opts.MaxExamples = 3
+	}
+	pipeline := bson.Pipeline{}
+	if len(opts.Filter) > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: opts.Filter}})
+	}
+	pipeline = append(pipeline, bson.D{{Key: "$sample", Value: bson.D{{Key: "size", Value: opts.SampleSize}}}})
+
+	docs, err := this.MongoAggregateDocuments(ctx, dbName, collectionName, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	schema = InferMongoSchema(docs, opts.MaxExamples)
+	schema.Collection = collectionName
+	return schema, nil
+}
+
+// MongoEsMappingOptions 推断结构转换为ES映射的选项
+type MongoEsMappingOptions struct {
+	// ES主版本号，小于7时映射放在_doc类型下
+	EsVersion int
+	// 字符串字段映射为text并附带keyword子字段，为false时映射为keyword
+	StringAsText bool
+	// 文档数组映射为nested，为false时映射为object
+	NestedArrays bool
+	// 出现比例低于该百分比的字段不写入映射，交给ES动态映射
+	MinPresence float64
+	// 顶层_id字段在ES中使用的字段名，为空时不映射_id（_id是ES的元数据字段，不能出现在映射中）
+	IdField string
+}
+
+// mongoEsTypes BSON类型 => ES字段类型
+var mongoEsTypes = map[string]string{
+	"double":              "double",
+	"decimal":             "double",
+	"int":                 "integer",
+	"long":                "long",
+	"string":              "keyword",
+	"symbol":              "keyword",
+	"javascript":          "keyword",
+	"javascriptWithScope": "keyword",
+	"regex":               "keyword",
+	"dbPointer":           "keyword",
+	"objectId":            "keyword",
+	"bool":                "boolean",
+	"date":                "date",
+	"timestamp":           "date",
+	"binData":             "binary",
+}
+
+// esFieldType 选出字段在ES中的类型，数值类型取最宽的类型，其他类型冲突时退化为keyword，
+// 只有null等无法映射的值时返回空字符串
+func esFieldType(field *vo.MongoSchemaField) string {
+	esTypes := map[string]bool{}
+	for _, types := range [][]*vo.MongoSchemaType{field.Types, field.ArrayTypes} {
+		for _, t := range types {
+			if t.Type == "array" {
+				continue
+			}
+			if t.Type == "object" {
+				esTypes["object"] = true
+				continue
+			}
+			if esType, ok := mongoEsTypes[t.Type]; ok {
+				esTypes[esType] = true
+			}
+		}
+	}
+	if esTypes["object"] {
+		// 文档与标量混用时ES无法建立映射，以文档为准
+		return "object"
+	}
+	for _, numeric := range []string{"double", "long", "integer"} {
+		if esTypes[numeric] {
+			for t := range esTypes {
+				if t != "double" && t != "long" && t != "integer" {
+					return "keyword"
+				}
+			}
+			return numeric
+		}
+	}
+	if len(esTypes) > 1 {
+		return "keyword"
+	}
+	for t := range esTypes {
+		return t
+	}
+	return ""
+}
+
+// hasSchemaType 字段值或数组元素中是否出现过指定类型
+func hasSchemaType(field *vo.MongoSchemaField, typ string) bool {
+	for _, types := range [][]*vo.MongoSchemaType{field.Types, field.ArrayTypes} {
+		for _, t := range types {
+			if t.Type == typ {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// MongoSchemaToEsMapping 将推断出的结构转换为ES映射，返回值可直接作为EsCreateIndex的body
+// 参数：
+//   - schema: 推断出的结构
+//   - opts: 转换选项
+//
+// 返回：
+//   - map[string]interface{}: 形如 {"mappings": {"properties": {...}}} 的索引配置
+func MongoSchemaToEsMapping(schema *vo.MongoSchema, opts MongoEsMappingOptions) map[string]interface{} {
+	root := map[string]interface{}{}
+	// 字段路径 => 该字段的properties，用于挂载子字段
+	containers := map[string]map[string]interface{}{"": root}
+
+	// 字段按路径排序，父字段总是先于子字段处理
+	for _, field := range schema.Fields {
+		if field.Presence < opts.MinPresence {
+			continue
+		}
+		parentPath, name := "", field.Path
+		if i := strings.LastIndex(field.Path, "."); i >= 0 {
+			parentPath, name = field.Path[:i], field.Path[i+1:]
+		}
+		if parentPath == "" && name == "_id" {
+			if opts.IdField == "" {
+				continue
+			}
+			name = opts.IdField
+		}
+		parent, ok := containers[parentPath]
+		if !ok {
+			// 父字段被跳过或不是文档
+			continue
+		}
+
+		esType := esFieldType(field)
+		switch esType {
+		case "":
+			continue
+		case "object":
+			props := map[string]interface{}{}
+			mapping := map[string]interface{}{"properties": props}
+			if opts.NestedArrays && len(field.ArrayTypes) > 0 {
+				mapping["type"] = "nested"
+			}
+			parent[name] = mapping
+			containers[field.Path] = props
+		case "keyword":
+			if opts.StringAsText && hasSchemaType(field, "string") {
+				parent[name] = map[string]interface{}{
+					"type": "text",
+					"fields": map[string]interface{}{
+						"keyword": map[string]interface{}{"type": "keyword", "ignore_above": 256},
+					},
+				}
+			} else {
+				parent[name] = map[string]interface{}{"type": "keyword", "ignore_above": 256}
+			}
+		default:
+			parent[name] = map[string]interface{}{"type": esType}
+		}
+	}
+
+	var mappings interface{} = map[string]interface{}{"properties": root}
+	if opts.EsVersion > 0 && opts.EsVersion < 7 {
+		mappings = map[string]interface{}{"_doc": mappings}
+	}
+	return map[string]interface{}{"mappings": mappings}
+}
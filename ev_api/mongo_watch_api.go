@@ -0,0 +1,434 @@
+// ev_api包提供EVE API的接口和实现
+//
+// mongo_watch_api.go 文件提供MongoDB变更流（change stream）的监听，
+// 支持断线后按恢复令牌续传、通过插件存储持久化恢复令牌，以及将事件转发到实时广播频道。
+package ev_api
+
+// 导入所需的包
+import (
+	// 上下文包
+	"context"
+	// 并发控制包
+	"sync"
+	// 时间处理包
+	"time"
+
+	// 日志包
+	"github.com/1340691923/eve-plugin-sdk-go/backend/logger"
+	// MongoDB BSON包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/bson"
+	// 数据传输对象包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/dto"
+	// MongoDB原始类型包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/primitive"
+	// 视图对象包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/vo"
+	// 错误处理包
+	"github.com/pkg/errors"
+)
+
+// MongoChangeHandler 变更事件处理函数，返回错误时MongoWatch停止监听，恢复令牌停留在该事件之前
+type MongoChangeHandler func(ctx context.Context, event *vo.MongoChangeEvent) error
+
+// MongoWatchOptions MongoDB变更流监听选项
+type MongoWatchOptions struct {
+	// 数据库名称，为空时监听整个集群
+	DbName string
+	// 集合名称，为空时监听整个数据库
+	CollectionName string
+	// 追加在$changeStream之后的过滤管道，例如 bson.NewPipeline().Match(bson.M{"operationType": "insert"}).Build()
+	Pipeline bson.Pipeline
+	// 更新事件是否返回完整文档：default、updateLookup、whenAvailable、required
+	FullDocument string
+	// 是否返回变更前的文档：off、whenAvailable、required
+	FullDocumentBeforeChange string
+	// 从该恢复令牌之后开始监听，TokenKey对应的令牌存在时以存储的令牌为准
+	ResumeAfter bson.M
+	// 从该操作时间开始监听，恢复令牌存在时忽略
+	StartAtOperationTime *primitive.Timestamp
+	// 每批拉取的事件数，小于等于0时为100
+	BatchSize int32
+	// 没有事件时每次拉取的最长等待时间，小于等于0时为1秒
+	MaxAwaitTime time.Duration
+	// 持久化恢复令牌使用的键，为空时不持久化，插件重启后从最新的变更开始监听
+	TokenKey string
+	// 恢复令牌存储，为nil时使用插件存储（见MongoStoreResumeTokens）
+	TokenStore MongoResumeTokenStore
+	// 出错后重新打开变更流的间隔，小于等于0时为5秒
+	RetryInterval time.Duration
+	// 错误回调，为nil时记录日志
+	OnError func(err error)
+}
+
+// MongoChangeStream MongoDB变更流，由基座按变更流ID保持打开
+type MongoChangeStream struct {
+	connectData  dto.EsConnectData
+	streamId     string
+	batchSize    int32
+	maxAwaitTime time.Duration
+	firstBatch   []bson.M
+	resumeToken  bson.M
+	lock         sync.Mutex
+	closed       bool
+}
+
+// MongoOpenChangeStream 打开MongoDB变更流，需要副本集或分片集群
+// 参数：
+//   - ctx: 上下文
+//   - opts: 监听选项，只使用变更流相关的字段
+//
+// 返回：
+//   - *MongoChangeStream: 变更流，用完后必须调用Close
+//   - error: 错误信息
+func (this *EvApiAdapter) MongoOpenChangeStream(ctx context.Context, opts MongoWatchOptions) (*MongoChangeStream, error) {
+	if opts.DbName == "" && opts.CollectionName != "" {
+		return nil, errors.New("监听集合时DbName不能为空")
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 100
+	}
+	if opts.MaxAwaitTime <= 0 {
+		opts.MaxAwaitTime = time.Second
+	}
+	req := &dto.MongoWatchReq{
+		EsConnectData:            this.buildEsConnectData(),
+		DbName:                   opts.DbName,
+		CollectionName:           opts.CollectionName,
+		Pipeline:                 opts.Pipeline,
+		FullDocument:             opts.FullDocument,
+		FullDocumentBeforeChange: opts.FullDocumentBeforeChange,
+		BatchSize:                opts.BatchSize,
+		MaxAwaitTimeMS:           opts.MaxAwaitTime.Milliseconds(),
+		IdleTimeout:              int(MongoCursorIdleTimeout.Seconds()),
+	}
+	if opts.ResumeAfter != nil {
+		req.ResumeAfter = opts.ResumeAfter
+	} else {
+		req.StartAtOperationTime = opts.StartAtOperationTime
+	}
+	res, err := GetEvApi().MongoWatchOpen(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return &MongoChangeStream{
+		connectData:  req.EsConnectData,
+		streamId:     res.StreamId,
+		batchSize:    opts.BatchSize,
+		maxAwaitTime: opts.MaxAwaitTime,
+		firstBatch:   res.Events,
+		resumeToken:  res.ResumeToken,
+	}, nil
+}
+
+// Next 读取下一批事件，没有事件时最多等待MaxAwaitTime后返回空切片
+// 参数：
+//   - ctx: 上下文
+//
+// 返回：
+//   - events: 本批事件
+//   - err: 错误信息
+func (this *MongoChangeStream) Next(ctx context.Context) (events []*vo.MongoChangeEvent, err error) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+	if this.closed {
+		return nil, errors.New("变更流已关闭")
+	}
+	docs := this.firstBatch
+	this.firstBatch = nil
+	// batchToken 本批之后的恢复令牌，首批使用打开变更流时返回的令牌
+	batchToken := this.resumeToken
+	if docs == nil {
+		res, err := GetEvApi().MongoWatchNext(ctx, &dto.MongoWatchNextReq{
+			EsConnectData:  this.connectData,
+			StreamId:       this.streamId,
+			BatchSize:      this.batchSize,
+			MaxAwaitTimeMS: this.maxAwaitTime.Milliseconds(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		docs = res.Events
+		batchToken = res.ResumeToken
+	}
+
+	events = make([]*vo.MongoChangeEvent, len(docs))
+	for i, doc := range docs {
+		events[i] = &vo.MongoChangeEvent{}
+		if err = bson.Unmarshal(doc, events[i]); err != nil {
+			return nil, errors.Wrapf(err, "解码第%d个变更事件失败", i)
+		}
+	}
+	// 基座未返回本批的恢复令牌时使用最后一个事件的_id，不能保留上一批的令牌，否则恢复位置会后退
+	if batchToken != nil {
+		this.resumeToken = batchToken
+	} else if len(events) > 0 {
+		this.resumeToken = events[len(events)-1].Id
+	}
+	return events, nil
+}
+
+// ResumeToken 返回已读取批次之后的恢复令牌，用于重新打开变更流
+func (this *MongoChangeStream) ResumeToken() bson.M {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.resumeToken
+}
+
+// Close 关闭变更流，可重复调用
+// 参数：
+//   - ctx: 上下文
+//
+// 返回：
+//   - error: 错误信息
+func (this *MongoChangeStream) Close(ctx context.Context) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	this.firstBatch = nil
+	if this.closed {
+		return nil
+	}
+	this.closed = true
+	return GetEvApi().MongoWatchClose(ctx, &dto.MongoWatchCloseReq{
+		EsConnectData: this.connectData,
+		StreamId:      this.streamId,
+	})
+}
+
+// handlerErr 处理函数返回的错误，出现时停止监听
+type handlerErr struct {
+	err error
+}
+
+// Error 实现error接口
+func (this *handlerErr) Error() string {
+	return this.err.Error()
+}
+
+// MongoWatch 监听MongoDB变更并依次调用handler，阻塞直到ctx被取消或handler返回错误
+// 变更流断开时按最近的恢复令牌重新打开；设置TokenKey时每批事件处理完后保存恢复令牌，插件重启后从上次的位置继续，
+// 保证每个事件至少被处理一次。一般在ReadyCallBack中用go启动：
+//
+//	go api.MongoWatch(ctx, ev_api.MongoWatchOptions{
+//		DbName: "shop", CollectionName: "orders", TokenKey: "orders-watcher",
+//	}, func(ctx context.Context, event *vo.MongoChangeEvent) error {
+//		return handleOrderChange(event)
+//	})
+//
+// 参数：
+//   - ctx: 上下文
+//   - opts: 监听选项
+//   - handler: 事件处理函数
+//
+// 返回：
+//   - error: handler返回的错误或无法恢复的错误，ctx被取消时为nil
+func (this *EvApiAdapter) MongoWatch(ctx context.Context, opts MongoWatchOptions, handler MongoChangeHandler) error {
+	if opts.RetryInterval <= 0 {
+		opts.RetryInterval = 5 * time.Second
+	}
+	if opts.TokenKey != "" && opts.TokenStore == nil {
+		opts.TokenStore = MongoStoreResumeTokens
+	}
+	onError := func(err error) {
+		if opts.OnError != nil {
+			opts.OnError(err)
+			return
+		}
+		logger.DefaultLogger.Error("mongo change stream",
+			"db", opts.DbName,
+			"collection", opts.CollectionName,
+			"err", err.Error())
+	}
+
+	if opts.TokenKey != "" {
+		token, err := opts.TokenStore.Load(ctx, opts.TokenKey)
+		if err != nil {
+			return stopErr(ctx, err)
+		}
+		if token != nil {
+			opts.ResumeAfter = token
+		}
+	}
+
+	for ctx.Err() == nil {
+		stream, err := this.MongoOpenChangeStream(ctx, opts)
+		if err != nil {
+			onError(err)
+			if !sleepCtx(ctx, opts.RetryInterval) {
+				return nil
+			}
+			continue
+		}
+		token, err := this.consumeChangeStream(ctx, stream, opts, handler)
+		// ctx可能已被取消，使用独立的上下文关闭变更流
+		closeCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		stream.Close(closeCtx)
+		cancel()
+		if token != nil {
+			opts.ResumeAfter = token
+			// 续传时以恢复令牌为准
+			opts.StartAtOperationTime = nil
+		}
+		if herr, ok := err.(*handlerErr); ok {
+			return herr.err
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+		onError(err)
+		if !sleepCtx(ctx, opts.RetryInterval) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// consumeChangeStream 持续读取变更流直到出错，返回最后一个已处理位置的恢复令牌
+func (this *EvApiAdapter) consumeChangeStream(ctx context.Context, stream *MongoChangeStream, opts MongoWatchOptions, handler MongoChangeHandler) (token bson.M, err error) {
+	var lastSave time.Time
+	for {
+		events, err := stream.Next(ctx)
+		if err != nil {
+			return token, err
+		}
+		for _, event := range events {
+			if err = handler(ctx, event); err != nil {
+				// 保存出错事件之前的位置，重启后从出错的事件继续
+				if opts.TokenKey != "" && token != nil {
+					if serr := opts.TokenStore.Save(ctx, opts.TokenKey, token); serr != nil {
+						err = errors.WithMessagef(err, "保存恢复令牌失败: %v", serr)
+					}
+				}
+				return token, &handlerErr{err: err}
+			}
+			token = event.Id
+		}
+		// 整批处理完后才使用批次之后的令牌，没有事件时令牌也会随集群时间前进
+		if batchToken := stream.ResumeToken(); batchToken != nil {
+			token = batchToken
+		}
+		if opts.TokenKey == "" || token == nil {
+			continue
+		}
+		// 有事件时立即保存，空批次的令牌前进限制保存频率
+		if len(events) > 0 || time.Since(lastSave) >= 10*time.Second {
+			if err = opts.TokenStore.Save(ctx, opts.TokenKey, token); err != nil {
+				return token, err
+			}
+			lastSave = time.Now()
+		}
+	}
+}
+
+// MongoWatchToLive 监听MongoDB变更并将事件转发到实时广播频道，前端订阅该频道即可实时收到变更，
+// 没有订阅者时丢弃事件，其余行为与MongoWatch相同
+// 参数：
+//   - ctx: 上下文
+//   - opts: 监听选项
+//   - channel: 频道名
+//
+// 返回：
+//   - error: 无法恢复的错误，ctx被取消时为nil
+func (this *EvApiAdapter) MongoWatchToLive(ctx context.Context, opts MongoWatchOptions, channel string) error {
+	return this.MongoWatch(ctx, opts, func(ctx context.Context, event *vo.MongoChangeEvent) error {
+		_, err := this.LiveBroadcast(ctx, channel, event)
+		return err
+	})
+}
+
+// sleepCtx 等待d，ctx被取消时返回false
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// stopErr ctx被取消时返回nil，否则返回err
+func stopErr(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// MongoResumeTokenStore 变更流恢复令牌存储
+type MongoResumeTokenStore interface {
+	// Load 读取恢复令牌，不存在时返回nil
+	Load(ctx context.Context, key string) (bson.M, error)
+	// Save 保存恢复令牌
+	Save(ctx context.Context, key string, token bson.M) error
+}
+
+// MongoStoreResumeTokens 基于插件存储的恢复令牌存储，首次使用时自动建表
+var MongoStoreResumeTokens MongoResumeTokenStore = &storeResumeTokens{table: "mongo_resume_tokens"}
+
+// storeResumeTokens 基于插件存储的恢复令牌存储，令牌以规范扩展JSON保存
+type storeResumeTokens struct {
+	table   string
+	lock    sync.Mutex
+	created bool
+}
+
+// ensureTable 创建令牌表
+func (this *storeResumeTokens) ensureTable(ctx context.Context) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if this.created {
+		return nil
+	}
+	_, err := GetEvApi().StoreExec(ctx, "CREATE TABLE IF NOT EXISTS "+this.table+
+		" (token_key VARCHAR(255) NOT NULL PRIMARY KEY, token TEXT NOT NULL, updated_at BIGINT NOT NULL)")
+	if err != nil {
+		return errors.Wrap(err, "创建恢复令牌表失败")
+	}
+	this.created = true
+	return nil
+}
+
+// Load 读取恢复令牌
+func (this *storeResumeTokens) Load(ctx context.Context, key string) (bson.M, error) {
+	if err := this.ensureTable(ctx); err != nil {
+		return nil, err
+	}
+	res, err := GetEvApi().StoreSelectRows(ctx, "SELECT token FROM "+this.table+" WHERE token_key = ? LIMIT 1", key)
+	if err != nil {
+		return nil, err
+	}
+	if len(res.Result) == 0 {
+		return nil, nil
+	}
+	raw, _ := res.Result[0]["token"].(string)
+	if raw == "" {
+		return nil, nil
+	}
+	var token bson.M
+	if err = bson.UnmarshalExtJSON([]byte(raw), true, &token); err != nil {
+		return nil, errors.Wrapf(err, "解析恢复令牌 %s 失败", key)
+	}
+	return token, nil
+}
+
+// Save 保存恢复令牌
+func (this *storeResumeTokens) Save(ctx context.Context, key string, token bson.M) error {
+	if err := this.ensureTable(ctx); err != nil {
+		return err
+	}
+	raw, err := bson.MarshalExtJSON(token, true, false)
+	if err != nil {
+		return err
+	}
+	return GetEvApi().StoreInsertOrUpdate(ctx, this.table, map[string]interface{}{
+		"token_key":  key,
+		"token":      string(raw),
+		"updated_at": time.Now().Unix(),
+	}, "token_key")
+}
@@ -3,3 +3,335 @@
 // mysql_api.go 文件定义了与MySQL数据库交互的API接口和实现，
 // 提供了数据查询、操作和管理的功能。
 package ev_api
+
+// 导入所需的包
+import (
+	// 上下文包
+	"context"
+	// IO包
+	"io"
+	// 并发控制包
+	"sync"
+	// 时间处理包
+	"time"
+
+	// 数据传输对象包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/dto"
+	// 视图对象包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/vo"
+	// 错误处理包
+	"github.com/pkg/errors"
+)
+
+// MysqlTxIdleTimeout MySQL事务的空闲超时时间，超时未操作时基座自动回滚
+var MysqlTxIdleTimeout = 60 * time.Second
+
+// ErrTxDone 事务已提交或已回滚
+var ErrTxDone = errors.New("事务已提交或已回滚")
+
+// MysqlTx MySQL事务接口，所有操作都在基座固定的同一个连接上执行
+type MysqlTx interface {
+	// TxId 返回事务ID
+	TxId() string
+	// Exec 在事务内执行SQL语句（INSERT、UPDATE、DELETE）
+	Exec(ctx context.Context, sql string, args ...interface{}) (rowsAffected int64, err error)
+	// Select 在事务内执行查询语句
+	Select(ctx context.Context, sql string, args ...interface{}) (columns []string, res []map[string]interface{}, err error)
+	// SelectRows 在事务内执行查询语句，返回有序列名、列类型元数据及结果，数字以json.Number保留精度
+	SelectRows(ctx context.Context, sql string, args ...interface{}) (*vo.MysqlSelectSqlRes, error)
+	// First 在事务内执行查询并获取第一条记录
+	First(ctx context.Context, sql string, args ...interface{}) (res map[string]interface{}, err error)
+	// Commit 提交事务
+	Commit(ctx context.Context) error
+	// Rollback 回滚事务，事务已结束时返回ErrTxDone
+	Rollback(ctx context.Context) error
+}
+
+// mysqlTx MySQL事务实现
+type mysqlTx struct {
+	connectData dto.EsConnectData
+	txId        string
+	lock        sync.Mutex
+	done        bool
+	closeCh     chan struct{}
+}
+
+// MysqlBeginTx 开启MySQL事务
+// 插件传入的ctx被取消时，若事务尚未结束则自动回滚
+// 参数：
+//   - ctx: 上下文，决定事务的生命周期
+//   - dbName: 数据库名称
+//
+// 返回：
+//   - MysqlTx: 事务
+//   - err: 错误信息
+func (this *EvApiAdapter) MysqlBeginTx(ctx context.Context, dbName string) (MysqlTx, error) {
+	connectData := this.buildEsConnectData()
+	txId, err := GetEvApi().MysqlBeginTx(ctx, &dto.MysqlBeginTxReq{
+		EsConnectData: connectData,
+		DbName:        dbName,
+		IdleTimeout:   int(MysqlTxIdleTimeout.Seconds()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tx := &mysqlTx{
+		connectData: connectData,
+		txId:        txId,
+		closeCh:     make(chan struct{}),
+	}
+
+	go tx.watch(ctx)
+
+	return tx, nil
+}
+
+// watch 监听ctx，取消时自动回滚
+func (this *mysqlTx) watch(ctx context.Context) {
+	select {
+	case <-ctx.Done():
+		this.Rollback(context.Background())
+	case <-this.closeCh:
+	}
+}
+
+// TxId 返回事务ID
+func (this *mysqlTx) TxId() string {
+	return this.txId
+}
+
+// Exec 在事务内执行SQL语句
+func (this *mysqlTx) Exec(ctx context.Context, sql string, args ...interface{}) (rowsAffected int64, err error) {
+	if err = this.check(); err != nil {
+		return 0, err
+	}
+	return GetEvApi().MysqlTxExecSql(ctx, this.execReq(sql, args))
+}
+
+// Select 在事务内执行查询语句
+func (this *mysqlTx) Select(ctx context.Context, sql string, args ...interface{}) (columns []string, res []map[string]interface{}, err error) {
+	if err = this.check(); err != nil {
+		return nil, nil, err
+	}
+	return GetEvApi().MysqlTxSelectSql(ctx, this.execReq(sql, args))
+}
+
+// SelectRows 在事务内执行查询语句，返回结果及列类型元数据
+func (this *mysqlTx) SelectRows(ctx context.Context, sql string, args ...interface{}) (*vo.MysqlSelectSqlRes, error) {
+	if err := this.check(); err != nil {
+		return nil, err
+	}
+	return GetEvApi().MysqlTxSelectRows(ctx, this.execReq(sql, args))
+}
+
+// First 在事务内执行查询并获取第一条记录
+func (this *mysqlTx) First(ctx context.Context, sql string, args ...interface{}) (res map[string]interface{}, err error) {
+	if err = this.check(); err != nil {
+		return nil, err
+	}
+	return GetEvApi().MysqlTxFirstSql(ctx, this.execReq(sql, args))
+}
+
+// Commit 提交事务
+func (this *mysqlTx) Commit(ctx context.Context) error {
+	if !this.finish() {
+		return ErrTxDone
+	}
+	return GetEvApi().MysqlTxCommit(ctx, &dto.MysqlTxReq{EsConnectData: this.connectData, TxId: this.txId})
+}
+
+// Rollback 回滚事务
+func (this *mysqlTx) Rollback(ctx context.Context) error {
+	if !this.finish() {
+		return ErrTxDone
+	}
+	return GetEvApi().MysqlTxRollback(ctx, &dto.MysqlTxReq{EsConnectData: this.connectData, TxId: this.txId})
+}
+
+// check 检查事务是否已结束
+func (this *mysqlTx) check() error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if this.done {
+		return ErrTxDone
+	}
+	return nil
+}
+
+// finish 将事务标记为已结束，已结束过时返回false
+func (this *mysqlTx) finish() bool {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if this.done {
+		return false
+	}
+	this.done = true
+	close(this.closeCh)
+	return true
+}
+
+// execReq 构建事务内执行请求
+func (this *mysqlTx) execReq(sql string, args []interface{}) *dto.MysqlTxExecReq {
+	return &dto.MysqlTxExecReq{
+		EsConnectData: this.connectData,
+		TxId:          this.txId,
+		Sql:           sql,
+		Args:          args,
+	}
+}
+
+// MysqlCursorIdleTimeout MySQL游标的空闲超时时间，超时未拉取时基座自动关闭游标
+var MysqlCursorIdleTimeout = 60 * time.Second
+
+// MysqlCursorBatchSize MySQL游标默认每批拉取的行数
+const MysqlCursorBatchSize = 1000
+
+// MysqlCursor MySQL服务端游标，用于分批读取大结果集
+type MysqlCursor struct {
+	connectData dto.EsConnectData
+	cursorId    string
+	columns     []string
+	columnTypes []vo.ColumnType
+	batchSize   int
+	lock        sync.Mutex
+	done        bool
+}
+
+// MysqlOpenCursor 打开MySQL服务端游标
+// 使用完毕后必须调用Close，结果集读完时基座会自动关闭游标
+// 参数：
+//   - ctx: 上下文
+//   - dbName: 数据库名称
+//   - batchSize: 每批拉取的行数，小于等于0时使用MysqlCursorBatchSize
+//   - sql: SQL语句
+//   - args: SQL参数
+//
+// 返回：
+//   - *MysqlCursor: 游标
+//   - err: 错误信息
+func (this *EvApiAdapter) MysqlOpenCursor(ctx context.Context, dbName string, batchSize int, sql string, args ...interface{}) (*MysqlCursor, error) {
+	if batchSize <= 0 {
+		batchSize = MysqlCursorBatchSize
+	}
+	connectData := this.buildEsConnectData()
+	res, err := GetEvApi().MysqlCursorOpen(ctx, &dto.MysqlCursorOpenReq{
+		EsConnectData: connectData,
+		DbName:        dbName,
+		Sql:           sql,
+		Args:          args,
+		IdleTimeout:   int(MysqlCursorIdleTimeout.Seconds()),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &MysqlCursor{
+		connectData: connectData,
+		cursorId:    res.CursorId,
+		columns:     res.Columns,
+		columnTypes: res.ColumnTypes,
+		batchSize:   batchSize,
+	}, nil
+}
+
+// MysqlIterRows 以迭代器的方式逐行读取查询结果，内部通过服务端游标分批拉取
+// 迭代结束、调用方提前退出或ctx被取消时自动关闭游标，出错时以err返回后结束迭代
+// Go 1.23及以上版本可直接使用 for row, err := range api.MysqlIterRows(...)
+// 参数：
+//   - ctx: 上下文
+//   - dbName: 数据库名称
+//   - batchSize: 每批拉取的行数，小于等于0时使用MysqlCursorBatchSize
+//   - sql: SQL语句
+//   - args: SQL参数
+//
+// 返回：
+//   - 迭代器函数
+func (this *EvApiAdapter) MysqlIterRows(ctx context.Context, dbName string, batchSize int, sql string, args ...interface{}) func(yield func(row map[string]interface{}, err error) bool) {
+	return func(yield func(row map[string]interface{}, err error) bool) {
+		cursor, err := this.MysqlOpenCursor(ctx, dbName, batchSize, sql, args...)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		defer cursor.Close(context.Background())
+
+		for {
+			rows, err := cursor.Next(ctx)
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for _, row := range rows {
+				if !yield(row, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// CursorId 返回游标ID
+func (this *MysqlCursor) CursorId() string {
+	return this.cursorId
+}
+
+// Columns 返回列名列表
+func (this *MysqlCursor) Columns() []string {
+	return this.columns
+}
+
+// ColumnTypes 返回列类型元数据
+func (this *MysqlCursor) ColumnTypes() []vo.ColumnType {
+	return this.columnTypes
+}
+
+// Next 拉取下一批数据，结果集读完后返回io.EOF
+// 参数：
+//   - ctx: 上下文，被取消时返回ctx.Err()
+//
+// 返回：
+//   - rows: 本批数据
+//   - err: 错误信息
+func (this *MysqlCursor) Next(ctx context.Context) (rows []map[string]interface{}, err error) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	if this.done {
+		return nil, io.EOF
+	}
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	res, err := GetEvApi().MysqlCursorFetch(ctx, &dto.MysqlCursorFetchReq{
+		EsConnectData: this.connectData,
+		CursorId:      this.cursorId,
+		Size:          this.batchSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+	this.done = res.Done
+	if len(res.Rows) == 0 {
+		return nil, io.EOF
+	}
+	return res.Rows, nil
+}
+
+// Close 关闭游标，可重复调用
+func (this *MysqlCursor) Close(ctx context.Context) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	if this.done {
+		return nil
+	}
+	this.done = true
+	return GetEvApi().MysqlCursorClose(ctx, &dto.MysqlCursorCloseReq{
+		EsConnectData: this.connectData,
+		CursorId:      this.cursorId,
+	})
+}
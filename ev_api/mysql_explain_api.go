@@ -0,0 +1,570 @@
+// ev_api包提供EVE API的接口和实现
+//
+// mysql_explain_api.go 文件提供MySQL执行计划与慢查询分析接口，
+// 执行计划解析为统一的计划树，慢查询统计来自performance_schema语句摘要或慢日志表。
+package ev_api
+
+// 导入所需的包
+import (
+	// 上下文包
+	"context"
+	// 正则表达式包
+	"regexp"
+	// 排序包
+	"sort"
+	// 字符串处理包
+	"strings"
+	// 时间处理包
+	"time"
+
+	// 视图对象包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/vo"
+	// JSON处理包
+	"github.com/goccy/go-json"
+	// 错误处理包
+	"github.com/pkg/errors"
+	// 类型转换包
+	"github.com/spf13/cast"
+)
+
+// MysqlExplainFormat 执行计划格式
+type MysqlExplainFormat string
+
+// 执行计划格式
+const (
+	// ExplainTraditional 传统表格格式
+	ExplainTraditional MysqlExplainFormat = "TRADITIONAL"
+	// ExplainJSON JSON格式
+	ExplainJSON MysqlExplainFormat = "JSON"
+	// ExplainTree 树形格式，需要MySQL 8.0.16及以上
+	ExplainTree MysqlExplainFormat = "TREE"
+	// ExplainAnalyze 实际执行并统计耗时，需要MySQL 8.0.18及以上
+	ExplainAnalyze MysqlExplainFormat = "ANALYZE"
+)
+
+// MysqlExplain 获取SQL的执行计划
+// 注意：ExplainAnalyze会真正执行SQL，对写语句使用时请放在事务中并回滚
+// 参数：
+//   - ctx: 上下文
+//   - dbName: 数据库名称
+//   - sql: 待分析的SQL语句
+//   - args: SQL参数
+//   - format: 执行计划格式，为空时使用ExplainTraditional
+//
+// 返回：
+//   - plan: 执行计划
+//   - err: 错误信息
+func (this *EvApiAdapter) MysqlExplain(ctx context.Context, dbName, sql string, args []interface{}, format MysqlExplainFormat) (plan *vo.MysqlExplainPlan, err error) {
+	if format == "" {
+		format = ExplainTraditional
+	}
+	plan = &vo.MysqlExplainPlan{Format: string(format)}
+
+	switch format {
+	case ExplainTraditional:
+		plan.Rows, err = ScanMysqlSelect[*vo.MysqlExplainRow](ctx, this, dbName, "EXPLAIN "+sql, args...)
+		if err != nil {
+			return nil, err
+		}
+		plan.Root = explainRowsToTree(plan.Rows)
+		return plan, nil
+	case ExplainJSON, ExplainTree:
+		plan.Raw, err = this.explainRaw(ctx, dbName, "EXPLAIN FORMAT="+string(format)+" "+sql, args)
+	case ExplainAnalyze:
+		plan.Raw, err = this.explainRaw(ctx, dbName, "EXPLAIN ANALYZE "+sql, args)
+	default:
+		return nil, errors.Errorf("不支持的执行计划格式：%s", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if format == ExplainJSON {
+		plan.Root, err = ParseMysqlExplainJSON(plan.Raw)
+	} else {
+		plan.Root, err = ParseMysqlExplainTree(plan.Raw)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// explainRaw 执行EXPLAIN并返回唯一一列的文本
+func (this *EvApiAdapter) explainRaw(ctx context.Context, dbName, sql string, args []interface{}) (string, error) {
+	res, err := this.MysqlFirstSql(ctx, dbName, sql, args...)
+	if err != nil {
+		return "", err
+	}
+	for _, v := range res {
+		return cast.ToString(v), nil
+	}
+	return "", errors.New("EXPLAIN结果为空")
+}
+
+// explainRowsToTree 将传统格式的结果行转换为计划树，每行作为根节点的一个子节点
+func explainRowsToTree(rows []*vo.MysqlExplainRow) *vo.MysqlExplainNode {
+	root := &vo.MysqlExplainNode{Operation: "query"}
+	for _, row := range rows {
+		node := &vo.MysqlExplainNode{
+			Operation:  row.SelectType,
+			Table:      row.Table,
+			AccessType: row.Type,
+			Key:        row.Key,
+			Rows:       float64(row.Rows),
+		}
+		if row.PossibleKeys != "" {
+			node.PossibleKeys = strings.Split(row.PossibleKeys, ",")
+		}
+		if row.Filtered != nil {
+			node.Filtered = *row.Filtered
+		}
+		if row.Extra != "" {
+			node.Extra = strings.Split(row.Extra, "; ")
+		}
+		root.Rows += node.Rows
+		root.Children = append(root.Children, node)
+	}
+	return root
+}
+
+// explainJSONChildKeys JSON格式执行计划中包含子节点的键，按输出顺序排列
+var explainJSONChildKeys = []string{
+	"query_block",
+	"union_result",
+	"query_specifications",
+	"windowing",
+	"ordering_operation",
+	"grouping_operation",
+	"duplicates_removal",
+	"nested_loop",
+	"table",
+	"materialized_from_subquery",
+	"attached_subqueries",
+	"optimized_away_subqueries",
+	"order_by_subqueries",
+	"group_by_subqueries",
+	"having_subqueries",
+	"select_list_subqueries",
+	"update_value_subqueries",
+}
+
+// explainJSONFlags JSON格式执行计划中表示额外信息的布尔键
+var explainJSONFlags = map[string]string{
+	"using_index":                  "Using index",
+	"using_filesort":               "Using filesort",
+	"using_temporary_table":        "Using temporary",
+	"using_join_buffer":            "Using join buffer",
+	"using_MRR":                    "Using MRR",
+	"using_index_for_group_by":     "Using index for group-by",
+	"index_condition":              "Using index condition",
+	"using_where":                  "Using where",
+	"distinct":                     "Distinct",
+	"dependent":                    "Dependent",
+	"cacheable":                    "Cacheable",
+	"using_temporary_table_for_gb": "Using temporary for group by",
+}
+
+// ParseMysqlExplainJSON 解析EXPLAIN FORMAT=JSON的输出
+// 参数：
+//   - raw: EXPLAIN FORMAT=JSON的输出
+//
+// 返回：
+//   - *vo.MysqlExplainNode: 计划树
+//   - error: 错误信息
+func ParseMysqlExplainJSON(raw string) (*vo.MysqlExplainNode, error) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal([]byte(raw), &doc); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return explainJSONChild("query", doc), nil
+}
+
+// explainJSONNode 递归解析JSON格式执行计划的一个对象
+func explainJSONNode(operation string, m map[string]interface{}) *vo.MysqlExplainNode {
+	node := &vo.MysqlExplainNode{Operation: operation}
+
+	node.Table = cast.ToString(m["table_name"])
+	node.AccessType = cast.ToString(m["access_type"])
+	node.Key = cast.ToString(m["key"])
+	node.PossibleKeys = cast.ToStringSlice(m["possible_keys"])
+	node.UsedKeyParts = cast.ToStringSlice(m["used_key_parts"])
+	node.Condition = cast.ToString(m["attached_condition"])
+	node.Rows = cast.ToFloat64(m["rows_examined_per_scan"])
+	node.Filtered = cast.ToFloat64(m["filtered"])
+	if costInfo, ok := m["cost_info"].(map[string]interface{}); ok {
+		if cost, ok := costInfo["query_cost"]; ok {
+			node.Cost = cast.ToFloat64(cost)
+		} else {
+			node.Cost = cast.ToFloat64(costInfo["prefix_cost"])
+		}
+	}
+	for key, extra := range explainJSONFlags {
+		if v, ok := m[key]; ok && v != false {
+			node.Extra = append(node.Extra, extra)
+		}
+	}
+	sort.Strings(node.Extra)
+
+	for _, key := range explainJSONChildKeys {
+		switch v := m[key].(type) {
+		case map[string]interface{}:
+			node.Children = append(node.Children, explainJSONChild(key, v))
+		case []interface{}:
+			for _, item := range v {
+				if obj, ok := item.(map[string]interface{}); ok {
+					node.Children = append(node.Children, explainJSONChild(key, obj))
+				}
+			}
+		}
+	}
+	return node
+}
+
+// explainJSONChild 解析子节点，只包裹了一个子对象的节点（例如nested_loop中的{"table":{...}}）直接展开
+func explainJSONChild(key string, m map[string]interface{}) *vo.MysqlExplainNode {
+	if len(m) == 1 {
+		for k, v := range m {
+			if obj, ok := v.(map[string]interface{}); ok {
+				return explainJSONNode(k, obj)
+			}
+		}
+	}
+	return explainJSONNode(key, m)
+}
+
+var (
+	// explainTreeCostReg 匹配 (cost=1.25 rows=10)
+	explainTreeCostReg = regexp.MustCompile(`\(cost=([\d.e+-]+)(?:\.\.[\d.e+-]+)? rows=([\d.e+-]+)\)`)
+	// explainTreeActualReg 匹配 (actual time=0.020..0.030 rows=10 loops=1)
+	explainTreeActualReg = regexp.MustCompile(`\(actual time=([\d.e+-]+)\.\.([\d.e+-]+) rows=([\d.e+-]+) loops=(\d+)\)`)
+	// explainTreeTableReg 匹配 Table scan on t1、Index lookup on t1 using idx
+	explainTreeTableReg = regexp.MustCompile(` on (\S+?)(?: using (\S+?))?(?: |$)`)
+)
+
+// ParseMysqlExplainTree 解析EXPLAIN FORMAT=TREE或EXPLAIN ANALYZE的输出
+// 每行以 -> 开头，缩进表示层级
+// 参数：
+//   - raw: EXPLAIN的输出
+//
+// 返回：
+//   - *vo.MysqlExplainNode: 计划树
+//   - error: 错误信息
+func ParseMysqlExplainTree(raw string) (*vo.MysqlExplainNode, error) {
+	root := &vo.MysqlExplainNode{Operation: "query"}
+	type level struct {
+		indent int
+		node   *vo.MysqlExplainNode
+	}
+	stack := []level{{indent: -1, node: root}}
+	var last *vo.MysqlExplainNode
+
+	for _, line := range strings.Split(raw, "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if trimmed == "" {
+			continue
+		}
+		if !strings.HasPrefix(trimmed, "->") {
+			// 过长的条件会折行，追加到上一个节点
+			if last != nil {
+				last.Operation += " " + strings.TrimSpace(trimmed)
+			}
+			continue
+		}
+
+		indent := len(line) - len(trimmed)
+		node := parseExplainTreeLine(strings.TrimSpace(strings.TrimPrefix(trimmed, "->")))
+		for len(stack) > 1 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		parent := stack[len(stack)-1].node
+		parent.Children = append(parent.Children, node)
+		stack = append(stack, level{indent: indent, node: node})
+		last = node
+	}
+
+	if len(root.Children) == 0 {
+		return nil, errors.Errorf("无法解析执行计划：%s", raw)
+	}
+	if len(root.Children) == 1 {
+		return root.Children[0], nil
+	}
+	return root, nil
+}
+
+// parseExplainTreeLine 解析树形执行计划的一行
+func parseExplainTreeLine(line string) *vo.MysqlExplainNode {
+	node := &vo.MysqlExplainNode{}
+
+	if m := explainTreeCostReg.FindStringSubmatch(line); m != nil {
+		node.Cost = cast.ToFloat64(m[1])
+		node.Rows = cast.ToFloat64(m[2])
+	}
+	if m := explainTreeActualReg.FindStringSubmatch(line); m != nil {
+		node.Actual = &vo.MysqlExplainActual{
+			FirstRowMs: cast.ToFloat64(m[1]),
+			LastRowMs:  cast.ToFloat64(m[2]),
+			Rows:       cast.ToFloat64(m[3]),
+			Loops:      cast.ToInt64(m[4]),
+		}
+	} else if strings.Contains(line, "(never executed)") {
+		node.Actual = &vo.MysqlExplainActual{NeverExecuted: true}
+	}
+
+	operation := line
+	if i := strings.Index(operation, "  ("); i >= 0 {
+		operation = operation[:i]
+	}
+	node.Operation = strings.TrimSpace(operation)
+
+	if strings.HasPrefix(node.Operation, "Filter: ") {
+		node.Condition = strings.TrimPrefix(node.Operation, "Filter: ")
+	} else if m := explainTreeTableReg.FindStringSubmatch(node.Operation); m != nil {
+		node.Table = m[1]
+		node.Key = m[2]
+	}
+	return node
+}
+
+// MysqlDigestOrderBy 语句摘要的排序方式
+type MysqlDigestOrderBy string
+
+// 语句摘要的排序方式
+const (
+	// DigestOrderByTotalLatency 按总耗时排序
+	DigestOrderByTotalLatency MysqlDigestOrderBy = "total_latency_ms"
+	// DigestOrderByAvgLatency 按平均耗时排序
+	DigestOrderByAvgLatency MysqlDigestOrderBy = "avg_latency_ms"
+	// DigestOrderByMaxLatency 按最大耗时排序
+	DigestOrderByMaxLatency MysqlDigestOrderBy = "max_latency_ms"
+	// DigestOrderByExecCount 按执行次数排序
+	DigestOrderByExecCount MysqlDigestOrderBy = "exec_count"
+	// DigestOrderByRowsExamined 按扫描行数排序
+	DigestOrderByRowsExamined MysqlDigestOrderBy = "rows_examined"
+	// DigestOrderByNoIndexUsed 按未使用索引的次数排序
+	DigestOrderByNoIndexUsed MysqlDigestOrderBy = "no_index_used"
+)
+
+// MysqlDigestOptions 语句摘要查询选项
+type MysqlDigestOptions struct {
+	// 只统计该库的语句，为空时统计所有库
+	SchemaName string
+	// 排序方式，为空时按总耗时排序
+	OrderBy MysqlDigestOrderBy
+	// 返回条数，小于等于0时为20
+	Limit int
+}
+
+// MysqlTopDigests 从performance_schema.events_statements_summary_by_digest读取耗时最高的语句
+// 需要开启performance_schema，连接用户需要有performance_schema的SELECT权限
+// 参数：
+//   - ctx: 上下文
+//   - dbName: 执行查询使用的数据库
+//   - opts: 查询选项
+//
+// 返回：
+//   - stats: 按排序方式降序排列的语句统计
+//   - err: 错误信息
+func (this *EvApiAdapter) MysqlTopDigests(ctx context.Context, dbName string, opts MysqlDigestOptions) (stats []*vo.MysqlStatementStats, err error) {
+	orderBy := opts.OrderBy
+	switch orderBy {
+	case "":
+		orderBy = DigestOrderByTotalLatency
+	case DigestOrderByTotalLatency, DigestOrderByAvgLatency, DigestOrderByMaxLatency,
+		DigestOrderByExecCount, DigestOrderByRowsExamined, DigestOrderByNoIndexUsed:
+	default:
+		return nil, errors.Errorf("不支持的排序方式：%s", orderBy)
+	}
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+
+	// 计时单位为皮秒，除以1e9转换为毫秒
+	sql := `SELECT
+	IFNULL(SCHEMA_NAME, '') AS schema_name,
+	DIGEST AS digest,
+	DIGEST_TEXT AS statement,
+	COUNT_STAR AS exec_count,
+	SUM_TIMER_WAIT / 1e9 AS total_latency_ms,
+	AVG_TIMER_WAIT / 1e9 AS avg_latency_ms,
+	MAX_TIMER_WAIT / 1e9 AS max_latency_ms,
+	SUM_LOCK_TIME / 1e9 AS lock_latency_ms,
+	SUM_ROWS_SENT AS rows_sent,
+	SUM_ROWS_EXAMINED AS rows_examined,
+	SUM_ROWS_AFFECTED AS rows_affected,
+	SUM_NO_INDEX_USED AS no_index_used,
+	SUM_CREATED_TMP_DISK_TABLES AS tmp_disk_tables,
+	FIRST_SEEN AS first_seen,
+	LAST_SEEN AS last_seen
+FROM performance_schema.events_statements_summary_by_digest
+WHERE DIGEST_TEXT IS NOT NULL`
+	args := []interface{}{}
+	if opts.SchemaName != "" {
+		sql += " AND SCHEMA_NAME = ?"
+		args = append(args, opts.SchemaName)
+	}
+	sql += " ORDER BY " + string(orderBy) + " DESC LIMIT ?"
+	args = append(args, limit)
+
+	return ScanMysqlSelect[*vo.MysqlStatementStats](ctx, this, dbName, sql, args...)
+}
+
+// MysqlSlowLogOptions 慢日志查询选项
+type MysqlSlowLogOptions struct {
+	// 只统计该库的语句，为空时统计所有库
+	SchemaName string
+	// 只统计该时间之后的记录，为零值时不限制
+	Since time.Time
+	// 最多读取的慢日志条数，小于等于0时为10000
+	MaxEntries int
+	// 排序方式，为空时按总耗时排序；不支持DigestOrderByNoIndexUsed
+	OrderBy MysqlDigestOrderBy
+	// 返回条数，小于等于0时为20
+	Limit int
+}
+
+// mysqlSlowLogRow mysql.slow_log中的一行
+type mysqlSlowLogRow struct {
+	StartTime    time.Time `db:"start_time"`
+	Db           string    `db:"db"`
+	SqlText      string    `db:"sql_text"`
+	QueryTimeMs  float64   `db:"query_time_ms"`
+	LockTimeMs   float64   `db:"lock_time_ms"`
+	RowsSent     int64     `db:"rows_sent"`
+	RowsExamined int64     `db:"rows_examined"`
+}
+
+// MysqlSlowLog 读取mysql.slow_log表，将语句去除字面量后聚合统计
+// 需要设置 log_output 包含 TABLE 并开启 slow_query_log
+// 参数：
+//   - ctx: 上下文
+//   - dbName: 执行查询使用的数据库
+//   - opts: 查询选项
+//
+// 返回：
+//   - stats: 按排序方式降序排列的语句统计
+//   - err: 错误信息
+func (this *EvApiAdapter) MysqlSlowLog(ctx context.Context, dbName string, opts MysqlSlowLogOptions) (stats []*vo.MysqlStatementStats, err error) {
+	maxEntries := opts.MaxEntries
+	if maxEntries <= 0 {
+		maxEntries = 10000
+	}
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+
+	sql := `SELECT
+	start_time,
+	db,
+	CONVERT(sql_text USING utf8mb4) AS sql_text,
+	(TIME_TO_SEC(query_time) + MICROSECOND(query_time) / 1e6) * 1000 AS query_time_ms,
+	(TIME_TO_SEC(lock_time) + MICROSECOND(lock_time) / 1e6) * 1000 AS lock_time_ms,
+	rows_sent,
+	rows_examined
+FROM mysql.slow_log
+WHERE 1 = 1`
+	args := []interface{}{}
+	if opts.SchemaName != "" {
+		sql += " AND db = ?"
+		args = append(args, opts.SchemaName)
+	}
+	if !opts.Since.IsZero() {
+		sql += " AND start_time >= ?"
+		args = append(args, opts.Since.Format("2006-01-02 15:04:05"))
+	}
+	sql += " ORDER BY start_time DESC LIMIT ?"
+	args = append(args, maxEntries)
+
+	rows, err := ScanMysqlSelect[mysqlSlowLogRow](ctx, this, dbName, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	byKey := map[string]*vo.MysqlStatementStats{}
+	for _, row := range rows {
+		statement := MysqlFingerprint(row.SqlText)
+		key := row.Db + "\x00" + statement
+		s, ok := byKey[key]
+		if !ok {
+			s = &vo.MysqlStatementStats{Schema: row.Db, Statement: statement, Sample: row.SqlText}
+			byKey[key] = s
+			stats = append(stats, s)
+		}
+		startTime := row.StartTime
+		s.ExecCount++
+		s.TotalLatencyMs += row.QueryTimeMs
+		s.LockLatencyMs += row.LockTimeMs
+		s.RowsSent += row.RowsSent
+		s.RowsExamined += row.RowsExamined
+		if row.QueryTimeMs > s.MaxLatencyMs {
+			s.MaxLatencyMs = row.QueryTimeMs
+			s.Sample = row.SqlText
+		}
+		if s.FirstSeen == nil || startTime.Before(*s.FirstSeen) {
+			s.FirstSeen = &startTime
+		}
+		if s.LastSeen == nil || startTime.After(*s.LastSeen) {
+			s.LastSeen = &startTime
+		}
+	}
+	for _, s := range stats {
+		s.AvgLatencyMs = s.TotalLatencyMs / float64(s.ExecCount)
+	}
+
+	less, err := statementStatsLess(opts.OrderBy)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(stats, func(i, j int) bool {
+		return less(stats[j], stats[i])
+	})
+	if len(stats) > limit {
+		stats = stats[:limit]
+	}
+	return stats, nil
+}
+
+// statementStatsLess 返回按排序方式比较两条统计的函数
+func statementStatsLess(orderBy MysqlDigestOrderBy) (func(a, b *vo.MysqlStatementStats) bool, error) {
+	switch orderBy {
+	case "", DigestOrderByTotalLatency:
+		return func(a, b *vo.MysqlStatementStats) bool { return a.TotalLatencyMs < b.TotalLatencyMs }, nil
+	case DigestOrderByAvgLatency:
+		return func(a, b *vo.MysqlStatementStats) bool { return a.AvgLatencyMs < b.AvgLatencyMs }, nil
+	case DigestOrderByMaxLatency:
+		return func(a, b *vo.MysqlStatementStats) bool { return a.MaxLatencyMs < b.MaxLatencyMs }, nil
+	case DigestOrderByExecCount:
+		return func(a, b *vo.MysqlStatementStats) bool { return a.ExecCount < b.ExecCount }, nil
+	case DigestOrderByRowsExamined:
+		return func(a, b *vo.MysqlStatementStats) bool { return a.RowsExamined < b.RowsExamined }, nil
+	}
+	return nil, errors.Errorf("不支持的排序方式：%s", orderBy)
+}
+
+var (
+	// fingerprintStringReg 匹配字符串字面量
+	fingerprintStringReg = regexp.MustCompile(`'(?:[^'\\]|\\.|'')*'|"(?:[^"\\]|\\.|"")*"`)
+	// fingerprintNumberReg 匹配独立的数字字面量，标识符中的数字（如t1、user2）不替换
+	fingerprintNumberReg = regexp.MustCompile(`\b(?:0x[0-9a-fA-F]+|\d+(?:\.\d+)?(?:[eE][+-]?\d+)?)\b`)
+	// fingerprintInReg 匹配 IN (?, ?, ?)
+	fingerprintInReg = regexp.MustCompile(`(?i)\bin\s*\(\s*\?(?:\s*,\s*\?)*\s*\)`)
+	// fingerprintSpaceReg 匹配连续空白
+	fingerprintSpaceReg = regexp.MustCompile(`\s+`)
+)
+
+// MysqlFingerprint 去除SQL中的字面量，得到用于聚合的语句摘要
+// 例如 SELECT * FROM t WHERE id IN (1, 2) AND name = 'a' => SELECT * FROM t WHERE id IN (...) AND name = ?
+// 参数：
+//   - sql: SQL语句
+//
+// 返回：
+//   - string: 语句摘要
+func MysqlFingerprint(sql string) string {
+	s := fingerprintStringReg.ReplaceAllString(sql, "?")
+	s = fingerprintNumberReg.ReplaceAllString(s, "?")
+	s = fingerprintInReg.ReplaceAllString(s, "IN (...)")
+	s = fingerprintSpaceReg.ReplaceAllString(s, " ")
+	return strings.TrimSpace(s)
+}
@@ -0,0 +1,237 @@
+// ev_api包提供EVE API的接口和实现
+//
+// mysql_schema_api.go 文件提供MySQL表结构的查询接口，
+// 通过information_schema查询列、索引、表状态和外键信息，并转换为vo中的结构体。
+package ev_api
+
+// 导入所需的包
+import (
+	// 上下文包
+	"context"
+	// 字符串处理包
+	"strings"
+
+	// 视图对象包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/vo"
+	// 错误处理包
+	"github.com/pkg/errors"
+	// 类型转换包
+	"github.com/spf13/cast"
+)
+
+// MysqlDescribeTable 获取MySQL表的列信息
+// 参数：
+//   - ctx: 上下文
+//   - dbName: 数据库名称
+//   - tableName: 表名
+//
+// 返回：
+//   - columns: 按列序号排列的列信息
+//   - err: 错误信息
+func (this *EvApiAdapter) MysqlDescribeTable(ctx context.Context, dbName, tableName string) (columns []*vo.MysqlColumn, err error) {
+	return ScanMysqlSelect[*vo.MysqlColumn](ctx, this, dbName, `SELECT
+	COLUMN_NAME AS name,
+	ORDINAL_POSITION AS position,
+	COLUMN_TYPE AS column_type,
+	DATA_TYPE AS data_type,
+	IS_NULLABLE = 'YES' AS nullable,
+	COLUMN_DEFAULT AS `+"`default`"+`,
+	COLUMN_KEY AS `+"`key`"+`,
+	EXTRA AS extra,
+	IFNULL(CHARACTER_SET_NAME, '') AS character_set,
+	IFNULL(COLLATION_NAME, '') AS collation,
+	COLUMN_COMMENT AS comment
+FROM information_schema.COLUMNS
+WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?
+ORDER BY ORDINAL_POSITION`, dbName, tableName)
+}
+
+// mysqlIndexRow information_schema.STATISTICS中的一行
+type mysqlIndexRow struct {
+	IndexName    string `db:"index_name"`
+	NonUnique    bool   `db:"non_unique"`
+	ColumnName   string `db:"column_name"`
+	SubPart      *int64 `db:"sub_part"`
+	Collation    string `db:"collation"`
+	IndexType    string `db:"index_type"`
+	IndexComment string `db:"index_comment"`
+}
+
+// MysqlIndexes 获取MySQL表的索引信息，主键排在最前
+// 参数：
+//   - ctx: 上下文
+//   - dbName: 数据库名称
+//   - tableName: 表名
+//
+// 返回：
+//   - indexes: 索引信息
+//   - err: 错误信息
+func (this *EvApiAdapter) MysqlIndexes(ctx context.Context, dbName, tableName string) (indexes []*vo.MysqlIndex, err error) {
+	rows, err := ScanMysqlSelect[mysqlIndexRow](ctx, this, dbName, `SELECT
+	INDEX_NAME AS index_name,
+	NON_UNIQUE AS non_unique,
+	IFNULL(COLUMN_NAME, '') AS column_name,
+	SUB_PART AS sub_part,
+	IFNULL(COLLATION, '') AS collation,
+	INDEX_TYPE AS index_type,
+	INDEX_COMMENT AS index_comment
+FROM information_schema.STATISTICS
+WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?
+ORDER BY INDEX_NAME = 'PRIMARY' DESC, INDEX_NAME, SEQ_IN_INDEX`, dbName, tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	indexes = []*vo.MysqlIndex{}
+	byName := map[string]*vo.MysqlIndex{}
+	for _, row := range rows {
+		index, ok := byName[row.IndexName]
+		if !ok {
+			index = &vo.MysqlIndex{
+				Name:      row.IndexName,
+				Primary:   row.IndexName == "PRIMARY",
+				Unique:    !row.NonUnique,
+				IndexType: row.IndexType,
+				Comment:   row.IndexComment,
+			}
+			byName[row.IndexName] = index
+			indexes = append(indexes, index)
+		}
+		index.Columns = append(index.Columns, vo.MysqlIndexColumn{
+			Name:      row.ColumnName,
+			SubPart:   row.SubPart,
+			Collation: row.Collation,
+		})
+	}
+	return indexes, nil
+}
+
+// MysqlShowCreateTable 获取MySQL表（或视图）的建表语句
+// 参数：
+//   - ctx: 上下文
+//   - dbName: 数据库名称
+//   - tableName: 表名
+//
+// 返回：
+//   - ddl: 建表语句
+//   - err: 错误信息
+func (this *EvApiAdapter) MysqlShowCreateTable(ctx context.Context, dbName, tableName string) (ddl string, err error) {
+	res, err := this.MysqlFirstSql(ctx, dbName, "SHOW CREATE TABLE "+QuoteMysqlIdent(dbName)+"."+QuoteMysqlIdent(tableName))
+	if err != nil {
+		return "", err
+	}
+	for _, col := range []string{"Create Table", "Create View"} {
+		if v, ok := res[col]; ok {
+			return cast.ToString(v), nil
+		}
+	}
+	return "", errors.Errorf("表%s.%s的建表语句为空", dbName, tableName)
+}
+
+// MysqlTableStatus 获取MySQL表的状态信息（行数、数据/索引大小、引擎等）
+// 参数：
+//   - ctx: 上下文
+//   - dbName: 数据库名称
+//   - tableNames: 表名，为空时返回库中所有表
+//
+// 返回：
+//   - status: 按表名排序的状态信息
+//   - err: 错误信息
+func (this *EvApiAdapter) MysqlTableStatus(ctx context.Context, dbName string, tableNames ...string) (status []*vo.MysqlTableStatus, err error) {
+	sql := `SELECT
+	TABLE_NAME AS name,
+	TABLE_TYPE AS table_type,
+	IFNULL(ENGINE, '') AS engine,
+	IFNULL(ROW_FORMAT, '') AS row_format,
+	IFNULL(TABLE_ROWS, 0) AS ` + "`rows`" + `,
+	IFNULL(AVG_ROW_LENGTH, 0) AS avg_row_length,
+	IFNULL(DATA_LENGTH, 0) AS data_length,
+	IFNULL(INDEX_LENGTH, 0) AS index_length,
+	IFNULL(DATA_FREE, 0) AS data_free,
+	AUTO_INCREMENT AS auto_increment,
+	CREATE_TIME AS create_time,
+	UPDATE_TIME AS update_time,
+	IFNULL(TABLE_COLLATION, '') AS collation,
+	TABLE_COMMENT AS comment
+FROM information_schema.TABLES
+WHERE TABLE_SCHEMA = ?`
+	args := []interface{}{dbName}
+	if len(tableNames) > 0 {
+		sql += " AND TABLE_NAME IN (?" + strings.Repeat(",?", len(tableNames)-1) + ")"
+		for _, tableName := range tableNames {
+			args = append(args, tableName)
+		}
+	}
+	sql += " ORDER BY TABLE_NAME"
+
+	return ScanMysqlSelect[*vo.MysqlTableStatus](ctx, this, dbName, sql, args...)
+}
+
+// mysqlForeignKeyRow information_schema.KEY_COLUMN_USAGE中的一行
+type mysqlForeignKeyRow struct {
+	Name      string `db:"name"`
+	Column    string `db:"column_name"`
+	RefSchema string `db:"ref_schema"`
+	RefTable  string `db:"ref_table"`
+	RefColumn string `db:"ref_column"`
+	OnUpdate  string `db:"on_update"`
+	OnDelete  string `db:"on_delete"`
+}
+
+// MysqlForeignKeys 获取MySQL表的外键信息
+// 参数：
+//   - ctx: 上下文
+//   - dbName: 数据库名称
+//   - tableName: 表名
+//
+// 返回：
+//   - foreignKeys: 外键信息
+//   - err: 错误信息
+func (this *EvApiAdapter) MysqlForeignKeys(ctx context.Context, dbName, tableName string) (foreignKeys []*vo.MysqlForeignKey, err error) {
+	rows, err := ScanMysqlSelect[mysqlForeignKeyRow](ctx, this, dbName, `SELECT
+	k.CONSTRAINT_NAME AS name,
+	k.COLUMN_NAME AS column_name,
+	k.REFERENCED_TABLE_SCHEMA AS ref_schema,
+	k.REFERENCED_TABLE_NAME AS ref_table,
+	k.REFERENCED_COLUMN_NAME AS ref_column,
+	r.UPDATE_RULE AS on_update,
+	r.DELETE_RULE AS on_delete
+FROM information_schema.KEY_COLUMN_USAGE k
+JOIN information_schema.REFERENTIAL_CONSTRAINTS r
+	ON r.CONSTRAINT_SCHEMA = k.CONSTRAINT_SCHEMA AND r.CONSTRAINT_NAME = k.CONSTRAINT_NAME AND r.TABLE_NAME = k.TABLE_NAME
+WHERE k.TABLE_SCHEMA = ? AND k.TABLE_NAME = ? AND k.REFERENCED_TABLE_NAME IS NOT NULL
+ORDER BY k.CONSTRAINT_NAME, k.ORDINAL_POSITION`, dbName, tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	foreignKeys = []*vo.MysqlForeignKey{}
+	byName := map[string]*vo.MysqlForeignKey{}
+	for _, row := range rows {
+		fk, ok := byName[row.Name]
+		if !ok {
+			fk = &vo.MysqlForeignKey{
+				Name:      row.Name,
+				RefSchema: row.RefSchema,
+				RefTable:  row.RefTable,
+				OnUpdate:  row.OnUpdate,
+				OnDelete:  row.OnDelete,
+			}
+			byName[row.Name] = fk
+			foreignKeys = append(foreignKeys, fk)
+		}
+		fk.Columns = append(fk.Columns, row.Column)
+		fk.RefColumns = append(fk.RefColumns, row.RefColumn)
+	}
+	return foreignKeys, nil
+}
+
+// QuoteMysqlIdent 使用反引号转义MySQL标识符（库名、表名、列名）
+// 参数：
+//   - name: 标识符
+//
+// 返回：
+//   - string: 转义后的标识符
+func QuoteMysqlIdent(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
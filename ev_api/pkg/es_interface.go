@@ -3,7 +3,9 @@ package pkg
 import (
 	"context"
 	"github.com/1340691923/eve-plugin-sdk-go/ev_api/bson"
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/dto"
 	"github.com/1340691923/eve-plugin-sdk-go/ev_api/proto"
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/vo"
 	"net/http"
 	"time"
 )
@@ -70,9 +72,23 @@ type ClientInterface interface {
 
 	MysqlTables(ctx context.Context, dbName string) (tables []string, err error)
 
+	MysqlDescribeTable(ctx context.Context, dbName, tableName string) (columns []*vo.MysqlColumn, err error)
+
+	MysqlIndexes(ctx context.Context, dbName, tableName string) (indexes []*vo.MysqlIndex, err error)
+
+	MysqlShowCreateTable(ctx context.Context, dbName, tableName string) (ddl string, err error)
+
+	MysqlTableStatus(ctx context.Context, dbName string, tableNames ...string) (status []*vo.MysqlTableStatus, err error)
+
+	MysqlForeignKeys(ctx context.Context, dbName, tableName string) (foreignKeys []*vo.MysqlForeignKey, err error)
+
 	//redis数据源接口
 	RedisExecCommand(ctx context.Context, dbName int, args ...interface{}) (data interface{}, err error)
 
+	RedisBatchExec(ctx context.Context, dbName int, cmds [][]interface{}) (replies []vo.RedisReply, err error)
+
+	RedisTxBatchExec(ctx context.Context, dbName int, cmds [][]interface{}) (replies []vo.RedisReply, err error)
+
 	//mongo数据源接口
 
 	ExecMongoCommand(ctx context.Context, dbName string, command bson.D, timeout time.Duration) (res bson.M, err error)
@@ -95,6 +111,24 @@ type ClientInterface interface {
 
 	MongoGetCollections(ctx context.Context, dbName string) ([]string, error)
 
+	MongoListIndexes(ctx context.Context, dbName, collectionName string) (indexes []*vo.MongoIndex, err error)
+
+	MongoCreateIndexes(ctx context.Context, dbName, collectionName string, models ...dto.MongoIndexModel) (names []string, err error)
+
+	MongoDropIndex(ctx context.Context, dbName, collectionName, indexName string) error
+
+	MongoCreateCollection(ctx context.Context, dbName, collectionName string, opts dto.MongoCreateCollectionOptions) error
+
+	MongoDropCollection(ctx context.Context, dbName, collectionName string) error
+
+	MongoRenameCollection(ctx context.Context, dbName, collectionName, newName string, dropTarget bool) error
+
+	MongoCollStats(ctx context.Context, dbName, collectionName string) (stats *vo.MongoCollStats, err error)
+
+	MongoBulkWrite(ctx context.Context, dbName, collectionName string, models []dto.MongoWriteModel, opts dto.MongoBulkWriteOptions) (result *vo.MongoBulkWriteResult, err error)
+
+	MongoInferSchema(ctx context.Context, dbName, collectionName string, opts dto.MongoSchemaOptions) (schema *vo.MongoSchema, err error)
+
 	DsType() string
 
 	BatchInsertData(ctx context.Context,dbName, tableName string, cols []string, data [][]interface{}) error
@@ -11,7 +11,9 @@ package primitive // import "go.mongodb.org/mongo-driver/bson/primitive"
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
 	"time"
 )
 
@@ -34,6 +36,37 @@ func (bp Binary) IsZero() bool {
 	return bp.Subtype == 0 && len(bp.Data) == 0
 }
 
+// UnmarshalJSON creates a primitive.Binary from an extended JSON $binary value or a JSON object with Subtype and Data
+// fields. Decoding "null" leaves bp unchanged.
+func (bp *Binary) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var ext struct {
+		Binary *struct {
+			Base64  []byte `json:"base64"`
+			SubType string `json:"subType"`
+		} `json:"$binary"`
+		Subtype byte
+		Data    []byte
+	}
+	if err := json.Unmarshal(data, &ext); err != nil {
+		return err
+	}
+	if ext.Binary == nil {
+		bp.Subtype, bp.Data = ext.Subtype, ext.Data
+		return nil
+	}
+
+	subtype, err := strconv.ParseUint(ext.Binary.SubType, 16, 8)
+	if err != nil {
+		return fmt.Errorf("not an extended JSON Binary: invalid subType %q", ext.Binary.SubType)
+	}
+	bp.Subtype, bp.Data = byte(subtype), ext.Binary.Base64
+	return nil
+}
+
 // Undefined represents the BSON undefined value type.
 type Undefined struct{}
 
@@ -57,6 +90,36 @@ func (d *DateTime) UnmarshalJSON(data []byte) error {
 		return nil
 	}
 
+	// Extended JSON: {"$date": "<ISO-8601>"}, {"$date": {"$numberLong": "<millis>"}} or {"$date": <millis>}
+	if len(data) > 0 && data[0] == '{' {
+		var ext struct {
+			Date json.RawMessage `json:"$date"`
+		}
+		if err := json.Unmarshal(data, &ext); err != nil {
+			return err
+		}
+		if len(ext.Date) == 0 {
+			return errors.New("not an extended JSON DateTime: expected key $date")
+		}
+		if ext.Date[0] != '"' {
+			var millis struct {
+				NumberLong string `json:"$numberLong"`
+			}
+			if ext.Date[0] != '{' {
+				millis.NumberLong = string(ext.Date)
+			} else if err := json.Unmarshal(ext.Date, &millis); err != nil {
+				return err
+			}
+			ms, err := strconv.ParseInt(millis.NumberLong, 10, 64)
+			if err != nil {
+				return err
+			}
+			*d = DateTime(ms)
+			return nil
+		}
+		data = ext.Date
+	}
+
 	var tempTime time.Time
 	if err := json.Unmarshal(data, &tempTime); err != nil {
 		return err
@@ -99,6 +162,32 @@ func (rp Regex) IsZero() bool {
 	return rp.Pattern == "" && rp.Options == ""
 }
 
+// UnmarshalJSON creates a primitive.Regex from an extended JSON $regularExpression value or a JSON object with Pattern
+// and Options fields. Decoding "null" leaves rp unchanged.
+func (rp *Regex) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var ext struct {
+		Regex *struct {
+			Pattern string `json:"pattern"`
+			Options string `json:"options"`
+		} `json:"$regularExpression"`
+		Pattern string
+		Options string
+	}
+	if err := json.Unmarshal(data, &ext); err != nil {
+		return err
+	}
+	if ext.Regex != nil {
+		rp.Pattern, rp.Options = ext.Regex.Pattern, ext.Regex.Options
+		return nil
+	}
+	rp.Pattern, rp.Options = ext.Pattern, ext.Options
+	return nil
+}
+
 // DBPointer represents a BSON dbpointer value.
 type DBPointer struct {
 	DB      string
@@ -151,6 +240,32 @@ func (tp Timestamp) IsZero() bool {
 	return tp.T == 0 && tp.I == 0
 }
 
+// UnmarshalJSON creates a primitive.Timestamp from an extended JSON $timestamp value or a JSON object with T and I
+// fields. Decoding "null" leaves tp unchanged.
+func (tp *Timestamp) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var ext struct {
+		Timestamp *struct {
+			T uint32 `json:"t"`
+			I uint32 `json:"i"`
+		} `json:"$timestamp"`
+		T uint32
+		I uint32
+	}
+	if err := json.Unmarshal(data, &ext); err != nil {
+		return err
+	}
+	if ext.Timestamp != nil {
+		tp.T, tp.I = ext.Timestamp.T, ext.Timestamp.I
+		return nil
+	}
+	tp.T, tp.I = ext.T, ext.I
+	return nil
+}
+
 // CompareTimestamp returns an integer comparing two Timestamps, where T is compared first, followed by I.
 // Returns 0 if tp = tp2, 1 if tp > tp2, -1 if tp < tp2.
 func CompareTimestamp(tp, tp2 Timestamp) int {
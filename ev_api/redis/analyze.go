@@ -0,0 +1,192 @@
+// redis包提供基于RedisExecCommand的类型化Redis命令封装
+package redis
+
+// 导入所需的包
+import (
+	// 上下文包
+	"context"
+	// 排序包
+	"sort"
+	// 字符串处理包
+	"strings"
+	// 时间处理包
+	"time"
+)
+
+// AnalyzeOptions 键空间分析选项
+type AnalyzeOptions struct {
+	// 键的匹配模式，为空时分析所有键
+	Match string
+	// 最多采样的键数量，小于等于0时为10000
+	SampleSize int64
+	// 每次SCAN的COUNT提示，小于等于0时为500
+	BatchSize int64
+	// 键名的分隔符，为空时为":"
+	Separator string
+	// 前缀的层级，小于等于0时为1，例如层级为2时 user:1:name 的前缀为 user:1:*
+	PrefixDepth int
+	// 返回的大键数量，小于等于0时为50
+	TopN int
+	// 每扫描一批后回调已扫描的键数量，可为nil
+	Progress func(scanned int64)
+}
+
+// PrefixStat 前缀的聚合统计
+type PrefixStat struct {
+	// 前缀，例如 user:*；没有分隔符的键以键名本身作为前缀
+	Prefix string `json:"prefix"`
+	// 键数量
+	Keys int64 `json:"keys"`
+	// 内存占用（字节）
+	MemoryUsage int64 `json:"memory_usage"`
+	// 类型 => 键数量
+	Types map[string]int64 `json:"types"`
+	// 没有过期时间的键数量
+	NoExpire int64 `json:"no_expire"`
+}
+
+// AnalyzeReport 键空间分析报告
+type AnalyzeReport struct {
+	// 当前库的键总数
+	DbSize int64 `json:"db_size"`
+	// 已采样的键数量
+	Scanned int64 `json:"scanned"`
+	// 采样键的内存总和（字节）
+	MemoryUsage int64 `json:"memory_usage"`
+	// 按采样比例估算的整库内存（字节），未设置Match时有效
+	EstimatedMemoryUsage int64 `json:"estimated_memory_usage"`
+	// 是否完整扫描了键空间
+	Complete bool `json:"complete"`
+	// 按内存降序排列的大键
+	BigKeys []KeyInfo `json:"big_keys"`
+	// 按内存降序排列的前缀统计
+	Prefixes []*PrefixStat `json:"prefixes"`
+	// 耗时
+	Cost time.Duration `json:"cost"`
+}
+
+// AnalyzeKeyspace 采样键空间，找出大键并按前缀聚合内存占用
+// ctx被取消时返回已采样部分的报告及ctx.Err()
+// 参数：
+//   - ctx: 上下文
+//   - opts: 分析选项
+//
+// 返回：
+//   - *AnalyzeReport: 分析报告
+//   - error: 错误信息
+func (this *Client) AnalyzeKeyspace(ctx context.Context, opts AnalyzeOptions) (*AnalyzeReport, error) {
+	if opts.SampleSize <= 0 {
+		opts.SampleSize = 10000
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 500
+	}
+	if opts.Separator == "" {
+		opts.Separator = ":"
+	}
+	if opts.PrefixDepth <= 0 {
+		opts.PrefixDepth = 1
+	}
+	if opts.TopN <= 0 {
+		opts.TopN = 50
+	}
+
+	start := time.Now()
+	report := &AnalyzeReport{}
+	dbSize, err := this.DbSize(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report.DbSize = dbSize
+
+	prefixes := map[string]*PrefixStat{}
+	seen := map[string]struct{}{}
+	scanOpts := ScanOptions{Match: opts.Match, Count: opts.BatchSize}
+
+	finish := func(err error) (*AnalyzeReport, error) {
+		report.Prefixes = make([]*PrefixStat, 0, len(prefixes))
+		for _, stat := range prefixes {
+			report.Prefixes = append(report.Prefixes, stat)
+		}
+		sort.Slice(report.Prefixes, func(i, j int) bool {
+			if report.Prefixes[i].MemoryUsage != report.Prefixes[j].MemoryUsage {
+				return report.Prefixes[i].MemoryUsage > report.Prefixes[j].MemoryUsage
+			}
+			return report.Prefixes[i].Prefix < report.Prefixes[j].Prefix
+		})
+		if opts.Match == "" && report.Scanned > 0 {
+			report.EstimatedMemoryUsage = int64(float64(report.MemoryUsage) * float64(report.DbSize) / float64(report.Scanned))
+		}
+		report.Cost = time.Since(start)
+		return report, err
+	}
+
+	for report.Scanned < opts.SampleSize {
+		if err = ctx.Err(); err != nil {
+			return finish(err)
+		}
+		page, err := this.ScanPage(ctx, scanOpts)
+		if err != nil {
+			return finish(err)
+		}
+
+		for _, info := range page.Keys {
+			if _, ok := seen[info.Key]; ok || info.Type == "none" {
+				continue
+			}
+			seen[info.Key] = struct{}{}
+			report.Scanned++
+			report.MemoryUsage += info.MemoryUsage
+			report.BigKeys = appendBigKey(report.BigKeys, info, opts.TopN)
+
+			prefix := keyPrefix(info.Key, opts.Separator, opts.PrefixDepth)
+			stat, ok := prefixes[prefix]
+			if !ok {
+				stat = &PrefixStat{Prefix: prefix, Types: map[string]int64{}}
+				prefixes[prefix] = stat
+			}
+			stat.Keys++
+			stat.MemoryUsage += info.MemoryUsage
+			stat.Types[info.Type]++
+			if info.TTL == TTLNoExpire {
+				stat.NoExpire++
+			}
+		}
+		if opts.Progress != nil {
+			opts.Progress(report.Scanned)
+		}
+
+		if page.Done {
+			report.Complete = true
+			break
+		}
+		scanOpts.Cursor = page.NextCursor
+	}
+	return finish(nil)
+}
+
+// keyPrefix 计算键的前缀
+func keyPrefix(key, separator string, depth int) string {
+	parts := strings.SplitN(key, separator, depth+1)
+	if len(parts) <= depth {
+		return key
+	}
+	return strings.Join(parts[:depth], separator) + separator + "*"
+}
+
+// appendBigKey 将键插入按内存降序排列的大键列表，只保留前topN个
+func appendBigKey(bigKeys []KeyInfo, info KeyInfo, topN int) []KeyInfo {
+	if len(bigKeys) >= topN && info.MemoryUsage <= bigKeys[len(bigKeys)-1].MemoryUsage {
+		return bigKeys
+	}
+	i := sort.Search(len(bigKeys), func(i int) bool {
+		return bigKeys[i].MemoryUsage < info.MemoryUsage
+	})
+	bigKeys = append(bigKeys, KeyInfo{})
+	copy(bigKeys[i+1:], bigKeys[i:])
+	bigKeys[i] = info
+	if len(bigKeys) > topN {
+		bigKeys = bigKeys[:topN]
+	}
+	return bigKeys
+}
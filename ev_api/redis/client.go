@@ -0,0 +1,254 @@
+// redis包提供基于RedisExecCommand的类型化Redis命令封装
+package redis
+
+// 导入所需的包
+import (
+	// 上下文包
+	"context"
+	// 字符串处理包
+	"strings"
+	// 时间处理包
+	"time"
+
+	// 错误处理包
+	"github.com/pkg/errors"
+)
+
+// Executor 执行Redis命令的接口，*ev_api.EvApiAdapter与pkg.ClientInterface均已实现
+type Executor interface {
+	// RedisExecCommand 执行Redis命令
+	RedisExecCommand(ctx context.Context, dbName int, args ...interface{}) (data interface{}, err error)
+}
+
+// Client Redis客户端
+type Client struct {
+	api    Executor
+	dbName int
+}
+
+// NewClient 创建Redis客户端
+// 参数：
+//   - api: 命令执行接口，一般为ev_api.NewEvWrapApi(connId, userId)
+//   - dbName: 数据库编号
+//
+// 返回：
+//   - *Client: Redis客户端
+func NewClient(api Executor, dbName int) *Client {
+	return &Client{api: api, dbName: dbName}
+}
+
+// DbName 返回数据库编号
+func (this *Client) DbName() int {
+	return this.dbName
+}
+
+// Do 执行任意Redis命令并解码回复，nil回复返回(nil, nil)
+// 参数：
+//   - ctx: 上下文
+//   - args: 命令名及参数
+//
+// 返回：
+//   - interface{}: 解码后的回复，类型见Decode
+//   - error: 错误信息
+func (this *Client) Do(ctx context.Context, args ...interface{}) (interface{}, error) {
+	encoded := make([]interface{}, len(args))
+	for i, arg := range args {
+		encoded[i] = encodeArg(arg)
+	}
+	res, err := this.api.RedisExecCommand(ctx, this.dbName, encoded...)
+	if err != nil {
+		if errors.Is(err, ErrNil) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return Decode(res), nil
+}
+
+// doString 执行命令并返回字符串，nil回复返回ErrNil
+func (this *Client) doString(ctx context.Context, args ...interface{}) (string, error) {
+	res, err := this.Do(ctx, args...)
+	if err != nil {
+		return "", err
+	}
+	if res == nil {
+		return "", ErrNil
+	}
+	return toString(res), nil
+}
+
+// doInt64 执行命令并返回整数
+func (this *Client) doInt64(ctx context.Context, args ...interface{}) (int64, error) {
+	res, err := this.Do(ctx, args...)
+	if err != nil {
+		return 0, err
+	}
+	return toInt64(res)
+}
+
+// doFloat64 执行命令并返回浮点数
+func (this *Client) doFloat64(ctx context.Context, args ...interface{}) (float64, error) {
+	res, err := this.Do(ctx, args...)
+	if err != nil {
+		return 0, err
+	}
+	return toFloat64(res)
+}
+
+// doStrings 执行命令并返回字符串数组
+func (this *Client) doStrings(ctx context.Context, args ...interface{}) ([]string, error) {
+	res, err := this.Do(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+	return toStringSlice(res)
+}
+
+// doOK 执行返回OK的命令
+func (this *Client) doOK(ctx context.Context, args ...interface{}) error {
+	_, err := this.Do(ctx, args...)
+	return err
+}
+
+// withKeys 拼接命令名与键
+func withKeys(cmd string, keys []string, extra ...interface{}) []interface{} {
+	args := make([]interface{}, 0, len(keys)+len(extra)+1)
+	args = append(args, cmd)
+	for _, key := range keys {
+		args = append(args, key)
+	}
+	return append(args, extra...)
+}
+
+// Del 删除键
+// 返回：
+//   - int64: 删除的键数量
+func (this *Client) Del(ctx context.Context, keys ...string) (int64, error) {
+	return this.doInt64(ctx, withKeys("DEL", keys)...)
+}
+
+// Unlink 异步删除键
+// 返回：
+//   - int64: 删除的键数量
+func (this *Client) Unlink(ctx context.Context, keys ...string) (int64, error) {
+	return this.doInt64(ctx, withKeys("UNLINK", keys)...)
+}
+
+// Exists 判断键是否存在
+// 返回：
+//   - int64: 存在的键数量
+func (this *Client) Exists(ctx context.Context, keys ...string) (int64, error) {
+	return this.doInt64(ctx, withKeys("EXISTS", keys)...)
+}
+
+// Expire 设置键的过期时间，精度为毫秒
+// 返回：
+//   - bool: 键不存在时为false
+func (this *Client) Expire(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	n, err := this.doInt64(ctx, "PEXPIRE", key, ttl.Milliseconds())
+	return n == 1, err
+}
+
+// Persist 移除键的过期时间
+// 返回：
+//   - bool: 键不存在或没有过期时间时为false
+func (this *Client) Persist(ctx context.Context, key string) (bool, error) {
+	n, err := this.doInt64(ctx, "PERSIST", key)
+	return n == 1, err
+}
+
+// 特殊的TTL值
+const (
+	// TTLNoExpire 键存在但没有过期时间
+	TTLNoExpire time.Duration = -1
+	// TTLNotExist 键不存在
+	TTLNotExist time.Duration = -2
+)
+
+// TTL 获取键的剩余过期时间，精度为毫秒
+// 返回：
+//   - time.Duration: 剩余时间，键没有过期时间时为TTLNoExpire，键不存在时为TTLNotExist
+func (this *Client) TTL(ctx context.Context, key string) (time.Duration, error) {
+	ms, err := this.doInt64(ctx, "PTTL", key)
+	if err != nil {
+		return 0, err
+	}
+	if ms < 0 {
+		return time.Duration(ms), nil
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+// Type 获取键的类型
+// 返回：
+//   - string: string、list、set、zset、hash、stream，键不存在时为none
+func (this *Client) Type(ctx context.Context, key string) (string, error) {
+	return this.doString(ctx, "TYPE", key)
+}
+
+// MemoryUsage 获取键占用的内存
+// 参数：
+//   - samples: 嵌套类型的采样数量，小于0时使用Redis默认值，0表示全部采样
+//
+// 返回：
+//   - int64: 占用的字节数，键不存在时返回ErrNil
+func (this *Client) MemoryUsage(ctx context.Context, key string, samples int) (int64, error) {
+	args := []interface{}{"MEMORY", "USAGE", key}
+	if samples >= 0 {
+		args = append(args, "SAMPLES", samples)
+	}
+	return this.doInt64(ctx, args...)
+}
+
+// Rename 重命名键
+func (this *Client) Rename(ctx context.Context, key, newKey string) error {
+	return this.doOK(ctx, "RENAME", key, newKey)
+}
+
+// Info 获取服务器信息并按节解析
+// 参数：
+//   - sections: 节名称，例如 server、memory、keyspace，为空时返回默认节
+//
+// 返回：
+//   - map[string]map[string]string: 节名称（小写） => 字段 => 值
+func (this *Client) Info(ctx context.Context, sections ...string) (map[string]map[string]string, error) {
+	args := []interface{}{"INFO"}
+	for _, section := range sections {
+		args = append(args, section)
+	}
+	raw, err := this.doString(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseInfo(raw), nil
+}
+
+// ParseInfo 解析INFO命令的输出
+// 参数：
+//   - raw: INFO命令的输出
+//
+// 返回：
+//   - map[string]map[string]string: 节名称（小写） => 字段 => 值
+func ParseInfo(raw string) map[string]map[string]string {
+	res := map[string]map[string]string{}
+	section := ""
+	for _, line := range strings.Split(raw, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			section = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(line, "#")))
+			continue
+		}
+		k, v, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		if res[section] == nil {
+			res[section] = map[string]string{}
+		}
+		res[section][k] = v
+	}
+	return res
+}
@@ -0,0 +1,240 @@
+// redis包提供基于RedisExecCommand的类型化Redis命令封装
+package redis
+
+// 导入所需的包
+import (
+	// 上下文包
+	"context"
+	// 时间处理包
+	"time"
+
+	// 日志包
+	"github.com/1340691923/eve-plugin-sdk-go/backend/logger"
+	// 错误处理包
+	"github.com/pkg/errors"
+)
+
+// Handler 消息处理函数，返回nil时自动确认消息，返回错误时消息保留在待确认列表中等待重新投递
+type Handler func(ctx context.Context, msg XMessage) error
+
+// ConsumerOptions Stream消费者选项
+type ConsumerOptions struct {
+	// Stream键名
+	Stream string
+	// 消费组名
+	Group string
+	// 消费者名，同一消费组内唯一
+	Consumer string
+	// 消费组不存在时创建消费组的起始ID，为空时为 $ （只消费新消息）
+	StartID string
+	// 没有消息时的阻塞时间，小于等于0时为5秒
+	Block time.Duration
+	// 每次读取的最大数量，小于等于0时为10
+	Count int64
+	// 其他消费者的消息空闲超过该时间后被认领，小于等于0时为1分钟
+	MinIdle time.Duration
+	// 检查可认领消息的间隔，小于等于0时为30秒
+	ClaimInterval time.Duration
+	// 最大投递次数，超过后调用DeadLetter并确认消息，小于等于0时不限制
+	MaxDeliveries int64
+	// 超过最大投递次数的消息的处理函数，可为nil
+	DeadLetter func(ctx context.Context, msg XMessage, deliveries int64)
+	// 错误回调，为nil时记录日志
+	OnError func(err error)
+}
+
+// Consumer Stream消费组的消费者，保证消息至少被处理一次
+type Consumer struct {
+	client    *Client
+	opts      ConsumerOptions
+	lastClaim time.Time
+}
+
+// NewConsumer 创建Stream消费者
+// 参数：
+//   - client: Redis客户端
+//   - opts: 消费者选项
+//
+// 返回：
+//   - *Consumer: 消费者
+func NewConsumer(client *Client, opts ConsumerOptions) *Consumer {
+	if opts.StartID == "" {
+		opts.StartID = "$"
+	}
+	if opts.Block <= 0 {
+		opts.Block = 5 * time.Second
+	}
+	if opts.Count <= 0 {
+		opts.Count = 10
+	}
+	if opts.MinIdle <= 0 {
+		opts.MinIdle = time.Minute
+	}
+	if opts.ClaimInterval <= 0 {
+		opts.ClaimInterval = 30 * time.Second
+	}
+	return &Consumer{client: client, opts: opts}
+}
+
+// Run 开始消费，阻塞直到ctx被取消
+// 启动时先处理本消费者上次退出前未确认的消息，之后循环读取新消息，并定期认领其他崩溃消费者遗留的消息。
+// 一般传入ReadyCallBack的ctx，插件退出时处理完当前消息后返回nil。
+// 参数：
+//   - ctx: 上下文
+//   - handler: 消息处理函数
+//
+// 返回：
+//   - error: 无法恢复的错误，ctx被取消时为nil
+func (this *Consumer) Run(ctx context.Context, handler Handler) error {
+	if this.opts.Stream == "" || this.opts.Group == "" || this.opts.Consumer == "" {
+		return errors.New("Stream、Group和Consumer不能为空")
+	}
+	if err := this.client.XGroupCreate(ctx, this.opts.Stream, this.opts.Group, this.opts.StartID, true); err != nil {
+		return stopErr(ctx, err)
+	}
+
+	// 处理本消费者未确认的消息
+	for {
+		msgs, err := this.client.XReadGroup(ctx, this.opts.Stream, this.opts.Group, this.opts.Consumer, "0", this.opts.Count, 0)
+		if err != nil {
+			return stopErr(ctx, err)
+		}
+		if len(msgs) == 0 {
+			break
+		}
+		if acked := this.handle(ctx, handler, msgs); acked == 0 {
+			// 全部处理失败，交给认领流程重试，避免死循环
+			break
+		}
+	}
+
+	for ctx.Err() == nil {
+		if time.Since(this.lastClaim) >= this.opts.ClaimInterval {
+			this.lastClaim = time.Now()
+			if err := this.reclaim(ctx, handler); err != nil {
+				this.onError(err)
+			}
+		}
+
+		msgs, err := this.client.XReadGroup(ctx, this.opts.Stream, this.opts.Group, this.opts.Consumer, ">", this.opts.Count, this.opts.Block)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			this.onError(err)
+			if !sleep(ctx, time.Second) {
+				return nil
+			}
+			continue
+		}
+		this.handle(ctx, handler, msgs)
+	}
+	return nil
+}
+
+// handle 依次处理消息，处理成功的消息立即确认
+// 返回：
+//   - int: 确认的消息数量
+func (this *Consumer) handle(ctx context.Context, handler Handler, msgs []XMessage) (acked int) {
+	for _, msg := range msgs {
+		if ctx.Err() != nil {
+			return acked
+		}
+		if err := this.call(ctx, handler, msg); err != nil {
+			this.onError(errors.Wrapf(err, "处理消息%s失败", msg.ID))
+			continue
+		}
+		// 使用独立的ctx确认，避免处理完成后插件退出导致重复投递
+		if _, err := this.client.XAck(context.Background(), this.opts.Stream, this.opts.Group, msg.ID); err != nil {
+			this.onError(err)
+			continue
+		}
+		acked++
+	}
+	return acked
+}
+
+// call 调用处理函数，处理函数panic时转换为错误
+func (this *Consumer) call(ctx context.Context, handler Handler, msg XMessage) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("panic: %v", r)
+		}
+	}()
+	return handler(ctx, msg)
+}
+
+// reclaim 认领空闲超过MinIdle的消息并处理，超过最大投递次数的消息转入死信处理
+func (this *Consumer) reclaim(ctx context.Context, handler Handler) error {
+	entries, err := this.client.XPending(ctx, this.opts.Stream, this.opts.Group, "-", "+", this.opts.Count*10, "")
+	if err != nil {
+		return err
+	}
+
+	var ids []string
+	deliveries := map[string]int64{}
+	for _, entry := range entries {
+		if entry.Idle < this.opts.MinIdle {
+			continue
+		}
+		ids = append(ids, entry.ID)
+		deliveries[entry.ID] = entry.Deliveries
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	msgs, err := this.client.XClaim(ctx, this.opts.Stream, this.opts.Group, this.opts.Consumer, this.opts.MinIdle, ids...)
+	if err != nil {
+		return err
+	}
+
+	var retry []XMessage
+	for _, msg := range msgs {
+		if this.opts.MaxDeliveries > 0 && deliveries[msg.ID] >= this.opts.MaxDeliveries {
+			if this.opts.DeadLetter != nil {
+				this.opts.DeadLetter(ctx, msg, deliveries[msg.ID])
+			}
+			if _, err = this.client.XAck(context.Background(), this.opts.Stream, this.opts.Group, msg.ID); err != nil {
+				this.onError(err)
+			}
+			continue
+		}
+		retry = append(retry, msg)
+	}
+	this.handle(ctx, handler, retry)
+	return nil
+}
+
+// onError 报告错误
+func (this *Consumer) onError(err error) {
+	if this.opts.OnError != nil {
+		this.opts.OnError(err)
+		return
+	}
+	logger.DefaultLogger.Error("redis stream consumer",
+		"stream", this.opts.Stream,
+		"group", this.opts.Group,
+		"consumer", this.opts.Consumer,
+		"err", err.Error())
+}
+
+// stopErr ctx被取消时返回nil，否则返回err
+func stopErr(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// sleep 等待d，ctx被取消时返回false
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
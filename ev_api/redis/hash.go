@@ -0,0 +1,118 @@
+// redis包提供基于RedisExecCommand的类型化Redis命令封装
+package redis
+
+// 导入所需的包
+import (
+	// 上下文包
+	"context"
+)
+
+// HGet 获取哈希字段的值，字段不存在时返回ErrNil
+func (this *Client) HGet(ctx context.Context, key, field string) (string, error) {
+	return this.doString(ctx, "HGET", key, field)
+}
+
+// HGetBytes 以二进制安全的方式获取哈希字段的值，字段不存在时返回ErrNil
+func (this *Client) HGetBytes(ctx context.Context, key, field string) ([]byte, error) {
+	res, err := this.Do(ctx, "HGET", key, field)
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return nil, ErrNil
+	}
+	return toBytes(res), nil
+}
+
+// HGetAll 获取哈希的所有字段
+// 返回：
+//   - map[string]string: 字段 => 值
+func (this *Client) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	res, err := this.Do(ctx, "HGETALL", key)
+	if err != nil {
+		return nil, err
+	}
+	return toStringMap(res)
+}
+
+// HSet 设置哈希字段
+// 参数：
+//   - values: 字段 => 值
+//
+// 返回：
+//   - int64: 新增的字段数量
+func (this *Client) HSet(ctx context.Context, key string, values map[string]interface{}) (int64, error) {
+	args := []interface{}{"HSET", key}
+	for field, v := range values {
+		args = append(args, field, v)
+	}
+	return this.doInt64(ctx, args...)
+}
+
+// HSetNX 字段不存在时设置哈希字段
+// 返回：
+//   - bool: 是否设置成功
+func (this *Client) HSetNX(ctx context.Context, key, field string, value interface{}) (bool, error) {
+	n, err := this.doInt64(ctx, "HSETNX", key, field, value)
+	return n == 1, err
+}
+
+// HMGet 批量获取哈希字段
+// 返回：
+//   - []*string: 与字段一一对应，字段不存在时为nil
+func (this *Client) HMGet(ctx context.Context, key string, fields ...string) ([]*string, error) {
+	args := []interface{}{"HMGET", key}
+	for _, field := range fields {
+		args = append(args, field)
+	}
+	res, err := this.Do(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+	items, err := toSlice(res)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]*string, len(items))
+	for i, item := range items {
+		if item != nil {
+			s := toString(item)
+			values[i] = &s
+		}
+	}
+	return values, nil
+}
+
+// HDel 删除哈希字段
+// 返回：
+//   - int64: 删除的字段数量
+func (this *Client) HDel(ctx context.Context, key string, fields ...string) (int64, error) {
+	args := []interface{}{"HDEL", key}
+	for _, field := range fields {
+		args = append(args, field)
+	}
+	return this.doInt64(ctx, args...)
+}
+
+// HExists 判断哈希字段是否存在
+func (this *Client) HExists(ctx context.Context, key, field string) (bool, error) {
+	n, err := this.doInt64(ctx, "HEXISTS", key, field)
+	return n == 1, err
+}
+
+// HLen 获取哈希的字段数量
+func (this *Client) HLen(ctx context.Context, key string) (int64, error) {
+	return this.doInt64(ctx, "HLEN", key)
+}
+
+// HKeys 获取哈希的所有字段名
+func (this *Client) HKeys(ctx context.Context, key string) ([]string, error) {
+	return this.doStrings(ctx, "HKEYS", key)
+}
+
+// HIncrBy 将哈希字段增加指定整数
+// 返回：
+//   - int64: 增加后的值
+func (this *Client) HIncrBy(ctx context.Context, key, field string, increment int64) (int64, error) {
+	return this.doInt64(ctx, "HINCRBY", key, field, increment)
+}
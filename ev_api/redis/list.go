@@ -0,0 +1,67 @@
+// redis包提供基于RedisExecCommand的类型化Redis命令封装
+package redis
+
+// 导入所需的包
+import (
+	// 上下文包
+	"context"
+)
+
+// LPush 从左侧插入元素
+// 返回：
+//   - int64: 插入后的列表长度
+func (this *Client) LPush(ctx context.Context, key string, values ...interface{}) (int64, error) {
+	return this.doInt64(ctx, append([]interface{}{"LPUSH", key}, values...)...)
+}
+
+// RPush 从右侧插入元素
+// 返回：
+//   - int64: 插入后的列表长度
+func (this *Client) RPush(ctx context.Context, key string, values ...interface{}) (int64, error) {
+	return this.doInt64(ctx, append([]interface{}{"RPUSH", key}, values...)...)
+}
+
+// LPop 从左侧弹出元素，列表为空时返回ErrNil
+func (this *Client) LPop(ctx context.Context, key string) (string, error) {
+	return this.doString(ctx, "LPOP", key)
+}
+
+// RPop 从右侧弹出元素，列表为空时返回ErrNil
+func (this *Client) RPop(ctx context.Context, key string) (string, error) {
+	return this.doString(ctx, "RPOP", key)
+}
+
+// LRange 获取列表指定范围的元素，stop为-1表示到末尾
+func (this *Client) LRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
+	return this.doStrings(ctx, "LRANGE", key, start, stop)
+}
+
+// LLen 获取列表长度
+func (this *Client) LLen(ctx context.Context, key string) (int64, error) {
+	return this.doInt64(ctx, "LLEN", key)
+}
+
+// LIndex 获取列表指定下标的元素，下标越界时返回ErrNil
+func (this *Client) LIndex(ctx context.Context, key string, index int64) (string, error) {
+	return this.doString(ctx, "LINDEX", key, index)
+}
+
+// LSet 设置列表指定下标的元素
+func (this *Client) LSet(ctx context.Context, key string, index int64, value interface{}) error {
+	return this.doOK(ctx, "LSET", key, index, value)
+}
+
+// LRem 删除列表中等于value的元素
+// 参数：
+//   - count: 大于0从左侧删除count个，小于0从右侧删除，等于0删除全部
+//
+// 返回：
+//   - int64: 删除的元素数量
+func (this *Client) LRem(ctx context.Context, key string, count int64, value interface{}) (int64, error) {
+	return this.doInt64(ctx, "LREM", key, count, value)
+}
+
+// LTrim 只保留列表指定范围的元素
+func (this *Client) LTrim(ctx context.Context, key string, start, stop int64) error {
+	return this.doOK(ctx, "LTRIM", key, start, stop)
+}
@@ -0,0 +1,71 @@
+// redis包提供基于RedisExecCommand的类型化Redis命令封装
+package redis
+
+// 导入所需的包
+import (
+	// 上下文包
+	"context"
+
+	// 视图对象包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/vo"
+	// 错误处理包
+	"github.com/pkg/errors"
+)
+
+// BatchExecutor 支持pipeline批量执行的接口，*ev_api.EvApiAdapter与pkg.ClientInterface均已实现
+type BatchExecutor interface {
+	Executor
+	// RedisBatchExec 通过pipeline批量执行Redis命令
+	RedisBatchExec(ctx context.Context, dbName int, cmds [][]interface{}) (replies []vo.RedisReply, err error)
+}
+
+// Result pipeline中单条命令的结果
+type Result struct {
+	// 解码后的回复，类型见Decode
+	Value interface{}
+	// 命令的错误
+	Err error
+}
+
+// Pipeline 批量执行命令
+// Executor实现了BatchExecutor时只需一次基座往返，否则逐条执行
+// 参数：
+//   - ctx: 上下文
+//   - cmds: 命令列表，每条命令为命令名及参数
+//
+// 返回：
+//   - []Result: 与命令一一对应的结果，nil回复的Value为nil
+//   - error: 整批执行的错误信息
+func (this *Client) Pipeline(ctx context.Context, cmds [][]interface{}) ([]Result, error) {
+	results := make([]Result, len(cmds))
+	batch, ok := this.api.(BatchExecutor)
+	if !ok {
+		for i, cmd := range cmds {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			results[i].Value, results[i].Err = this.Do(ctx, cmd...)
+		}
+		return results, nil
+	}
+
+	encoded := make([][]interface{}, len(cmds))
+	for i, cmd := range cmds {
+		encoded[i] = make([]interface{}, len(cmd))
+		for j, arg := range cmd {
+			encoded[i][j] = encodeArg(arg)
+		}
+	}
+	replies, err := batch.RedisBatchExec(ctx, this.dbName, encoded)
+	if err != nil {
+		return nil, err
+	}
+	for i, reply := range replies {
+		if errors.Is(reply.Err(), ErrNil) {
+			continue
+		}
+		results[i].Value = Decode(reply.Value)
+		results[i].Err = reply.Err()
+	}
+	return results, nil
+}
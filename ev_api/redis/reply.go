@@ -0,0 +1,262 @@
+// redis包提供基于RedisExecCommand的类型化Redis命令封装
+//
+// 基座通过JSON传输Redis回复，为保证二进制安全和RESP3类型不丢失，约定以下标记对象：
+//   - {"$binary": "<base64>"}：非UTF-8的bulk string，解码为[]byte
+//   - {"$map": [[k, v], ...]}：RESP3 map，解码为map[string]interface{}
+//   - {"$set": [...]}：RESP3 set，解码为[]interface{}
+//   - {"$double": "1.5"}：RESP3 double（包括inf、-inf、nan），解码为float64
+//   - {"$error": "msg"}：数组中的错误回复，解码为error
+//
+// 参数中的[]byte或string若不是合法的UTF-8（例如SCAN返回的二进制键），同样以{"$binary": "<base64>"}发送。
+package redis
+
+// 导入所需的包
+import (
+	// Base64编码包
+	"encoding/base64"
+	// 数学包
+	"math"
+	// 字符串转换包
+	"strconv"
+	// 字符串处理包
+	"strings"
+	// UTF-8处理包
+	"unicode/utf8"
+
+	// 视图对象包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/vo"
+	// JSON处理包
+	"github.com/goccy/go-json"
+	// 错误处理包
+	"github.com/pkg/errors"
+	// 类型转换包
+	"github.com/spf13/cast"
+)
+
+// ErrNil 键不存在或回复为nil，与RedisExecCommand返回的vo.ErrRedisNil相同
+var ErrNil = vo.ErrRedisNil
+
+// 标记对象的键
+const (
+	binaryTag = "$binary"
+	mapTag    = "$map"
+	setTag    = "$set"
+	doubleTag = "$double"
+	errorTag  = "$error"
+)
+
+// Decode 解码基座返回的回复，还原标记对象
+// 参数：
+//   - v: JSON解码后的回复
+//
+// 返回：
+//   - interface{}: nil、string、[]byte、int64、float64、bool、error、[]interface{}或map[string]interface{}
+func Decode(v interface{}) interface{} {
+	switch val := v.(type) {
+	case float64:
+		if val == math.Trunc(val) && math.Abs(val) < 1<<63 {
+			return int64(val)
+		}
+		return val
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			return i
+		}
+		f, _ := val.Float64()
+		return f
+	case []interface{}:
+		res := make([]interface{}, len(val))
+		for i := range val {
+			res[i] = Decode(val[i])
+		}
+		return res
+	case map[string]interface{}:
+		if len(val) == 1 {
+			for tag, inner := range val {
+				if res, ok := decodeTag(tag, inner); ok {
+					return res
+				}
+			}
+		}
+		res := make(map[string]interface{}, len(val))
+		for k := range val {
+			res[k] = Decode(val[k])
+		}
+		return res
+	}
+	return v
+}
+
+// decodeTag 还原标记对象
+func decodeTag(tag string, inner interface{}) (interface{}, bool) {
+	switch tag {
+	case binaryTag:
+		s, ok := inner.(string)
+		if !ok {
+			return nil, false
+		}
+		b, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return nil, false
+		}
+		return b, true
+	case mapTag:
+		pairs, ok := inner.([]interface{})
+		if !ok {
+			return nil, false
+		}
+		res := make(map[string]interface{}, len(pairs))
+		for _, pair := range pairs {
+			kv, ok := pair.([]interface{})
+			if !ok || len(kv) != 2 {
+				return nil, false
+			}
+			res[toString(Decode(kv[0]))] = Decode(kv[1])
+		}
+		return res, true
+	case setTag:
+		members, ok := inner.([]interface{})
+		if !ok {
+			return nil, false
+		}
+		return Decode(members), true
+	case doubleTag:
+		switch s := strings.ToLower(cast.ToString(inner)); s {
+		case "inf", "+inf":
+			return math.Inf(1), true
+		case "-inf":
+			return math.Inf(-1), true
+		case "nan":
+			return math.NaN(), true
+		default:
+			f, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return nil, false
+			}
+			return f, true
+		}
+	case errorTag:
+		return errors.New(cast.ToString(inner)), true
+	}
+	return nil, false
+}
+
+// encodeArg 编码命令参数，非UTF-8的[]byte和string以标记对象发送
+// 例如SCAN返回的二进制键经toString转换后仍保留原始字节，再作为参数时需要按二进制发送，
+// 否则JSON编码会把非法字节替换为U+FFFD
+func encodeArg(arg interface{}) interface{} {
+	switch v := arg.(type) {
+	case []byte:
+		if utf8.Valid(v) {
+			return string(v)
+		}
+		return map[string]interface{}{binaryTag: base64.StdEncoding.EncodeToString(v)}
+	case string:
+		if !utf8.ValidString(v) {
+			return map[string]interface{}{binaryTag: base64.StdEncoding.EncodeToString([]byte(v))}
+		}
+	}
+	return arg
+}
+
+// toString 将回复转换为字符串
+func toString(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case []byte:
+		return string(val)
+	case int64:
+		return strconv.FormatInt(val, 10)
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	}
+	return cast.ToString(v)
+}
+
+// toBytes 将回复转换为[]byte
+func toBytes(v interface{}) []byte {
+	if b, ok := v.([]byte); ok {
+		return b
+	}
+	return []byte(toString(v))
+}
+
+// toInt64 将回复转换为int64
+func toInt64(v interface{}) (int64, error) {
+	switch val := v.(type) {
+	case int64:
+		return val, nil
+	case float64:
+		return int64(val), nil
+	case string, []byte:
+		i, err := strconv.ParseInt(toString(val), 10, 64)
+		return i, errors.WithStack(err)
+	case nil:
+		return 0, ErrNil
+	}
+	return 0, errors.Errorf("无法将%T转换为整数", v)
+}
+
+// toFloat64 将回复转换为float64
+func toFloat64(v interface{}) (float64, error) {
+	switch val := v.(type) {
+	case float64:
+		return val, nil
+	case int64:
+		return float64(val), nil
+	case string, []byte:
+		f, err := strconv.ParseFloat(toString(val), 64)
+		return f, errors.WithStack(err)
+	case nil:
+		return 0, ErrNil
+	}
+	return 0, errors.Errorf("无法将%T转换为浮点数", v)
+}
+
+// toSlice 将回复转换为数组
+func toSlice(v interface{}) ([]interface{}, error) {
+	switch val := v.(type) {
+	case []interface{}:
+		return val, nil
+	case nil:
+		return nil, nil
+	}
+	return nil, errors.Errorf("回复不是数组：%T", v)
+}
+
+// toStringSlice 将数组回复转换为[]string
+func toStringSlice(v interface{}) ([]string, error) {
+	items, err := toSlice(v)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]string, 0, len(items))
+	for _, item := range items {
+		res = append(res, toString(item))
+	}
+	return res, nil
+}
+
+// toStringMap 将回复转换为map[string]string，兼容RESP2的[k, v, k, v]数组与RESP3的map
+func toStringMap(v interface{}) (map[string]string, error) {
+	if m, ok := v.(map[string]interface{}); ok {
+		res := make(map[string]string, len(m))
+		for k, val := range m {
+			res[k] = toString(val)
+		}
+		return res, nil
+	}
+	items, err := toSlice(v)
+	if err != nil {
+		return nil, err
+	}
+	if len(items)%2 != 0 {
+		return nil, errors.Errorf("回复的元素个数不是偶数：%d", len(items))
+	}
+	res := make(map[string]string, len(items)/2)
+	for i := 0; i < len(items); i += 2 {
+		res[toString(items[i])] = toString(items[i+1])
+	}
+	return res, nil
+}
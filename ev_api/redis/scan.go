@@ -0,0 +1,200 @@
+// redis包提供基于RedisExecCommand的类型化Redis命令封装
+package redis
+
+// 导入所需的包
+import (
+	// 上下文包
+	"context"
+	// 时间处理包
+	"time"
+
+	// 错误处理包
+	"github.com/pkg/errors"
+)
+
+// ScanOptions SCAN选项
+type ScanOptions struct {
+	// 起始游标，为空或"0"时从头开始，可传入上一页的NextCursor继续
+	Cursor string
+	// 键的匹配模式，例如 user:*，为空时不过滤
+	Match string
+	// 每次SCAN的COUNT提示，小于等于0时为100
+	Count int64
+	// 只返回指定类型的键，例如 string、hash，需要Redis 6.0及以上
+	Type string
+}
+
+// KeyInfo 键的详细信息
+type KeyInfo struct {
+	// 键名
+	Key string `json:"key"`
+	// 类型，键在扫描后被删除时为none
+	Type string `json:"type"`
+	// 剩余过期时间，没有过期时间时为TTLNoExpire，键不存在时为TTLNotExist
+	TTL time.Duration `json:"ttl"`
+	// 内部编码，例如 listpack、hashtable
+	Encoding string `json:"encoding"`
+	// 占用的内存（字节）
+	MemoryUsage int64 `json:"memory_usage"`
+}
+
+// KeyPage 一页键
+type KeyPage struct {
+	// 下一页的游标
+	NextCursor string `json:"next_cursor"`
+	// 是否已扫描完整个键空间
+	Done bool `json:"done"`
+	// 本页的键
+	Keys []KeyInfo `json:"keys"`
+}
+
+// Scan 执行一次SCAN
+// 注意：SCAN可能返回空页或重复的键，调用方应以Done判断是否结束
+// 参数：
+//   - ctx: 上下文
+//   - opts: SCAN选项
+//
+// 返回：
+//   - keys: 本页的键
+//   - nextCursor: 下一页的游标，为"0"时表示扫描结束
+//   - err: 错误信息
+func (this *Client) Scan(ctx context.Context, opts ScanOptions) (keys []string, nextCursor string, err error) {
+	cursor := opts.Cursor
+	if cursor == "" {
+		cursor = "0"
+	}
+	count := opts.Count
+	if count <= 0 {
+		count = 100
+	}
+	args := []interface{}{"SCAN", cursor}
+	if opts.Match != "" {
+		args = append(args, "MATCH", opts.Match)
+	}
+	args = append(args, "COUNT", count)
+	if opts.Type != "" {
+		args = append(args, "TYPE", opts.Type)
+	}
+
+	res, err := this.Do(ctx, args...)
+	if err != nil {
+		return nil, "", err
+	}
+	items, err := toSlice(res)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(items) != 2 {
+		return nil, "", errors.Errorf("SCAN回复格式错误：%v", res)
+	}
+	keys, err = toStringSlice(items[1])
+	if err != nil {
+		return nil, "", err
+	}
+	return keys, toString(items[0]), nil
+}
+
+// ScanPage 执行一次SCAN并补充每个键的类型、TTL、编码和内存占用
+// 参数：
+//   - ctx: 上下文
+//   - opts: SCAN选项
+//
+// 返回：
+//   - *KeyPage: 一页键
+//   - error: 错误信息
+func (this *Client) ScanPage(ctx context.Context, opts ScanOptions) (*KeyPage, error) {
+	keys, nextCursor, err := this.Scan(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	infos, err := this.KeyInfos(ctx, keys...)
+	if err != nil {
+		return nil, err
+	}
+	return &KeyPage{NextCursor: nextCursor, Done: nextCursor == "0", Keys: infos}, nil
+}
+
+// ScanKeys 以迭代器的方式遍历匹配的键，从opts.Cursor开始直到扫描结束
+// ctx被取消时以ctx.Err()结束迭代
+// Go 1.23及以上版本可直接使用 for key, err := range client.ScanKeys(...)
+// 参数：
+//   - ctx: 上下文
+//   - opts: SCAN选项
+//
+// 返回：
+//   - 迭代器函数
+func (this *Client) ScanKeys(ctx context.Context, opts ScanOptions) func(yield func(key string, err error) bool) {
+	return func(yield func(key string, err error) bool) {
+		for {
+			if err := ctx.Err(); err != nil {
+				yield("", err)
+				return
+			}
+			keys, nextCursor, err := this.Scan(ctx, opts)
+			if err != nil {
+				yield("", err)
+				return
+			}
+			for _, key := range keys {
+				if !yield(key, nil) {
+					return
+				}
+			}
+			if nextCursor == "0" {
+				return
+			}
+			opts.Cursor = nextCursor
+		}
+	}
+}
+
+// KeyInfos 通过pipeline批量获取键的类型、TTL、编码和内存占用
+// 参数：
+//   - ctx: 上下文
+//   - keys: 键名
+//
+// 返回：
+//   - []KeyInfo: 与键一一对应的详细信息
+//   - error: 错误信息
+func (this *Client) KeyInfos(ctx context.Context, keys ...string) ([]KeyInfo, error) {
+	const cmdsPerKey = 4
+	cmds := make([][]interface{}, 0, len(keys)*cmdsPerKey)
+	for _, key := range keys {
+		cmds = append(cmds,
+			[]interface{}{"TYPE", key},
+			[]interface{}{"PTTL", key},
+			[]interface{}{"OBJECT", "ENCODING", key},
+			[]interface{}{"MEMORY", "USAGE", key},
+		)
+	}
+	results, err := this.Pipeline(ctx, cmds)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]KeyInfo, len(keys))
+	for i, key := range keys {
+		r := results[i*cmdsPerKey : (i+1)*cmdsPerKey]
+		info := KeyInfo{Key: key, Type: toString(r[0].Value), TTL: TTLNotExist}
+		if ms, err := toInt64(r[1].Value); err == nil {
+			if ms < 0 {
+				info.TTL = time.Duration(ms)
+			} else {
+				info.TTL = time.Duration(ms) * time.Millisecond
+			}
+		}
+		if r[2].Value != nil {
+			info.Encoding = toString(r[2].Value)
+		}
+		if size, err := toInt64(r[3].Value); err == nil {
+			info.MemoryUsage = size
+		}
+		infos[i] = info
+	}
+	return infos, nil
+}
+
+// DbSize 获取当前库的键数量
+func (this *Client) DbSize(ctx context.Context) (int64, error) {
+	return this.doInt64(ctx, "DBSIZE")
+}
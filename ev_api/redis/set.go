@@ -0,0 +1,45 @@
+// redis包提供基于RedisExecCommand的类型化Redis命令封装
+package redis
+
+// 导入所需的包
+import (
+	// 上下文包
+	"context"
+)
+
+// SAdd 向集合添加成员
+// 返回：
+//   - int64: 新增的成员数量
+func (this *Client) SAdd(ctx context.Context, key string, members ...interface{}) (int64, error) {
+	return this.doInt64(ctx, append([]interface{}{"SADD", key}, members...)...)
+}
+
+// SRem 从集合删除成员
+// 返回：
+//   - int64: 删除的成员数量
+func (this *Client) SRem(ctx context.Context, key string, members ...interface{}) (int64, error) {
+	return this.doInt64(ctx, append([]interface{}{"SREM", key}, members...)...)
+}
+
+// SMembers 获取集合的所有成员
+func (this *Client) SMembers(ctx context.Context, key string) ([]string, error) {
+	return this.doStrings(ctx, "SMEMBERS", key)
+}
+
+// SIsMember 判断是否为集合成员
+func (this *Client) SIsMember(ctx context.Context, key string, member interface{}) (bool, error) {
+	n, err := this.doInt64(ctx, "SISMEMBER", key, member)
+	return n == 1, err
+}
+
+// SCard 获取集合的成员数量
+func (this *Client) SCard(ctx context.Context, key string) (int64, error) {
+	return this.doInt64(ctx, "SCARD", key)
+}
+
+// SRandMember 随机获取集合成员
+// 参数：
+//   - count: 获取的数量，为负数时可能重复
+func (this *Client) SRandMember(ctx context.Context, key string, count int64) ([]string, error) {
+	return this.doStrings(ctx, "SRANDMEMBER", key, count)
+}
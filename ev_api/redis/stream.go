@@ -0,0 +1,127 @@
+// redis包提供基于RedisExecCommand的类型化Redis命令封装
+package redis
+
+// 导入所需的包
+import (
+	// 上下文包
+	"context"
+
+	// 错误处理包
+	"github.com/pkg/errors"
+)
+
+// XMessage Stream消息
+type XMessage struct {
+	// 消息ID
+	ID string `json:"id"`
+	// 字段 => 值
+	Values map[string]string `json:"values"`
+}
+
+// XAdd 向Stream追加消息
+// 参数：
+//   - id: 消息ID，为空时使用*由Redis生成
+//   - values: 字段 => 值
+//
+// 返回：
+//   - string: 消息ID
+func (this *Client) XAdd(ctx context.Context, key, id string, values map[string]interface{}) (string, error) {
+	if id == "" {
+		id = "*"
+	}
+	args := []interface{}{"XADD", key, id}
+	for field, v := range values {
+		args = append(args, field, v)
+	}
+	return this.doString(ctx, args...)
+}
+
+// XLen 获取Stream的消息数量
+func (this *Client) XLen(ctx context.Context, key string) (int64, error) {
+	return this.doInt64(ctx, "XLEN", key)
+}
+
+// XRange 按ID升序获取消息
+// 参数：
+//   - start: 起始ID，- 表示最小
+//   - end: 结束ID，+ 表示最大
+//   - count: 返回的数量，小于等于0时不限制
+func (this *Client) XRange(ctx context.Context, key, start, end string, count int64) ([]XMessage, error) {
+	args := []interface{}{"XRANGE", key, start, end}
+	if count > 0 {
+		args = append(args, "COUNT", count)
+	}
+	return this.doXMessages(ctx, args...)
+}
+
+// XRevRange 按ID降序获取消息
+// 参数：
+//   - end: 结束ID，+ 表示最大
+//   - start: 起始ID，- 表示最小
+//   - count: 返回的数量，小于等于0时不限制
+func (this *Client) XRevRange(ctx context.Context, key, end, start string, count int64) ([]XMessage, error) {
+	args := []interface{}{"XREVRANGE", key, end, start}
+	if count > 0 {
+		args = append(args, "COUNT", count)
+	}
+	return this.doXMessages(ctx, args...)
+}
+
+// XDel 删除消息
+// 返回：
+//   - int64: 删除的消息数量
+func (this *Client) XDel(ctx context.Context, key string, ids ...string) (int64, error) {
+	args := []interface{}{"XDEL", key}
+	for _, id := range ids {
+		args = append(args, id)
+	}
+	return this.doInt64(ctx, args...)
+}
+
+// XTrimMaxLen 将Stream裁剪到最多maxLen条消息
+// 参数：
+//   - approx: 是否使用 ~ 近似裁剪，性能更好
+//
+// 返回：
+//   - int64: 删除的消息数量
+func (this *Client) XTrimMaxLen(ctx context.Context, key string, maxLen int64, approx bool) (int64, error) {
+	args := []interface{}{"XTRIM", key, "MAXLEN"}
+	if approx {
+		args = append(args, "~")
+	}
+	return this.doInt64(ctx, append(args, maxLen)...)
+}
+
+// doXMessages 执行返回消息列表的命令
+func (this *Client) doXMessages(ctx context.Context, args ...interface{}) ([]XMessage, error) {
+	res, err := this.Do(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+	return toXMessages(res)
+}
+
+// toXMessages 将[[id, [k, v, ...]], ...]转换为消息列表
+func toXMessages(v interface{}) ([]XMessage, error) {
+	items, err := toSlice(v)
+	if err != nil {
+		return nil, err
+	}
+	msgs := make([]XMessage, 0, len(items))
+	for _, item := range items {
+		// XCLAIM等命令中已被删除的消息为nil
+		if item == nil {
+			continue
+		}
+		pair, ok := item.([]interface{})
+		if !ok || len(pair) != 2 {
+			return nil, errors.Errorf("Stream消息格式错误：%v", item)
+		}
+		values, err := toStringMap(pair[1])
+		if err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, XMessage{ID: toString(pair[0]), Values: values})
+	}
+	return msgs, nil
+}
@@ -0,0 +1,163 @@
+// redis包提供基于RedisExecCommand的类型化Redis命令封装
+package redis
+
+// 导入所需的包
+import (
+	// 上下文包
+	"context"
+	// 字符串处理包
+	"strings"
+	// 时间处理包
+	"time"
+
+	// 错误处理包
+	"github.com/pkg/errors"
+)
+
+// XPendingEntry 消费组中未确认的消息
+type XPendingEntry struct {
+	// 消息ID
+	ID string `json:"id"`
+	// 持有消息的消费者
+	Consumer string `json:"consumer"`
+	// 距离上次投递的时间
+	Idle time.Duration `json:"idle"`
+	// 投递次数
+	Deliveries int64 `json:"deliveries"`
+}
+
+// XGroupCreate 创建消费组，消费组已存在时返回nil
+// 参数：
+//   - stream: Stream键名
+//   - group: 消费组名
+//   - start: 起始ID，$ 表示只消费新消息，0 表示从头消费
+//   - mkStream: Stream不存在时是否自动创建
+func (this *Client) XGroupCreate(ctx context.Context, stream, group, start string, mkStream bool) error {
+	args := []interface{}{"XGROUP", "CREATE", stream, group, start}
+	if mkStream {
+		args = append(args, "MKSTREAM")
+	}
+	err := this.doOK(ctx, args...)
+	if err != nil && strings.Contains(err.Error(), "BUSYGROUP") {
+		return nil
+	}
+	return err
+}
+
+// XGroupDestroy 删除消费组
+func (this *Client) XGroupDestroy(ctx context.Context, stream, group string) (int64, error) {
+	return this.doInt64(ctx, "XGROUP", "DESTROY", stream, group)
+}
+
+// XGroupDelConsumer 从消费组删除消费者，该消费者未确认的消息也会被丢弃
+// 返回：
+//   - int64: 被丢弃的未确认消息数量
+func (this *Client) XGroupDelConsumer(ctx context.Context, stream, group, consumer string) (int64, error) {
+	return this.doInt64(ctx, "XGROUP", "DELCONSUMER", stream, group, consumer)
+}
+
+// XReadGroup 以消费组的方式读取消息
+// 参数：
+//   - stream: Stream键名
+//   - group: 消费组名
+//   - consumer: 消费者名
+//   - id: > 表示读取新消息，0 表示读取本消费者未确认的消息
+//   - count: 最多读取的数量，小于等于0时不限制
+//   - block: 没有消息时的阻塞时间，小于等于0时不阻塞
+//
+// 返回：
+//   - []XMessage: 读取到的消息，超时时为空
+//   - error: 错误信息
+func (this *Client) XReadGroup(ctx context.Context, stream, group, consumer, id string, count int64, block time.Duration) ([]XMessage, error) {
+	args := []interface{}{"XREADGROUP", "GROUP", group, consumer}
+	if count > 0 {
+		args = append(args, "COUNT", count)
+	}
+	if block > 0 {
+		args = append(args, "BLOCK", block.Milliseconds())
+	}
+	args = append(args, "STREAMS", stream, id)
+
+	res, err := this.Do(ctx, args...)
+	if err != nil || res == nil {
+		return nil, err
+	}
+
+	// RESP3返回 {stream: messages}，RESP2返回 [[stream, messages]]
+	if m, ok := res.(map[string]interface{}); ok {
+		return toXMessages(m[stream])
+	}
+	streams, err := toSlice(res)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range streams {
+		pair, ok := item.([]interface{})
+		if !ok || len(pair) != 2 {
+			return nil, errors.Errorf("XREADGROUP回复格式错误：%v", item)
+		}
+		if toString(pair[0]) == stream {
+			return toXMessages(pair[1])
+		}
+	}
+	return nil, nil
+}
+
+// XAck 确认消息
+// 返回：
+//   - int64: 确认成功的消息数量
+func (this *Client) XAck(ctx context.Context, stream, group string, ids ...string) (int64, error) {
+	args := []interface{}{"XACK", stream, group}
+	for _, id := range ids {
+		args = append(args, id)
+	}
+	return this.doInt64(ctx, args...)
+}
+
+// XPending 获取消费组中未确认的消息
+// 参数：
+//   - start: 起始ID，- 表示最小
+//   - end: 结束ID，+ 表示最大
+//   - count: 返回的数量
+//   - consumer: 只返回该消费者的消息，为空时返回所有消费者的消息
+func (this *Client) XPending(ctx context.Context, stream, group, start, end string, count int64, consumer string) ([]XPendingEntry, error) {
+	args := []interface{}{"XPENDING", stream, group, start, end, count}
+	if consumer != "" {
+		args = append(args, consumer)
+	}
+	res, err := this.Do(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+	items, err := toSlice(res)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]XPendingEntry, 0, len(items))
+	for _, item := range items {
+		fields, ok := item.([]interface{})
+		if !ok || len(fields) != 4 {
+			return nil, errors.Errorf("XPENDING回复格式错误：%v", item)
+		}
+		idle, _ := toInt64(fields[2])
+		deliveries, _ := toInt64(fields[3])
+		entries = append(entries, XPendingEntry{
+			ID:         toString(fields[0]),
+			Consumer:   toString(fields[1]),
+			Idle:       time.Duration(idle) * time.Millisecond,
+			Deliveries: deliveries,
+		})
+	}
+	return entries, nil
+}
+
+// XClaim 将空闲时间超过minIdle的未确认消息转移给consumer
+// 返回：
+//   - []XMessage: 转移成功的消息，已被删除的消息不会返回
+func (this *Client) XClaim(ctx context.Context, stream, group, consumer string, minIdle time.Duration, ids ...string) ([]XMessage, error) {
+	args := []interface{}{"XCLAIM", stream, group, consumer, minIdle.Milliseconds()}
+	for _, id := range ids {
+		args = append(args, id)
+	}
+	return this.doXMessages(ctx, args...)
+}
@@ -0,0 +1,120 @@
+// redis包提供基于RedisExecCommand的类型化Redis命令封装
+package redis
+
+// 导入所需的包
+import (
+	// 上下文包
+	"context"
+	// 时间处理包
+	"time"
+)
+
+// Get 获取字符串值，键不存在时返回ErrNil
+func (this *Client) Get(ctx context.Context, key string) (string, error) {
+	return this.doString(ctx, "GET", key)
+}
+
+// GetBytes 以二进制安全的方式获取值，键不存在时返回ErrNil
+func (this *Client) GetBytes(ctx context.Context, key string) ([]byte, error) {
+	res, err := this.Do(ctx, "GET", key)
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return nil, ErrNil
+	}
+	return toBytes(res), nil
+}
+
+// Set 设置值
+// 参数：
+//   - value: 值，[]byte按二进制安全的方式发送
+//   - ttl: 过期时间，为0时不过期
+func (this *Client) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	args := []interface{}{"SET", key, value}
+	if ttl > 0 {
+		args = append(args, "PX", ttl.Milliseconds())
+	}
+	return this.doOK(ctx, args...)
+}
+
+// SetNX 键不存在时设置值
+// 返回：
+//   - bool: 是否设置成功
+func (this *Client) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	args := []interface{}{"SET", key, value, "NX"}
+	if ttl > 0 {
+		args = append(args, "PX", ttl.Milliseconds())
+	}
+	res, err := this.Do(ctx, args...)
+	if err != nil {
+		return false, err
+	}
+	return res != nil, nil
+}
+
+// MGet 批量获取值
+// 返回：
+//   - []*string: 与键一一对应，键不存在时为nil
+func (this *Client) MGet(ctx context.Context, keys ...string) ([]*string, error) {
+	res, err := this.Do(ctx, withKeys("MGET", keys)...)
+	if err != nil {
+		return nil, err
+	}
+	items, err := toSlice(res)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]*string, len(items))
+	for i, item := range items {
+		if item != nil {
+			s := toString(item)
+			values[i] = &s
+		}
+	}
+	return values, nil
+}
+
+// MSet 批量设置值
+// 参数：
+//   - values: 键 => 值
+func (this *Client) MSet(ctx context.Context, values map[string]interface{}) error {
+	args := []interface{}{"MSET"}
+	for k, v := range values {
+		args = append(args, k, v)
+	}
+	return this.doOK(ctx, args...)
+}
+
+// Incr 将值加1
+// 返回：
+//   - int64: 增加后的值
+func (this *Client) Incr(ctx context.Context, key string) (int64, error) {
+	return this.doInt64(ctx, "INCR", key)
+}
+
+// IncrBy 将值增加指定整数
+// 返回：
+//   - int64: 增加后的值
+func (this *Client) IncrBy(ctx context.Context, key string, increment int64) (int64, error) {
+	return this.doInt64(ctx, "INCRBY", key, increment)
+}
+
+// IncrByFloat 将值增加指定浮点数
+// 返回：
+//   - float64: 增加后的值
+func (this *Client) IncrByFloat(ctx context.Context, key string, increment float64) (float64, error) {
+	return this.doFloat64(ctx, "INCRBYFLOAT", key, increment)
+}
+
+// Append 追加字符串
+// 返回：
+//   - int64: 追加后的长度
+func (this *Client) Append(ctx context.Context, key string, value interface{}) (int64, error) {
+	return this.doInt64(ctx, "APPEND", key, value)
+}
+
+// StrLen 获取字符串长度
+func (this *Client) StrLen(ctx context.Context, key string) (int64, error) {
+	return this.doInt64(ctx, "STRLEN", key)
+}
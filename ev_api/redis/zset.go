@@ -0,0 +1,125 @@
+// redis包提供基于RedisExecCommand的类型化Redis命令封装
+package redis
+
+// 导入所需的包
+import (
+	// 上下文包
+	"context"
+
+	// 错误处理包
+	"github.com/pkg/errors"
+)
+
+// Z 有序集合成员
+type Z struct {
+	// 成员
+	Member string `json:"member"`
+	// 分数
+	Score float64 `json:"score"`
+}
+
+// ZAdd 向有序集合添加成员
+// 返回：
+//   - int64: 新增的成员数量
+func (this *Client) ZAdd(ctx context.Context, key string, members ...Z) (int64, error) {
+	args := []interface{}{"ZADD", key}
+	for _, z := range members {
+		args = append(args, z.Score, z.Member)
+	}
+	return this.doInt64(ctx, args...)
+}
+
+// ZRem 从有序集合删除成员
+// 返回：
+//   - int64: 删除的成员数量
+func (this *Client) ZRem(ctx context.Context, key string, members ...interface{}) (int64, error) {
+	return this.doInt64(ctx, append([]interface{}{"ZREM", key}, members...)...)
+}
+
+// ZCard 获取有序集合的成员数量
+func (this *Client) ZCard(ctx context.Context, key string) (int64, error) {
+	return this.doInt64(ctx, "ZCARD", key)
+}
+
+// ZScore 获取成员的分数，成员不存在时返回ErrNil
+func (this *Client) ZScore(ctx context.Context, key, member string) (float64, error) {
+	return this.doFloat64(ctx, "ZSCORE", key, member)
+}
+
+// ZIncrBy 将成员的分数增加指定值
+// 返回：
+//   - float64: 增加后的分数
+func (this *Client) ZIncrBy(ctx context.Context, key string, increment float64, member string) (float64, error) {
+	return this.doFloat64(ctx, "ZINCRBY", key, increment, member)
+}
+
+// ZRange 按分数升序获取指定下标范围的成员
+func (this *Client) ZRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
+	return this.doStrings(ctx, "ZRANGE", key, start, stop)
+}
+
+// ZRangeWithScores 按分数升序获取指定下标范围的成员及分数
+func (this *Client) ZRangeWithScores(ctx context.Context, key string, start, stop int64) ([]Z, error) {
+	return this.doZ(ctx, "ZRANGE", key, start, stop, "WITHSCORES")
+}
+
+// ZRevRangeWithScores 按分数降序获取指定下标范围的成员及分数
+func (this *Client) ZRevRangeWithScores(ctx context.Context, key string, start, stop int64) ([]Z, error) {
+	return this.doZ(ctx, "ZREVRANGE", key, start, stop, "WITHSCORES")
+}
+
+// ZRangeByScore 按分数范围获取成员及分数
+// 参数：
+//   - min: 最小分数，例如 "-inf"、"(1.5"
+//   - max: 最大分数，例如 "+inf"、"10"
+//   - offset: 跳过的数量
+//   - count: 返回的数量，小于等于0时不限制
+func (this *Client) ZRangeByScore(ctx context.Context, key, min, max string, offset, count int64) ([]Z, error) {
+	args := []interface{}{"ZRANGEBYSCORE", key, min, max, "WITHSCORES"}
+	if count > 0 {
+		args = append(args, "LIMIT", offset, count)
+	}
+	return this.doZ(ctx, args...)
+}
+
+// doZ 执行带WITHSCORES的命令，兼容RESP2的[member, score, ...]与RESP3的[[member, score], ...]
+func (this *Client) doZ(ctx context.Context, args ...interface{}) ([]Z, error) {
+	res, err := this.Do(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+	items, err := toSlice(res)
+	if err != nil {
+		return nil, err
+	}
+
+	zs := make([]Z, 0, len(items)/2)
+	if len(items) > 0 {
+		if _, nested := items[0].([]interface{}); nested {
+			for _, item := range items {
+				pair, ok := item.([]interface{})
+				if !ok || len(pair) != 2 {
+					return nil, errors.Errorf("有序集合回复格式错误：%v", item)
+				}
+				score, err := toFloat64(pair[1])
+				if err != nil {
+					return nil, err
+				}
+				zs = append(zs, Z{Member: toString(pair[0]), Score: score})
+			}
+			return zs, nil
+		}
+	}
+
+	if len(items)%2 != 0 {
+		return nil, errors.Errorf("有序集合回复的元素个数不是偶数：%d", len(items))
+	}
+	for i := 0; i < len(items); i += 2 {
+		score, err := toFloat64(items[i+1])
+		if err != nil {
+			return nil, err
+		}
+		zs = append(zs, Z{Member: toString(items[i]), Score: score})
+	}
+	return zs, nil
+}
@@ -0,0 +1,42 @@
+// ev_api包提供EVE API的接口和实现
+//
+// redis_api.go 文件提供基于EvApiAdapter的类型化Redis客户端与Stream消费者。
+package ev_api
+
+// 导入所需的包
+import (
+	// 类型化Redis命令包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/redis"
+)
+
+// RedisClient 创建类型化Redis客户端
+// 参数：
+//   - dbName: 数据库编号
+//
+// 返回：
+//   - *redis.Client: Redis客户端
+func (this *EvApiAdapter) RedisClient(dbName int) *redis.Client {
+	return redis.NewClient(this, dbName)
+}
+
+// RedisStreamConsumer 创建Redis Stream消费组的消费者
+// 示例用法：
+//
+//	ReadyCallBack: func(ctx context.Context) {
+//		consumer := ev_api.NewEvWrapApi(connId, userId).RedisStreamConsumer(0, redis.ConsumerOptions{
+//			Stream: "orders", Group: "my-plugin", Consumer: "worker-1",
+//		})
+//		go consumer.Run(ctx, func(ctx context.Context, msg redis.XMessage) error {
+//			return handleOrder(msg.Values)
+//		})
+//	}
+//
+// 参数：
+//   - dbName: 数据库编号
+//   - opts: 消费者选项
+//
+// 返回：
+//   - *redis.Consumer: 消费者，调用Run开始消费
+func (this *EvApiAdapter) RedisStreamConsumer(dbName int, opts redis.ConsumerOptions) *redis.Consumer {
+	return redis.NewConsumer(this.RedisClient(dbName), opts)
+}
@@ -0,0 +1,85 @@
+// scan包提供将查询结果扫描到Go结构体的功能
+package scan
+
+// 导入所需的包
+import (
+	// 反射包
+	"reflect"
+	// 字符串处理包
+	"strings"
+	// 并发控制包
+	"sync"
+	// Unicode处理包
+	"unicode"
+)
+
+// TagName 结构体字段标签名
+const TagName = "db"
+
+// fieldCache 结构体类型 => 列名到字段索引的映射
+var fieldCache sync.Map
+
+// fieldsOf 返回结构体的 列名 => 字段索引，嵌入的匿名结构体会被展开
+// 字段名优先取`db`标签，`db:"-"`表示忽略，未设置标签时使用字段名的蛇形命名
+func fieldsOf(t reflect.Type) map[string][]int {
+	if cached, ok := fieldCache.Load(t); ok {
+		return cached.(map[string][]int)
+	}
+	fields := map[string][]int{}
+	collectFields(t, nil, fields)
+	fieldCache.Store(t, fields)
+	return fields
+}
+
+// collectFields 递归收集字段
+func collectFields(t reflect.Type, parent []int, fields map[string][]int) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get(TagName)
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+
+		index := make([]int, len(parent)+1)
+		copy(index, parent)
+		index[len(parent)] = i
+
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct && ft != timeType {
+			collectFields(ft, index, fields)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = SnakeCase(f.Name)
+		}
+		// 外层字段优先于嵌入结构体中的同名字段
+		if exist, ok := fields[name]; ok && len(exist) <= len(index) {
+			continue
+		}
+		fields[name] = index
+	}
+}
+
+// SnakeCase 将驼峰命名转换为蛇形命名，例如 UserID => user_id，CreatedAt => created_at
+func SnakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+				b.WriteByte('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,523 @@
+// scan包提供将查询结果扫描到Go结构体的功能
+//
+// 基座返回的行数据经过一次JSON传输，数字会变成float64或字符串，时间会变成字符串。
+// 本包根据基座返回的列类型元数据还原每一列的值，再按`db`标签赋值到结构体字段，
+// 支持time.Time、sql.Null*等sql.Scanner实现、指针（表示NULL）、DECIMAL和[]byte。
+//
+// 示例用法:
+//
+//	type User struct {
+//		Id        int64          `db:"id"`
+//		Name      string         `db:"name"`
+//		Nickname  sql.NullString `db:"nickname"`
+//		Balance   string         `db:"balance"` // DECIMAL按字符串保留精度
+//		CreatedAt time.Time      `db:"created_at"`
+//		DeletedAt *time.Time     `db:"deleted_at"`
+//	}
+package scan
+
+// 导入所需的包
+import (
+	// 数据库接口包
+	"database/sql"
+	// 编码接口包
+	"encoding"
+	// Base64编码包
+	"encoding/base64"
+	// 格式化包
+	"fmt"
+	// 反射包
+	"reflect"
+	// 字符串转换包
+	"strconv"
+	// 字符串处理包
+	"strings"
+	// 时间处理包
+	"time"
+
+	// 视图对象包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/vo"
+	// JSON处理包
+	"github.com/goccy/go-json"
+	// 错误处理包
+	"github.com/pkg/errors"
+)
+
+// ErrNoRows 查询结果为空
+var ErrNoRows = sql.ErrNoRows
+
+// TimeLayouts 解析时间字符串时尝试的格式
+var TimeLayouts = []string{
+	"2006-01-02 15:04:05.999999999",
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// TimeLocation 解析不带时区的时间字符串时使用的时区
+var TimeLocation = time.Local
+
+// Scan 将多行数据扫描为[]T
+// T可以是结构体、结构体指针或map[string]interface{}
+// 参数：
+//   - rows: 行数据
+//   - columnTypes: 列类型元数据，可以为空
+//
+// 返回：
+//   - []T: 扫描结果
+//   - error: 错误信息
+func Scan[T any](rows []map[string]interface{}, columnTypes []vo.ColumnType) ([]T, error) {
+	types := typesByName(columnTypes)
+	res := make([]T, 0, len(rows))
+	for i, row := range rows {
+		var dest T
+		if err := scanRow(reflect.ValueOf(&dest).Elem(), row, types); err != nil {
+			return nil, errors.Wrapf(err, "第%d行", i+1)
+		}
+		res = append(res, dest)
+	}
+	return res, nil
+}
+
+// ScanOne 将单行数据扫描为T，row为空时返回ErrNoRows
+// 参数：
+//   - row: 行数据
+//   - columnTypes: 列类型元数据，可以为空
+//
+// 返回：
+//   - T: 扫描结果
+//   - error: 错误信息
+func ScanOne[T any](row map[string]interface{}, columnTypes []vo.ColumnType) (T, error) {
+	var dest T
+	if len(row) == 0 {
+		return dest, ErrNoRows
+	}
+	err := scanRow(reflect.ValueOf(&dest).Elem(), row, typesByName(columnTypes))
+	return dest, err
+}
+
+// ScanInto 将多行数据扫描到dest，dest必须是切片指针，元素类型规则与Scan一致
+// 参数：
+//   - dest: 切片指针，例如 *[]User
+//   - rows: 行数据
+//   - columnTypes: 列类型元数据，可以为空
+//
+// 返回：
+//   - error: 错误信息
+func ScanInto(dest interface{}, rows []map[string]interface{}, columnTypes []vo.ColumnType) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Slice {
+		return errors.Errorf("扫描目标必须是非nil的切片指针，实际为%T", dest)
+	}
+	types := typesByName(columnTypes)
+	slice := reflect.MakeSlice(v.Elem().Type(), len(rows), len(rows))
+	for i, row := range rows {
+		if err := scanRow(slice.Index(i), row, types); err != nil {
+			return errors.Wrapf(err, "第%d行", i+1)
+		}
+	}
+	v.Elem().Set(slice)
+	return nil
+}
+
+// ScanOneInto 将单行数据扫描到dest，dest必须是指针，row为空时返回ErrNoRows
+// 参数：
+//   - dest: 指针，例如 *User
+//   - row: 行数据
+//   - columnTypes: 列类型元数据，可以为空
+//
+// 返回：
+//   - error: 错误信息
+func ScanOneInto(dest interface{}, row map[string]interface{}, columnTypes []vo.ColumnType) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return errors.Errorf("扫描目标必须是非nil的指针，实际为%T", dest)
+	}
+	if len(row) == 0 {
+		return ErrNoRows
+	}
+	return scanRow(v.Elem(), row, typesByName(columnTypes))
+}
+
+// typesByName 将列类型元数据转换为 列名 => 数据库类型
+func typesByName(columnTypes []vo.ColumnType) map[string]string {
+	types := make(map[string]string, len(columnTypes))
+	for _, ct := range columnTypes {
+		types[ct.Name] = strings.ToUpper(ct.DatabaseType)
+	}
+	return types
+}
+
+// scanRow 扫描一行到dest
+func scanRow(dest reflect.Value, row map[string]interface{}, types map[string]string) error {
+	for dest.Kind() == reflect.Ptr {
+		if dest.IsNil() {
+			dest.Set(reflect.New(dest.Type().Elem()))
+		}
+		dest = dest.Elem()
+	}
+
+	switch dest.Kind() {
+	case reflect.Map:
+		if dest.Type().Key().Kind() != reflect.String {
+			return errors.Errorf("不支持的扫描目标类型：%s", dest.Type())
+		}
+		if dest.IsNil() {
+			dest.Set(reflect.MakeMapWithSize(dest.Type(), len(row)))
+		}
+		for col, v := range row {
+			elem := reflect.New(dest.Type().Elem()).Elem()
+			if err := assign(elem, Normalize(v, types[col])); err != nil {
+				return errors.Wrapf(err, "列%s", col)
+			}
+			dest.SetMapIndex(reflect.ValueOf(col).Convert(dest.Type().Key()), elem)
+		}
+		return nil
+	case reflect.Struct:
+		if isScalarStruct(dest) {
+			break
+		}
+		for col, index := range fieldsOf(dest.Type()) {
+			v, ok := row[col]
+			if !ok {
+				continue
+			}
+			field, err := fieldByIndex(dest, index)
+			if err != nil {
+				return err
+			}
+			if err = assign(field, Normalize(v, types[col])); err != nil {
+				return errors.Wrapf(err, "列%s", col)
+			}
+		}
+		return nil
+	}
+
+	// 单列查询可以直接扫描到基础类型
+	if len(row) != 1 {
+		return errors.Errorf("扫描到%s时结果必须只有一列，实际有%d列", dest.Type(), len(row))
+	}
+	for col, v := range row {
+		return assign(dest, Normalize(v, types[col]))
+	}
+	return nil
+}
+
+// isScalarStruct 判断结构体是否应作为单个值处理，例如time.Time、sql.NullString
+func isScalarStruct(v reflect.Value) bool {
+	if v.Type() == timeType {
+		return true
+	}
+	_, ok := v.Addr().Interface().(sql.Scanner)
+	return ok
+}
+
+// fieldByIndex 按索引获取字段，必要时初始化嵌入的结构体指针
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, errors.Errorf("无法初始化嵌入字段：%s", v.Type())
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, nil
+}
+
+// Normalize 根据数据库类型还原经过JSON传输的值
+// 整数类型还原为int64/uint64，浮点类型还原为float64，DECIMAL保留为字符串，
+// 日期时间类型还原为time.Time，BLOB、BINARY、VARBINARY按base64解码为[]byte，未知类型的数字尽量还原为int64
+// 参数：
+//   - v: JSON解码后的值
+//   - dbType: 数据库类型名称（大写），未知时为空
+//
+// 返回：
+//   - interface{}: database/sql/driver.Value兼容的值
+func Normalize(v interface{}, dbType string) interface{} {
+	if v == nil {
+		return nil
+	}
+
+	unsigned := strings.HasPrefix(dbType, "UNSIGNED ")
+	baseType := strings.TrimPrefix(dbType, "UNSIGNED ")
+	if i := strings.IndexByte(baseType, '('); i > 0 {
+		baseType = baseType[:i]
+	}
+
+	switch baseType {
+	case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "INTEGER", "BIGINT", "YEAR":
+		s := numberString(v)
+		if unsigned {
+			if u, err := strconv.ParseUint(s, 10, 64); err == nil {
+				return u
+			}
+		}
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return i
+		}
+	case "FLOAT", "DOUBLE", "REAL":
+		if f, err := strconv.ParseFloat(numberString(v), 64); err == nil {
+			return f
+		}
+	case "DECIMAL", "NUMERIC":
+		return numberString(v)
+	case "DATETIME", "TIMESTAMP", "DATE":
+		if s, ok := v.(string); ok {
+			if t, err := parseTime(s); err == nil {
+				return t
+			}
+		}
+	case "BLOB", "TINYBLOB", "MEDIUMBLOB", "LONGBLOB", "BINARY", "VARBINARY":
+		// 只有二进制列由基座按base64编码传输，其余列即使内容恰好是合法的base64也不解码
+		if s, ok := v.(string); ok {
+			if b, err := base64.StdEncoding.DecodeString(s); err == nil {
+				return b
+			}
+			return []byte(s)
+		}
+	case "BIT", "GEOMETRY":
+		if s, ok := v.(string); ok {
+			return []byte(s)
+		}
+	case "":
+		switch val := v.(type) {
+		case json.Number:
+			if i, err := val.Int64(); err == nil {
+				return i
+			}
+			if f, err := val.Float64(); err == nil {
+				return f
+			}
+			return val.String()
+		case float64:
+			if val == float64(int64(val)) {
+				return int64(val)
+			}
+			return val
+		}
+	}
+
+	switch val := v.(type) {
+	case json.Number:
+		return val.String()
+	case map[string]interface{}, []interface{}:
+		b, _ := json.Marshal(val)
+		return string(b)
+	}
+	return v
+}
+
+// numberString 将数字或字符串转换为不丢失精度的字符串
+func numberString(v interface{}) string {
+	switch val := v.(type) {
+	case json.Number:
+		return val.String()
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case bool:
+		if val {
+			return "1"
+		}
+		return "0"
+	}
+	return fmt.Sprint(v)
+}
+
+// parseTime 按TimeLayouts解析时间字符串
+func parseTime(s string) (time.Time, error) {
+	for _, layout := range TimeLayouts {
+		if t, err := time.ParseInLocation(layout, s, TimeLocation); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.Errorf("无法解析时间：%s", s)
+}
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	bytesType           = reflect.TypeOf([]byte(nil))
+	scannerType         = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// assign 将还原后的值赋给dest
+func assign(dest reflect.Value, src interface{}) error {
+	if reflect.PtrTo(dest.Type()).Implements(scannerType) {
+		return dest.Addr().Interface().(sql.Scanner).Scan(src)
+	}
+
+	if dest.Kind() == reflect.Ptr {
+		if src == nil {
+			dest.Set(reflect.Zero(dest.Type()))
+			return nil
+		}
+		elem := reflect.New(dest.Type().Elem())
+		if err := assign(elem.Elem(), src); err != nil {
+			return err
+		}
+		dest.Set(elem)
+		return nil
+	}
+
+	if src == nil {
+		dest.Set(reflect.Zero(dest.Type()))
+		return nil
+	}
+
+	if dest.Kind() == reflect.Interface {
+		dest.Set(reflect.ValueOf(src))
+		return nil
+	}
+
+	if dest.Type() == timeType {
+		return assignTime(dest, src)
+	}
+
+	if dest.Type() == bytesType {
+		switch val := src.(type) {
+		case []byte:
+			dest.SetBytes(append([]byte(nil), val...))
+		case string:
+			dest.SetBytes([]byte(val))
+		default:
+			dest.SetBytes([]byte(asString(val)))
+		}
+		return nil
+	}
+
+	if reflect.PtrTo(dest.Type()).Implements(textUnmarshalerType) {
+		if s, ok := src.(string); ok {
+			return dest.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+		}
+	}
+
+	switch dest.Kind() {
+	case reflect.String:
+		dest.SetString(asString(src))
+		return nil
+	case reflect.Bool:
+		b, err := asBool(src)
+		if err != nil {
+			return err
+		}
+		dest.SetBool(b)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(integerString(src), 10, 64)
+		if err != nil {
+			return errors.Errorf("无法将%v转换为%s", src, dest.Type())
+		}
+		if dest.OverflowInt(i) {
+			return errors.Errorf("%d超出%s的范围", i, dest.Type())
+		}
+		dest.SetInt(i)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(integerString(src), 10, 64)
+		if err != nil {
+			return errors.Errorf("无法将%v转换为%s", src, dest.Type())
+		}
+		if dest.OverflowUint(u) {
+			return errors.Errorf("%d超出%s的范围", u, dest.Type())
+		}
+		dest.SetUint(u)
+		return nil
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(asString(src), 64)
+		if err != nil {
+			return errors.Errorf("无法将%v转换为%s", src, dest.Type())
+		}
+		dest.SetFloat(f)
+		return nil
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		// JSON列解码到复合类型
+		var b []byte
+		switch val := src.(type) {
+		case string:
+			b = []byte(val)
+		case []byte:
+			b = val
+		default:
+			return errors.Errorf("无法将%T转换为%s", src, dest.Type())
+		}
+		return errors.WithStack(json.Unmarshal(b, dest.Addr().Interface()))
+	}
+
+	return errors.Errorf("不支持的字段类型：%s", dest.Type())
+}
+
+// assignTime 赋值time.Time
+func assignTime(dest reflect.Value, src interface{}) error {
+	switch val := src.(type) {
+	case time.Time:
+		dest.Set(reflect.ValueOf(val))
+	case string:
+		t, err := parseTime(val)
+		if err != nil {
+			return err
+		}
+		dest.Set(reflect.ValueOf(t))
+	case []byte:
+		t, err := parseTime(string(val))
+		if err != nil {
+			return err
+		}
+		dest.Set(reflect.ValueOf(t))
+	case int64:
+		dest.Set(reflect.ValueOf(time.Unix(val, 0)))
+	default:
+		return errors.Errorf("无法将%T转换为time.Time", src)
+	}
+	return nil
+}
+
+// asString 将值转换为字符串
+func asString(src interface{}) string {
+	switch val := src.(type) {
+	case string:
+		return val
+	case []byte:
+		return string(val)
+	case time.Time:
+		return val.Format("2006-01-02 15:04:05")
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	}
+	return numberString(src)
+}
+
+// integerString 将值转换为整数字符串，允许小数部分为0的浮点数
+func integerString(src interface{}) string {
+	s := asString(src)
+	if i := strings.IndexByte(s, '.'); i >= 0 && strings.Trim(s[i+1:], "0") == "" {
+		return s[:i]
+	}
+	return s
+}
+
+// asBool 将值转换为布尔值
+func asBool(src interface{}) (bool, error) {
+	switch val := src.(type) {
+	case bool:
+		return val, nil
+	case int64:
+		return val != 0, nil
+	case uint64:
+		return val != 0, nil
+	case float64:
+		return val != 0, nil
+	case []byte:
+		if len(val) == 1 && val[0] <= 1 {
+			return val[0] == 1, nil
+		}
+		return strconv.ParseBool(string(val))
+	}
+	return strconv.ParseBool(asString(src))
+}
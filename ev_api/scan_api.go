@@ -0,0 +1,95 @@
+// ev_api包提供EVE API的接口和实现
+//
+// scan_api.go 文件提供将MySQL数据源和插件存储的查询结果扫描到结构体的泛型方法，
+// 字段映射规则见scan包。
+package ev_api
+
+// 导入所需的包
+import (
+	// 上下文包
+	"context"
+
+	// 数据传输对象包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/dto"
+	// 结构体扫描包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/scan"
+)
+
+// ScanMysqlSelect 执行MySQL查询语句并扫描为[]T
+// 参数：
+//   - ctx: 上下文
+//   - api: 数据源适配器
+//   - dbName: 数据库名称
+//   - sql: SQL语句
+//   - args: SQL参数
+//
+// 返回：
+//   - []T: 查询结果
+//   - error: 错误信息
+func ScanMysqlSelect[T any](ctx context.Context, api *EvApiAdapter, dbName, sql string, args ...interface{}) ([]T, error) {
+	res, err := api.MysqlSelectRows(ctx, dbName, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	return scan.Scan[T](res.Result, res.ColumnTypes)
+}
+
+// ScanMysqlFirst 执行MySQL查询并将第一条记录扫描为T，没有记录时返回scan.ErrNoRows
+// 参数：
+//   - ctx: 上下文
+//   - api: 数据源适配器
+//   - dbName: 数据库名称
+//   - sql: SQL语句
+//   - args: SQL参数
+//
+// 返回：
+//   - T: 查询结果
+//   - error: 错误信息
+func ScanMysqlFirst[T any](ctx context.Context, api *EvApiAdapter, dbName, sql string, args ...interface{}) (T, error) {
+	res, err := GetEvApi().MysqlFirstRow(ctx, &dto.MysqlSelectReq{
+		EsConnectData: api.buildEsConnectData(),
+		DbName:        dbName,
+		Sql:           sql,
+		Args:          args,
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return scan.ScanOne[T](res.Result, res.ColumnTypes)
+}
+
+// ScanStoreSelect 查询插件存储并扫描为[]T
+// 参数：
+//   - ctx: 上下文
+//   - sql: SQL语句
+//   - args: SQL参数
+//
+// 返回：
+//   - []T: 查询结果
+//   - error: 错误信息
+func ScanStoreSelect[T any](ctx context.Context, sql string, args ...interface{}) ([]T, error) {
+	res, err := GetEvApi().StoreSelectRows(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	return scan.Scan[T](res.Result, res.ColumnTypes)
+}
+
+// ScanStoreFirst 查询插件存储并将第一条记录扫描为T，没有记录时返回scan.ErrNoRows
+// 参数：
+//   - ctx: 上下文
+//   - sql: SQL语句
+//   - args: SQL参数
+//
+// 返回：
+//   - T: 查询结果
+//   - error: 错误信息
+func ScanStoreFirst[T any](ctx context.Context, sql string, args ...interface{}) (T, error) {
+	res, err := GetEvApi().StoreFirstRow(ctx, sql, args...)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return scan.ScanOne[T](res.Result, res.ColumnTypes)
+}
@@ -0,0 +1,219 @@
+// ev_api包提供EVE API的接口和实现
+//
+// store_tx.go 文件提供插件存储的事务，事务内的读写都在基座固定的同一个连接上执行。
+package ev_api
+
+// 导入所需的包
+import (
+	// 上下文包
+	"context"
+	// 并发控制包
+	"sync"
+	// 时间处理包
+	"time"
+
+	// 视图对象包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/vo"
+	// 错误处理包
+	"github.com/pkg/errors"
+)
+
+// StoreTxIdleTimeout 插件存储事务的空闲超时时间，超时未操作时基座自动回滚
+var StoreTxIdleTimeout = 60 * time.Second
+
+// StoreTx 插件存储事务接口，方法与同名的Store方法一致
+type StoreTx interface {
+	// TxId 返回事务ID
+	TxId() string
+	// Exec 在事务内执行SQL语句
+	Exec(ctx context.Context, sql string, args ...interface{}) (rowsAffected int64, err error)
+	// Select 在事务内查询，结果写入dest
+	Select(ctx context.Context, dest interface{}, sql string, args ...interface{}) error
+	// SelectRows 在事务内查询，返回结果及列类型元数据
+	SelectRows(ctx context.Context, sql string, args ...interface{}) (*vo.StoreRowsRes, error)
+	// First 在事务内查询第一条记录，结果写入dest
+	First(ctx context.Context, dest interface{}, sql string, args ...interface{}) error
+	// Save 在事务内保存数据
+	Save(ctx context.Context, table string, data interface{}) error
+	// Update 在事务内更新记录
+	Update(ctx context.Context, table string, updateData map[string]interface{}, whereSql string, whereArgs ...interface{}) (rowsAffected int64, err error)
+	// Delete 在事务内删除记录
+	Delete(ctx context.Context, table, whereSql string, whereArgs ...interface{}) (rowsAffected int64, err error)
+	// InsertOrUpdate 在事务内插入或更新记录
+	InsertOrUpdate(ctx context.Context, table string, upsertData map[string]interface{}, uniqueKeys ...string) error
+	// Commit 提交事务
+	Commit(ctx context.Context) error
+	// Rollback 回滚事务，事务已结束时返回ErrTxDone
+	Rollback(ctx context.Context) error
+}
+
+// storeTx 插件存储事务实现
+type storeTx struct {
+	api     *evApi
+	txId    string
+	lock    sync.Mutex
+	done    bool
+	closeCh chan struct{}
+}
+
+// StoreBeginTx 开启插件存储事务，一般使用StoreTx代替
+// 插件传入的ctx被取消时，若事务尚未结束则自动回滚
+// 参数：
+//   - ctx: 上下文，决定事务的生命周期
+//
+// 返回：
+//   - StoreTx: 事务
+//   - err: 错误信息
+func (this *evApi) StoreBeginTx(ctx context.Context) (StoreTx, error) {
+	txId, err := this.storeBeginTx(ctx, int(StoreTxIdleTimeout.Seconds()))
+	if err != nil {
+		return nil, err
+	}
+	tx := &storeTx{api: this, txId: txId, closeCh: make(chan struct{})}
+	go tx.watch(ctx)
+	return tx, nil
+}
+
+// StoreTx 在插件存储事务内执行fn，fn返回nil时提交，返回错误或panic时回滚
+// fn内不要调用Commit和Rollback，panic会在回滚后继续抛出
+// 参数：
+//   - ctx: 上下文
+//   - fn: 事务内执行的函数
+//
+// 返回：
+//   - err: fn返回的错误或提交失败的错误
+func (this *evApi) StoreTx(ctx context.Context, fn func(tx StoreTx) error) (err error) {
+	tx, err := this.StoreBeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback(context.Background())
+			panic(p)
+		}
+	}()
+	if err = fn(tx); err != nil {
+		if rerr := tx.Rollback(context.Background()); rerr != nil && rerr != ErrTxDone {
+			return errors.WithMessagef(err, "回滚失败: %v", rerr)
+		}
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// watch 监听ctx，取消时自动回滚
+func (this *storeTx) watch(ctx context.Context) {
+	select {
+	case <-ctx.Done():
+		this.Rollback(context.Background())
+	case <-this.closeCh:
+	}
+}
+
+// TxId 返回事务ID
+func (this *storeTx) TxId() string {
+	return this.txId
+}
+
+// Exec 在事务内执行SQL语句
+func (this *storeTx) Exec(ctx context.Context, sql string, args ...interface{}) (rowsAffected int64, err error) {
+	if err = this.check(); err != nil {
+		return 0, err
+	}
+	return this.api.storeExec(ctx, this.txId, sql, args...)
+}
+
+// Select 在事务内查询
+func (this *storeTx) Select(ctx context.Context, dest interface{}, sql string, args ...interface{}) error {
+	if err := this.check(); err != nil {
+		return err
+	}
+	return this.api.storeSelect(ctx, this.txId, dest, sql, args...)
+}
+
+// SelectRows 在事务内查询，返回结果及列类型元数据
+func (this *storeTx) SelectRows(ctx context.Context, sql string, args ...interface{}) (*vo.StoreRowsRes, error) {
+	if err := this.check(); err != nil {
+		return nil, err
+	}
+	return this.api.storeSelectRows(ctx, this.txId, sql, args...)
+}
+
+// First 在事务内查询第一条记录
+func (this *storeTx) First(ctx context.Context, dest interface{}, sql string, args ...interface{}) error {
+	if err := this.check(); err != nil {
+		return err
+	}
+	return this.api.storeFirst(ctx, this.txId, dest, sql, args...)
+}
+
+// Save 在事务内保存数据
+func (this *storeTx) Save(ctx context.Context, table string, data interface{}) error {
+	if err := this.check(); err != nil {
+		return err
+	}
+	return this.api.storeSave(ctx, this.txId, table, data)
+}
+
+// Update 在事务内更新记录
+func (this *storeTx) Update(ctx context.Context, table string, updateData map[string]interface{}, whereSql string, whereArgs ...interface{}) (rowsAffected int64, err error) {
+	if err = this.check(); err != nil {
+		return 0, err
+	}
+	return this.api.storeUpdate(ctx, this.txId, table, updateData, whereSql, whereArgs...)
+}
+
+// Delete 在事务内删除记录
+func (this *storeTx) Delete(ctx context.Context, table, whereSql string, whereArgs ...interface{}) (rowsAffected int64, err error) {
+	if err = this.check(); err != nil {
+		return 0, err
+	}
+	return this.api.storeDelete(ctx, this.txId, table, whereSql, whereArgs...)
+}
+
+// InsertOrUpdate 在事务内插入或更新记录
+func (this *storeTx) InsertOrUpdate(ctx context.Context, table string, upsertData map[string]interface{}, uniqueKeys ...string) error {
+	if err := this.check(); err != nil {
+		return err
+	}
+	return this.api.storeInsertOrUpdate(ctx, this.txId, table, upsertData, uniqueKeys...)
+}
+
+// Commit 提交事务
+func (this *storeTx) Commit(ctx context.Context) error {
+	if !this.finish() {
+		return ErrTxDone
+	}
+	return this.api.storeTxCommit(ctx, this.txId)
+}
+
+// Rollback 回滚事务
+func (this *storeTx) Rollback(ctx context.Context) error {
+	if !this.finish() {
+		return ErrTxDone
+	}
+	return this.api.storeTxRollback(ctx, this.txId)
+}
+
+// check 检查事务是否已结束
+func (this *storeTx) check() error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if this.done {
+		return ErrTxDone
+	}
+	return nil
+}
+
+// finish 将事务标记为已结束，已结束过时返回false
+func (this *storeTx) finish() bool {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if this.done {
+		return false
+	}
+	this.done = true
+	close(this.closeCh)
+	return true
+}
@@ -1,6 +1,11 @@
 package vo
 
-import "github.com/1340691923/eve-plugin-sdk-go/ev_api/bson"
+import (
+	"time"
+
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/bson"
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/primitive"
+)
 
 type MongoExecRes struct {
 	Result bson.M `json:"result"`
@@ -12,3 +17,212 @@ type MongoUpdateRes struct {
 	UpsertedCount int64       // The number of documents upserted by the operation.
 	UpsertedID    interface{} // The _id field of the upserted document, or nil if no upsert was done.
 }
+
+// MongoCursorRes MongoDB游标结果，打开游标时为第一批数据
+type MongoCursorRes struct {
+	// 游标ID
+	CursorId string `json:"cursor_id" bson:"cursor_id"`
+	// 本批文档
+	Batch []bson.M `json:"batch" bson:"batch"`
+	// 结果集是否已读完，为true时基座已自动关闭游标
+	Done bool `json:"done" bson:"done"`
+}
+
+// MongoIndex MongoDB索引信息
+type MongoIndex struct {
+	// 索引名称
+	Name string `json:"name" bson:"name"`
+	// 索引键
+	Keys bson.D `json:"keys" bson:"key"`
+	// 索引版本
+	Version int32 `json:"version" bson:"v"`
+	// 唯一索引
+	Unique bool `json:"unique" bson:"unique"`
+	// 稀疏索引
+	Sparse bool `json:"sparse" bson:"sparse"`
+	// 隐藏索引
+	Hidden bool `json:"hidden" bson:"hidden"`
+	// TTL索引的过期秒数，不是TTL索引时为nil
+	ExpireAfterSeconds *int32 `json:"expire_after_seconds,omitempty" bson:"expireAfterSeconds,truncate"`
+	// 部分索引的过滤条件
+	PartialFilterExpression bson.M `json:"partial_filter_expression,omitempty" bson:"partialFilterExpression"`
+	// 文本索引各字段的权重
+	Weights bson.M `json:"weights,omitempty" bson:"weights"`
+	// 文本索引的默认语言
+	DefaultLanguage string `json:"default_language,omitempty" bson:"default_language"`
+	// 文本索引中指定文档语言的字段
+	LanguageOverride string `json:"language_override,omitempty" bson:"language_override"`
+	// 2dsphere索引版本
+	SphereVersion int32 `json:"2dsphere_index_version,omitempty" bson:"2dsphereIndexVersion"`
+	// 排序规则
+	Collation bson.M `json:"collation,omitempty" bson:"collation"`
+}
+
+// MongoCollStats MongoDB集合统计信息
+type MongoCollStats struct {
+	// 命名空间，格式为 库名.集合名
+	Ns string `json:"ns" bson:"ns"`
+	// 文档数
+	Count int64 `json:"count" bson:"count,truncate"`
+	// 数据大小（字节，未压缩）
+	Size int64 `json:"size" bson:"size,truncate"`
+	// 平均文档大小（字节）
+	AvgObjSize float64 `json:"avg_obj_size" bson:"avgObjSize"`
+	// 占用的存储空间（字节）
+	StorageSize int64 `json:"storage_size" bson:"storageSize,truncate"`
+	// 可复用的空闲存储空间（字节）
+	FreeStorageSize int64 `json:"free_storage_size" bson:"freeStorageSize,truncate"`
+	// 索引数
+	IndexCount int64 `json:"index_count" bson:"nindexes,truncate"`
+	// 索引总大小（字节）
+	TotalIndexSize int64 `json:"total_index_size" bson:"totalIndexSize,truncate"`
+	// 数据和索引的总大小（字节）
+	TotalSize int64 `json:"total_size" bson:"totalSize,truncate"`
+	// 各索引的大小（字节）
+	IndexSizes map[string]int64 `json:"index_sizes" bson:"indexSizes"`
+	// 是否为固定集合
+	Capped bool `json:"capped" bson:"capped"`
+	// 固定集合的最大文档数
+	Max int64 `json:"max,omitempty" bson:"max,truncate"`
+	// 固定集合的最大字节数
+	MaxSize int64 `json:"max_size,omitempty" bson:"maxSize,truncate"`
+}
+
+// MongoWriteError MongoDB批量写入中单个操作的写入错误
+type MongoWriteError struct {
+	// 出错操作在批量写入模型列表中的下标
+	Index int `json:"index" bson:"index"`
+	// 错误码，例如11000表示唯一键冲突
+	Code int32 `json:"code" bson:"code"`
+	// 错误信息
+	Message string `json:"errmsg" bson:"errmsg"`
+	// 错误详情，例如文档校验失败的原因
+	Details bson.M `json:"errInfo" bson:"errInfo"`
+}
+
+// MongoWriteConcernError MongoDB写关注错误
+type MongoWriteConcernError struct {
+	// 错误码
+	Code int32 `json:"code" bson:"code"`
+	// 错误信息
+	Message string `json:"errmsg" bson:"errmsg"`
+	// 错误详情
+	Details bson.M `json:"errInfo" bson:"errInfo"`
+}
+
+// MongoBulkWriteResult MongoDB批量写入结果
+type MongoBulkWriteResult struct {
+	// 插入的文档数
+	InsertedCount int64 `json:"inserted_count"`
+	// 匹配的文档数
+	MatchedCount int64 `json:"matched_count"`
+	// 修改的文档数
+	ModifiedCount int64 `json:"modified_count"`
+	// 删除的文档数
+	DeletedCount int64 `json:"deleted_count"`
+	// upsert插入的文档数
+	UpsertedCount int64 `json:"upserted_count"`
+	// 写入模型下标 => 插入成功的文档ID，为文档中的原始_id或客户端生成的primitive.ObjectID
+	InsertedIds map[int]interface{} `json:"inserted_ids"`
+	// 写入模型下标 => upsert插入的文档ID
+	UpsertedIds map[int]interface{} `json:"upserted_ids"`
+	// 各操作的写入错误，按下标排序
+	WriteErrors []MongoWriteError `json:"write_errors"`
+	// 写关注错误
+	WriteConcernErrors []MongoWriteConcernError `json:"write_concern_errors"`
+}
+
+// HasErrors 是否存在写入错误或写关注错误
+func (this *MongoBulkWriteResult) HasErrors() bool {
+	return len(this.WriteErrors) > 0 || len(this.WriteConcernErrors) > 0
+}
+
+// MongoSchemaType MongoDB字段中某种类型的出现频率
+type MongoSchemaType struct {
+	// 类型名称，与$type操作符的别名一致，例如string、int、long、double、objectId、date、object、array
+	Type string `json:"type"`
+	// 出现次数
+	Count int `json:"count"`
+	// 占该字段全部值的百分比
+	Percent float64 `json:"percent"`
+}
+
+// MongoSchemaField MongoDB推断出的字段信息
+type MongoSchemaField struct {
+	// 字段路径，嵌套文档用.连接，数组中文档的字段与数组本身使用同一前缀，例如 items.name
+	Path string `json:"path"`
+	// 字段名，即路径的最后一段
+	Name string `json:"name"`
+	// 包含该字段的文档数
+	Count int `json:"count"`
+	// 包含该字段的文档占样本的百分比
+	Presence float64 `json:"presence"`
+	// 字段值的类型频率，按出现次数倒序
+	Types []*MongoSchemaType `json:"types"`
+	// 字段为数组时数组元素的类型频率，按出现次数倒序
+	ArrayTypes []*MongoSchemaType `json:"array_types"`
+	// 示例值，不包含文档和数组
+	Examples []interface{} `json:"examples"`
+}
+
+// MongoSchema MongoDB集合推断出的结构
+type MongoSchema struct {
+	// 集合名称
+	Collection string `json:"collection"`
+	// 样本文档数
+	SampleSize int `json:"sample_size"`
+	// 字段列表，按路径排序，父字段在子字段之前
+	Fields []*MongoSchemaField `json:"fields"`
+}
+
+// MongoChangeStreamRes MongoDB变更流结果，打开变更流时为第一批事件
+type MongoChangeStreamRes struct {
+	// 变更流ID
+	StreamId string `json:"stream_id" bson:"stream_id"`
+	// 本批事件
+	Events []bson.M `json:"events" bson:"events"`
+	// 本批之后的恢复令牌（postBatchResumeToken），没有事件时也会前进
+	ResumeToken bson.M `json:"resume_token" bson:"resume_token"`
+}
+
+// MongoChangeNamespace MongoDB变更事件的命名空间
+type MongoChangeNamespace struct {
+	// 数据库名称
+	Db string `json:"db" bson:"db"`
+	// 集合名称
+	Coll string `json:"coll" bson:"coll"`
+}
+
+// MongoUpdateDescription MongoDB更新事件中的变更内容
+type MongoUpdateDescription struct {
+	// 更新后的字段
+	UpdatedFields bson.M `json:"updatedFields" bson:"updatedFields"`
+	// 删除的字段
+	RemovedFields []string `json:"removedFields" bson:"removedFields"`
+	// 被截断的数组
+	TruncatedArrays []bson.M `json:"truncatedArrays" bson:"truncatedArrays"`
+}
+
+// MongoChangeEvent MongoDB变更事件
+type MongoChangeEvent struct {
+	// 恢复令牌
+	Id bson.M `json:"_id" bson:"_id"`
+	// 操作类型：insert、update、replace、delete、drop、rename、dropDatabase、invalidate等
+	OperationType string `json:"operationType" bson:"operationType"`
+	// 操作在oplog中的时间
+	ClusterTime primitive.Timestamp `json:"clusterTime" bson:"clusterTime"`
+	// 操作的服务器时间
+	WallTime time.Time `json:"wallTime" bson:"wallTime"`
+	// 命名空间
+	Ns MongoChangeNamespace `json:"ns" bson:"ns"`
+	// rename事件的新命名空间
+	To *MongoChangeNamespace `json:"to,omitempty" bson:"to"`
+	// 文档的_id及分片键
+	DocumentKey bson.M `json:"documentKey" bson:"documentKey"`
+	// 完整文档，insert和replace事件总是返回，update事件需要设置FullDocument
+	FullDocument bson.M `json:"fullDocument" bson:"fullDocument"`
+	// 变更前的文档，需要设置FullDocumentBeforeChange
+	FullDocumentBeforeChange bson.M `json:"fullDocumentBeforeChange" bson:"fullDocumentBeforeChange"`
+	// update事件的变更内容
+	UpdateDescription *MongoUpdateDescription `json:"updateDescription,omitempty" bson:"updateDescription"`
+}
@@ -1,16 +1,30 @@
 package vo
 
+import "time"
+
 type MysqlExecSqlRes struct {
 	RowsAffected int64 `json:"rows_affected"`
 }
 
 type MysqlSelectSqlRes struct {
-	Result  []map[string]interface{} `json:"result"`
-	Columns []string                 `json:"columns"`
+	Result      []map[string]interface{} `json:"result"`
+	Columns     []string                 `json:"columns"`
+	ColumnTypes []ColumnType             `json:"column_types"`
 }
 
 type MysqlFirstSqlRes struct {
-	Result map[string]interface{} `json:"result"`
+	Result      map[string]interface{} `json:"result"`
+	ColumnTypes []ColumnType           `json:"column_types"`
+}
+
+// ColumnType 查询结果的列类型元数据
+type ColumnType struct {
+	// 列名
+	Name string `json:"name"`
+	// 数据库类型名称，例如 BIGINT、UNSIGNED INT、DECIMAL、DATETIME
+	DatabaseType string `json:"database_type"`
+	// 是否可为NULL
+	Nullable bool `json:"nullable"`
 }
 
 type MysqlDbsRes struct {
@@ -25,3 +39,123 @@ type MysqlTablesRes struct {
 type DsTypeRes struct {
 	DsType string `json:"ds_type"`
 }
+
+type MysqlBeginTxRes struct {
+	TxId string `json:"tx_id"`
+}
+
+// MysqlCursorOpenRes MySQL打开游标结果
+type MysqlCursorOpenRes struct {
+	CursorId    string       `json:"cursor_id"`
+	Columns     []string     `json:"columns"`
+	ColumnTypes []ColumnType `json:"column_types"`
+}
+
+// MysqlCursorFetchRes MySQL游标拉取结果
+type MysqlCursorFetchRes struct {
+	Rows []map[string]interface{} `json:"rows"`
+	// 结果集是否已读完，为true时基座已自动关闭游标
+	Done bool `json:"done"`
+}
+
+// MysqlColumn MySQL表的列信息
+type MysqlColumn struct {
+	// 列名
+	Name string `json:"name" db:"name"`
+	// 列序号，从1开始
+	Position int `json:"position" db:"position"`
+	// 完整列类型，例如 int(11) unsigned、varchar(255)
+	ColumnType string `json:"column_type" db:"column_type"`
+	// 数据类型，例如 int、varchar
+	DataType string `json:"data_type" db:"data_type"`
+	// 是否可为NULL
+	Nullable bool `json:"nullable" db:"nullable"`
+	// 默认值，没有默认值时为nil
+	Default *string `json:"default" db:"default"`
+	// 键类型：PRI、UNI、MUL或空
+	Key string `json:"key" db:"key"`
+	// 额外信息，例如 auto_increment
+	Extra string `json:"extra" db:"extra"`
+	// 字符集
+	CharacterSet string `json:"character_set" db:"character_set"`
+	// 排序规则
+	Collation string `json:"collation" db:"collation"`
+	// 注释
+	Comment string `json:"comment" db:"comment"`
+}
+
+// MysqlIndex MySQL表的索引信息
+type MysqlIndex struct {
+	// 索引名，主键为PRIMARY
+	Name string `json:"name"`
+	// 是否主键
+	Primary bool `json:"primary"`
+	// 是否唯一索引
+	Unique bool `json:"unique"`
+	// 索引类型，例如 BTREE、FULLTEXT
+	IndexType string `json:"index_type"`
+	// 按顺序排列的索引列
+	Columns []MysqlIndexColumn `json:"columns"`
+	// 注释
+	Comment string `json:"comment"`
+}
+
+// MysqlIndexColumn MySQL索引中的列
+type MysqlIndexColumn struct {
+	// 列名，函数索引时为空
+	Name string `json:"name"`
+	// 前缀索引长度，非前缀索引时为nil
+	SubPart *int64 `json:"sub_part"`
+	// 排序方式：A（升序）、D（降序）或空
+	Collation string `json:"collation"`
+}
+
+// MysqlTableStatus MySQL表的状态信息
+type MysqlTableStatus struct {
+	// 表名
+	Name string `json:"name" db:"name"`
+	// 表类型：BASE TABLE、VIEW
+	TableType string `json:"table_type" db:"table_type"`
+	// 存储引擎
+	Engine string `json:"engine" db:"engine"`
+	// 行格式
+	RowFormat string `json:"row_format" db:"row_format"`
+	// 估算行数，InnoDB下为近似值
+	Rows int64 `json:"rows" db:"rows"`
+	// 平均行长度（字节）
+	AvgRowLength int64 `json:"avg_row_length" db:"avg_row_length"`
+	// 数据大小（字节）
+	DataLength int64 `json:"data_length" db:"data_length"`
+	// 索引大小（字节）
+	IndexLength int64 `json:"index_length" db:"index_length"`
+	// 已分配未使用的空间（字节）
+	DataFree int64 `json:"data_free" db:"data_free"`
+	// 下一个自增值，没有自增列时为nil
+	AutoIncrement *int64 `json:"auto_increment" db:"auto_increment"`
+	// 创建时间
+	CreateTime *time.Time `json:"create_time" db:"create_time"`
+	// 更新时间
+	UpdateTime *time.Time `json:"update_time" db:"update_time"`
+	// 排序规则
+	Collation string `json:"collation" db:"collation"`
+	// 注释
+	Comment string `json:"comment" db:"comment"`
+}
+
+// MysqlForeignKey MySQL表的外键信息
+type MysqlForeignKey struct {
+	// 约束名
+	Name string `json:"name"`
+	// 本表的列，与RefColumns一一对应
+	Columns []string `json:"columns"`
+	// 引用的数据库
+	RefSchema string `json:"ref_schema"`
+	// 引用的表
+	RefTable string `json:"ref_table"`
+	// 引用的列
+	RefColumns []string `json:"ref_columns"`
+	// 更新规则，例如 CASCADE、RESTRICT
+	OnUpdate string `json:"on_update"`
+	// 删除规则
+	OnDelete string `json:"on_delete"`
+}
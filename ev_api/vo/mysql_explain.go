@@ -0,0 +1,111 @@
+package vo
+
+import "time"
+
+// MysqlExplainPlan MySQL执行计划
+type MysqlExplainPlan struct {
+	// 执行计划格式：TRADITIONAL、JSON、TREE、ANALYZE
+	Format string `json:"format"`
+	// 传统格式的EXPLAIN结果行，仅TRADITIONAL格式有值
+	Rows []*MysqlExplainRow `json:"rows,omitempty"`
+	// 执行计划树
+	Root *MysqlExplainNode `json:"root"`
+	// MySQL返回的原始执行计划，JSON、TREE、ANALYZE格式有值
+	Raw string `json:"raw,omitempty"`
+}
+
+// MysqlExplainRow 传统格式的EXPLAIN结果行
+type MysqlExplainRow struct {
+	Id           *int64   `json:"id" db:"id"`
+	SelectType   string   `json:"select_type" db:"select_type"`
+	Table        string   `json:"table" db:"table"`
+	Partitions   string   `json:"partitions" db:"partitions"`
+	Type         string   `json:"type" db:"type"`
+	PossibleKeys string   `json:"possible_keys" db:"possible_keys"`
+	Key          string   `json:"key" db:"key"`
+	KeyLen       string   `json:"key_len" db:"key_len"`
+	Ref          string   `json:"ref" db:"ref"`
+	Rows         int64    `json:"rows" db:"rows"`
+	Filtered     *float64 `json:"filtered" db:"filtered"`
+	Extra        string   `json:"extra" db:"Extra"`
+}
+
+// MysqlExplainNode 执行计划树的节点
+type MysqlExplainNode struct {
+	// 操作，例如 table、nested_loop、ordering_operation，或TREE格式中的 Table scan on t1
+	Operation string `json:"operation"`
+	// 访问的表
+	Table string `json:"table,omitempty"`
+	// 访问类型，例如 ALL、ref、range
+	AccessType string `json:"access_type,omitempty"`
+	// 可能使用的索引
+	PossibleKeys []string `json:"possible_keys,omitempty"`
+	// 实际使用的索引
+	Key string `json:"key,omitempty"`
+	// 使用的索引列
+	UsedKeyParts []string `json:"used_key_parts,omitempty"`
+	// 过滤条件
+	Condition string `json:"condition,omitempty"`
+	// 估算的扫描行数
+	Rows float64 `json:"rows"`
+	// 估算的按条件过滤后保留的百分比
+	Filtered float64 `json:"filtered,omitempty"`
+	// 估算的成本
+	Cost float64 `json:"cost"`
+	// 额外信息，例如 Using index、Using filesort
+	Extra []string `json:"extra,omitempty"`
+	// EXPLAIN ANALYZE的实际执行信息，未执行时为nil
+	Actual *MysqlExplainActual `json:"actual,omitempty"`
+	// 子节点
+	Children []*MysqlExplainNode `json:"children,omitempty"`
+}
+
+// MysqlExplainActual EXPLAIN ANALYZE的实际执行信息
+type MysqlExplainActual struct {
+	// 返回第一行的耗时（毫秒）
+	FirstRowMs float64 `json:"first_row_ms"`
+	// 返回所有行的耗时（毫秒）
+	LastRowMs float64 `json:"last_row_ms"`
+	// 每次循环返回的行数
+	Rows float64 `json:"rows"`
+	// 循环次数
+	Loops int64 `json:"loops"`
+	// 是否从未执行
+	NeverExecuted bool `json:"never_executed"`
+}
+
+// MysqlStatementStats 语句的执行统计，按语句摘要（去除字面量后的SQL）聚合
+type MysqlStatementStats struct {
+	// 数据库
+	Schema string `json:"schema" db:"schema_name"`
+	// 摘要哈希，慢日志来源时为空
+	Digest string `json:"digest" db:"digest"`
+	// 摘要文本
+	Statement string `json:"statement" db:"statement"`
+	// 慢日志来源时的一条原始SQL示例
+	Sample string `json:"sample,omitempty" db:"sample"`
+	// 执行次数
+	ExecCount int64 `json:"exec_count" db:"exec_count"`
+	// 总耗时（毫秒）
+	TotalLatencyMs float64 `json:"total_latency_ms" db:"total_latency_ms"`
+	// 平均耗时（毫秒）
+	AvgLatencyMs float64 `json:"avg_latency_ms" db:"avg_latency_ms"`
+	// 最大耗时（毫秒）
+	MaxLatencyMs float64 `json:"max_latency_ms" db:"max_latency_ms"`
+	// 锁等待总耗时（毫秒）
+	LockLatencyMs float64 `json:"lock_latency_ms" db:"lock_latency_ms"`
+	// 返回的总行数
+	RowsSent int64 `json:"rows_sent" db:"rows_sent"`
+	// 扫描的总行数
+	RowsExamined int64 `json:"rows_examined" db:"rows_examined"`
+	// 影响的总行数
+	RowsAffected int64 `json:"rows_affected" db:"rows_affected"`
+	// 未使用索引的执行次数
+	NoIndexUsed int64 `json:"no_index_used" db:"no_index_used"`
+	// 创建磁盘临时表的次数
+	TmpDiskTables int64 `json:"tmp_disk_tables" db:"tmp_disk_tables"`
+	// 首次出现时间
+	FirstSeen *time.Time `json:"first_seen" db:"first_seen"`
+	// 最后出现时间
+	LastSeen *time.Time `json:"last_seen" db:"last_seen"`
+}
@@ -4,6 +4,23 @@ type SelectRes struct {
 	Result interface{} `json:"result"`
 }
 
+// StoreRowsRes 插件存储多行查询结果
+type StoreRowsRes struct {
+	Result      []map[string]interface{} `json:"result"`
+	ColumnTypes []ColumnType             `json:"column_types"`
+}
+
+// StoreRowRes 插件存储单行查询结果
+type StoreRowRes struct {
+	Result      map[string]interface{} `json:"result"`
+	ColumnTypes []ColumnType           `json:"column_types"`
+}
+
+// StoreBeginTxRes 插件存储开启事务结果
+type StoreBeginTxRes struct {
+	TxId string `json:"tx_id"`
+}
+
 type ExecSqlRes struct {
 	RowsAffected int64 `json:"rows_affected"`
 }
@@ -0,0 +1,31 @@
+package vo
+
+import "errors"
+
+// ErrRedisNil 基座返回的nil回复，键不存在或回复为nil
+var ErrRedisNil = errors.New("redis: nil")
+
+// RedisReply Redis单条命令的执行结果
+type RedisReply struct {
+	// 命令返回值
+	Value interface{} `json:"value"`
+	// 命令执行失败时的错误信息
+	ErrMsg string `json:"error"`
+}
+
+// Err 返回命令的错误，执行成功时为nil
+func (this RedisReply) Err() error {
+	if this.ErrMsg == "" {
+		return nil
+	}
+	if this.ErrMsg == ErrRedisNil.Error() {
+		return ErrRedisNil
+	}
+	return errors.New(this.ErrMsg)
+}
+
+// RedisBatchExecRes Redis批量执行结果
+type RedisBatchExecRes struct {
+	// 与命令一一对应的执行结果
+	Data []RedisReply `json:"data"`
+}
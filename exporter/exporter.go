@@ -0,0 +1,321 @@
+// exporter包提供查询结果导出功能，支持将ES/MySQL/MongoDB数据源流式导出为CSV、NDJSON、XLSX文件
+package exporter
+
+// 导入所需的包
+import (
+	// 带缓冲IO包
+	"bufio"
+	// 上下文包
+	"context"
+	// IO包
+	"io"
+	// HTTP包
+	"net/http"
+	// URL处理包
+	"net/url"
+	// 操作系统包
+	"os"
+	// 字符串处理包
+	"strings"
+
+	// 插件服务包
+	"github.com/1340691923/eve-plugin-sdk-go/backend/plugin_server"
+	// Gin框架
+	"github.com/gin-gonic/gin"
+	// JSON处理包
+	"github.com/goccy/go-json"
+	// 错误处理包
+	"github.com/pkg/errors"
+)
+
+// Options 导出选项
+type Options struct {
+	// 导出格式
+	Format Format
+	// 导出的列及顺序，为空时使用数据源的列，数据源也未知列时扫描全部数据取并集
+	Columns []string
+	// 是否展开嵌套字段
+	Flatten bool
+	// 展开嵌套字段时的连接符，默认为"."
+	FlattenSep string
+	// 最多导出的行数，<=0表示不限制
+	Limit int64
+	// 下载文件名（不含扩展名）
+	FileName string
+	// 是否先完整写入临时文件再下载，开启后响应带Content-Length
+	SpoolToTmpFile bool
+}
+
+// flattenSep 返回展开嵌套字段的连接符
+func (this *Options) flattenSep() string {
+	if this.FlattenSep == "" {
+		return "."
+	}
+	return this.FlattenSep
+}
+
+// Export 将数据源中的数据流式写入w
+// 参数：
+//   - ctx: 上下文
+//   - src: 数据源
+//   - w: 写入目标，实现http.Flusher时每批数据写入后会刷新
+//   - opts: 导出选项
+//
+// 返回：
+//   - rows: 导出的行数
+//   - err: 错误信息
+func Export(ctx context.Context, src Source, w io.Writer, opts Options) (rows int64, err error) {
+	defer src.Close(context.Background())
+
+	rw, err := NewRowWriter(opts.Format, w)
+	if err != nil {
+		return 0, err
+	}
+
+	first, err := src.Next(ctx)
+	if err != nil && err != io.EOF {
+		return 0, errors.WithStack(err)
+	}
+	eof := err == io.EOF
+
+	columns := opts.Columns
+	if len(columns) == 0 && !opts.Flatten {
+		columns = src.Columns()
+	}
+
+	// 需要列但列未知，先将数据暂存到临时文件并收集列
+	if len(columns) == 0 && opts.Format.needColumns() {
+		return exportBySpool(ctx, src, rw, first, eof, opts)
+	}
+
+	if err = rw.WriteHeader(columns); err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	batch := first
+	for {
+		for _, row := range batch {
+			if opts.Limit > 0 && rows >= opts.Limit {
+				return rows, rw.Close()
+			}
+			if opts.Flatten {
+				row = Flatten(row, opts.flattenSep())
+			}
+			if err = rw.WriteRow(columns, row); err != nil {
+				return rows, errors.WithStack(err)
+			}
+			rows++
+		}
+		if err = flush(rw, w); err != nil {
+			return rows, err
+		}
+		if eof || (opts.Limit > 0 && rows >= opts.Limit) {
+			break
+		}
+		if err = ctx.Err(); err != nil {
+			return rows, err
+		}
+		batch, err = src.Next(ctx)
+		if err == io.EOF {
+			eof = true
+			continue
+		}
+		if err != nil {
+			return rows, errors.WithStack(err)
+		}
+	}
+
+	return rows, rw.Close()
+}
+
+// exportBySpool 将数据以NDJSON暂存到临时文件，收集完整的列后再写出
+func exportBySpool(ctx context.Context, src Source, rw RowWriter, first []map[string]interface{}, eof bool, opts Options) (rows int64, err error) {
+	tmp, err := os.CreateTemp(plugin_server.GetTmpFileStorePath(), "ev_export_*.ndjson")
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	defer func() {
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}()
+
+	buf := bufio.NewWriter(tmp)
+	enc := json.NewEncoder(buf)
+	columns := []string{}
+	seen := map[string]struct{}{}
+
+	var spooled int64
+	batch := first
+	for {
+		for _, row := range batch {
+			if opts.Limit > 0 && spooled >= opts.Limit {
+				break
+			}
+			if opts.Flatten {
+				row = Flatten(row, opts.flattenSep())
+			}
+			for col := range row {
+				if _, ok := seen[col]; !ok {
+					seen[col] = struct{}{}
+					columns = append(columns, col)
+				}
+			}
+			if err = enc.Encode(row); err != nil {
+				return 0, errors.WithStack(err)
+			}
+			spooled++
+		}
+		if eof || (opts.Limit > 0 && spooled >= opts.Limit) {
+			break
+		}
+		if err = ctx.Err(); err != nil {
+			return 0, err
+		}
+		batch, err = src.Next(ctx)
+		if err == io.EOF {
+			eof = true
+			continue
+		}
+		if err != nil {
+			return 0, errors.WithStack(err)
+		}
+	}
+	if err = buf.Flush(); err != nil {
+		return 0, errors.WithStack(err)
+	}
+	if _, err = tmp.Seek(0, io.SeekStart); err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	sortColumns(columns)
+	if err = rw.WriteHeader(columns); err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	dec := json.NewDecoder(bufio.NewReader(tmp))
+	dec.UseNumber()
+	for dec.More() {
+		row := map[string]interface{}{}
+		if err = dec.Decode(&row); err != nil {
+			return rows, errors.WithStack(err)
+		}
+		if err = rw.WriteRow(columns, row); err != nil {
+			return rows, errors.WithStack(err)
+		}
+		rows++
+	}
+
+	return rows, rw.Close()
+}
+
+// flush 刷新行写入器，底层写入目标支持时一并刷新
+func flush(rw RowWriter, w io.Writer) error {
+	if err := rw.Flush(); err != nil {
+		return errors.WithStack(err)
+	}
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
+
+// sortColumns 将_id、_index等元数据列排到最前，其余列保持首次出现的顺序
+func sortColumns(columns []string) {
+	j := 0
+	for i, col := range columns {
+		if strings.HasPrefix(col, "_") && !strings.Contains(col, ".") {
+			copy(columns[j+1:i+1], columns[j:i])
+			columns[j] = col
+			j++
+		}
+	}
+}
+
+// Download 将数据源以附件形式通过gin响应下载
+// 默认边读边写（分块传输），首批数据写出前发生的错误会直接返回，调用方仍可正常响应错误信息
+// 开启SpoolToTmpFile时先写入plugin_server.GetTmpFileStorePath()下的临时文件，完成后再整体下载
+// 参数：
+//   - c: gin上下文
+//   - src: 数据源
+//   - opts: 导出选项
+//
+// 返回：
+//   - rows: 导出的行数
+//   - err: 错误信息
+func Download(c *gin.Context, src Source, opts Options) (rows int64, err error) {
+	fileName := opts.FileName
+	if fileName == "" {
+		fileName = "export"
+	}
+	fileName += opts.Format.Ext()
+
+	if opts.SpoolToTmpFile {
+		return downloadByTmpFile(c, src, opts, fileName)
+	}
+
+	w := &attachmentWriter{c: c, contentType: opts.Format.ContentType(), fileName: fileName}
+	return Export(c.Request.Context(), src, w, opts)
+}
+
+// downloadByTmpFile 先导出到临时文件再下载
+func downloadByTmpFile(c *gin.Context, src Source, opts Options, fileName string) (rows int64, err error) {
+	tmp, err := os.CreateTemp(plugin_server.GetTmpFileStorePath(), "ev_export_*"+opts.Format.Ext())
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	defer func() {
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}()
+
+	rows, err = Export(c.Request.Context(), src, tmp, opts)
+	if err != nil {
+		return rows, err
+	}
+
+	stat, err := tmp.Stat()
+	if err != nil {
+		return rows, errors.WithStack(err)
+	}
+	if _, err = tmp.Seek(0, io.SeekStart); err != nil {
+		return rows, errors.WithStack(err)
+	}
+
+	c.DataFromReader(http.StatusOK, stat.Size(), opts.Format.ContentType(), tmp, map[string]string{
+		"Content-Disposition": contentDisposition(fileName),
+	})
+	return rows, nil
+}
+
+// attachmentWriter 首次写入时才设置附件响应头，以便写出前的错误仍能正常响应
+type attachmentWriter struct {
+	c           *gin.Context
+	contentType string
+	fileName    string
+	wroteHeader bool
+}
+
+// Write 写入数据
+func (this *attachmentWriter) Write(p []byte) (int, error) {
+	if !this.wroteHeader {
+		this.wroteHeader = true
+		header := this.c.Writer.Header()
+		header.Set("Content-Type", this.contentType)
+		header.Set("Content-Disposition", contentDisposition(this.fileName))
+		header.Set("Cache-Control", "no-cache")
+		this.c.Status(http.StatusOK)
+	}
+	return this.c.Writer.Write(p)
+}
+
+// Flush 刷新响应
+func (this *attachmentWriter) Flush() {
+	if this.wroteHeader {
+		this.c.Writer.Flush()
+	}
+}
+
+// contentDisposition 生成兼容中文文件名的Content-Disposition
+func contentDisposition(fileName string) string {
+	return `attachment; filename="` + url.PathEscape(fileName) + `"; filename*=UTF-8''` + url.PathEscape(fileName)
+}
@@ -0,0 +1,102 @@
+// exporter包提供查询结果导出功能，支持将ES/MySQL/MongoDB数据源流式导出为CSV、NDJSON、XLSX文件
+package exporter
+
+// 导入所需的包
+import (
+	// 格式化包
+	"fmt"
+	// 字符串转换包
+	"strconv"
+	// 时间处理包
+	"time"
+
+	// MongoDB原始类型包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/primitive"
+	// JSON处理包
+	"github.com/goccy/go-json"
+)
+
+// Flatten 将嵌套的文档展开为单层结构，嵌套字段以sep连接，例如 {"a":{"b":1}} => {"a.b":1}
+// 数组不展开，保留原值，由单元格格式化时序列化为JSON
+func Flatten(row map[string]interface{}, sep string) map[string]interface{} {
+	res := make(map[string]interface{}, len(row))
+	flattenInto(res, "", row, sep)
+	return res
+}
+
+// flattenInto 递归展开嵌套文档
+func flattenInto(dst map[string]interface{}, prefix string, src map[string]interface{}, sep string) {
+	for k, v := range src {
+		key := k
+		if prefix != "" {
+			key = prefix + sep + k
+		}
+		switch val := v.(type) {
+		case map[string]interface{}:
+			if len(val) == 0 {
+				dst[key] = val
+				continue
+			}
+			flattenInto(dst, key, val, sep)
+		case primitive.M:
+			if len(val) == 0 {
+				dst[key] = val
+				continue
+			}
+			flattenInto(dst, key, val, sep)
+		case primitive.D:
+			if len(val) == 0 {
+				dst[key] = val
+				continue
+			}
+			flattenInto(dst, key, val.Map(), sep)
+		default:
+			dst[key] = v
+		}
+	}
+}
+
+// FormatCell 将单元格的值格式化为字符串
+func FormatCell(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case []byte:
+		return string(val)
+	case bool:
+		return strconv.FormatBool(val)
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(val), 'f', -1, 32)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(val)
+	case json.Number:
+		return val.String()
+	case time.Time:
+		return val.Format(time.RFC3339)
+	case primitive.ObjectID:
+		return val.Hex()
+	case primitive.DateTime:
+		return val.Time().Format(time.RFC3339)
+	case fmt.Stringer:
+		return val.String()
+	default:
+		b, err := json.Marshal(val)
+		if err != nil {
+			return fmt.Sprint(val)
+		}
+		return string(b)
+	}
+}
+
+// isNumber 判断单元格的值是否为数字，XLSX中数字按数值类型写入
+func isNumber(v interface{}) bool {
+	switch v.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, json.Number:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,325 @@
+// exporter包提供查询结果导出功能，支持将ES/MySQL/MongoDB数据源流式导出为CSV、NDJSON、XLSX文件
+package exporter
+
+// 导入所需的包
+import (
+	// 上下文包
+	"context"
+	// 格式化包
+	"fmt"
+	// IO包
+	"io"
+	// HTTP包
+	"net/http"
+	// 字符串处理包
+	"strings"
+	// 时间处理包
+	"time"
+
+	// EVE API包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api"
+	// MongoDB BSON包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/bson"
+	// 数据源接口包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/pkg"
+	// Protobuf协议包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/proto"
+	// JSON处理包
+	"github.com/goccy/go-json"
+	// 错误处理包
+	"github.com/pkg/errors"
+	// JSON路径解析包
+	"github.com/tidwall/gjson"
+)
+
+// DefaultBatchSize 默认每批拉取的行数
+const DefaultBatchSize = 1000
+
+// Source 导出数据源接口
+// Next 每次返回一批行数据，数据读取完毕时返回io.EOF
+type Source interface {
+	// Columns 返回数据源已知的列顺序，未知时返回nil
+	Columns() []string
+	// Next 读取下一批数据
+	Next(ctx context.Context) (rows []map[string]interface{}, err error)
+	// Close 释放数据源占用的资源
+	Close(ctx context.Context) error
+}
+
+// EsSearchSource 基于EsSearch的scroll数据源
+type EsSearchSource struct {
+	api       pkg.ClientInterface
+	indices   []string
+	query     interface{}
+	batchSize int
+	keepAlive time.Duration
+	scrollId  string
+	done      bool
+}
+
+// NewEsSearchSource 创建ES查询数据源
+// 参数：
+//   - api: 数据源接口
+//   - indices: 索引名称列表
+//   - query: 查询DSL（不需要包含size）
+//   - batchSize: 每批拉取条数，<=0时使用默认值
+//
+// 返回：
+//   - *EsSearchSource: ES数据源
+func NewEsSearchSource(api pkg.ClientInterface, indices []string, query interface{}, batchSize int) *EsSearchSource {
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	return &EsSearchSource{
+		api:       api,
+		indices:   indices,
+		query:     query,
+		batchSize: batchSize,
+		keepAlive: time.Minute,
+	}
+}
+
+// Columns ES文档没有固定列，返回nil
+func (this *EsSearchSource) Columns() []string {
+	return nil
+}
+
+// Next 读取下一批文档，首批通过EsSearch开启scroll，后续批次通过_search/scroll继续拉取
+func (this *EsSearchSource) Next(ctx context.Context) (rows []map[string]interface{}, err error) {
+	if this.done {
+		return nil, io.EOF
+	}
+
+	var res *proto.Response
+	if this.scrollId == "" {
+		size := this.batchSize
+		res, err = this.api.EsSearch(ctx, proto.SearchRequest{
+			Index:  this.indices,
+			Size:   &size,
+			Scroll: this.keepAlive,
+		}, this.query)
+	} else {
+		res, err = this.performScroll(ctx, http.MethodPost, map[string]interface{}{
+			"scroll":    fmt.Sprintf("%ds", int(this.keepAlive.Seconds())),
+			"scroll_id": this.scrollId,
+		})
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err = res.StatusErr(); err != nil {
+		return nil, err
+	}
+
+	body := res.ResByte()
+	this.scrollId = gjson.GetBytes(body, "_scroll_id").String()
+
+	hits := gjson.GetBytes(body, "hits.hits").Array()
+	if len(hits) == 0 {
+		this.done = true
+		return nil, io.EOF
+	}
+
+	rows = make([]map[string]interface{}, 0, len(hits))
+	for _, hit := range hits {
+		row := map[string]interface{}{}
+		if source := hit.Get("_source"); source.Exists() {
+			if err = json.Unmarshal([]byte(source.Raw), &row); err != nil {
+				return nil, errors.WithStack(err)
+			}
+		}
+		row["_id"] = hit.Get("_id").String()
+		row["_index"] = hit.Get("_index").String()
+		rows = append(rows, row)
+	}
+
+	if len(hits) < this.batchSize {
+		this.done = true
+	}
+
+	return rows, nil
+}
+
+// Close 清理scroll上下文
+func (this *EsSearchSource) Close(ctx context.Context) error {
+	if this.scrollId == "" {
+		return nil
+	}
+	_, err := this.performScroll(ctx, http.MethodDelete, map[string]interface{}{
+		"scroll_id": this.scrollId,
+	})
+	this.scrollId = ""
+	return err
+}
+
+// performScroll 发送_search/scroll请求
+func (this *EsSearchSource) performScroll(ctx context.Context, method string, body interface{}) (*proto.Response, error) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	req, err := http.NewRequest(method, "/_search/scroll", strings.NewReader(string(b)))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return this.api.EsPerformRequest(ctx, req)
+}
+
+// MysqlCursorOpener 可以打开MySQL服务端游标的接口，*ev_api.EvApiAdapter已实现
+type MysqlCursorOpener interface {
+	MysqlOpenCursor(ctx context.Context, dbName string, batchSize int, sql string, args ...interface{}) (*ev_api.MysqlCursor, error)
+}
+
+// MysqlSelectSource 基于MySQL服务端游标的数据源
+// 整个导出只执行一次查询，不会因分页查询之间行顺序不稳定而重复或遗漏行，大表的后续批次也不会变慢
+type MysqlSelectSource struct {
+	api       MysqlCursorOpener
+	dbName    string
+	sql       string
+	args      []interface{}
+	batchSize int
+	cursor    *ev_api.MysqlCursor
+	columns   []string
+	done      bool
+}
+
+// NewMysqlSelectSource 创建MySQL查询数据源，首次调用Next时打开服务端游标并按批拉取
+// 参数：
+//   - api: 数据源适配器
+//   - dbName: 数据库名称
+//   - sql: 查询SQL
+//   - batchSize: 每批拉取条数，<=0时使用默认值
+//   - args: SQL参数
+//
+// 返回：
+//   - *MysqlSelectSource: MySQL数据源
+func NewMysqlSelectSource(api MysqlCursorOpener, dbName, sql string, batchSize int, args ...interface{}) *MysqlSelectSource {
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	return &MysqlSelectSource{
+		api:       api,
+		dbName:    dbName,
+		sql:       strings.TrimRight(strings.TrimSpace(sql), ";"),
+		args:      args,
+		batchSize: batchSize,
+	}
+}
+
+// Columns 返回打开游标后得到的列名
+func (this *MysqlSelectSource) Columns() []string {
+	return this.columns
+}
+
+// Next 读取下一批数据
+func (this *MysqlSelectSource) Next(ctx context.Context) (rows []map[string]interface{}, err error) {
+	if this.done {
+		return nil, io.EOF
+	}
+
+	if this.cursor == nil {
+		this.cursor, err = this.api.MysqlOpenCursor(ctx, this.dbName, this.batchSize, this.sql, this.args...)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		this.columns = this.cursor.Columns()
+	}
+
+	rows, err = this.cursor.Next(ctx)
+	if err == io.EOF {
+		this.done = true
+		return nil, io.EOF
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return rows, nil
+}
+
+// Close 关闭服务端游标
+func (this *MysqlSelectSource) Close(ctx context.Context) error {
+	if this.cursor == nil {
+		return nil
+	}
+	return this.cursor.Close(ctx)
+}
+
+// MongoFindSource 基于FindMongoDocuments的分页数据源
+type MongoFindSource struct {
+	api            pkg.ClientInterface
+	dbName         string
+	collectionName string
+	filter         bson.M
+	projection     bson.M
+	sort           bson.D
+	batchSize      int64
+	skip           int64
+	done           bool
+}
+
+// NewMongoFindSource 创建MongoDB查询数据源
+// 参数：
+//   - api: 数据源接口
+//   - dbName: 数据库名称
+//   - collectionName: 集合名称
+//   - filter: 查询过滤条件
+//   - projection: 投影
+//   - sort: 排序条件，为空时按_id排序以保证分页稳定
+//   - batchSize: 每批拉取条数，<=0时使用默认值
+//
+// 返回：
+//   - *MongoFindSource: MongoDB数据源
+func NewMongoFindSource(api pkg.ClientInterface, dbName, collectionName string, filter, projection bson.M, sort bson.D, batchSize int) *MongoFindSource {
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	if len(sort) == 0 {
+		sort = bson.D{{Key: "_id", Value: 1}}
+	}
+	return &MongoFindSource{
+		api:            api,
+		dbName:         dbName,
+		collectionName: collectionName,
+		filter:         filter,
+		projection:     projection,
+		sort:           sort,
+		batchSize:      int64(batchSize),
+	}
+}
+
+// Columns MongoDB文档没有固定列，返回nil
+func (this *MongoFindSource) Columns() []string {
+	return nil
+}
+
+// Next 读取下一批文档
+func (this *MongoFindSource) Next(ctx context.Context) (rows []map[string]interface{}, err error) {
+	if this.done {
+		return nil, io.EOF
+	}
+
+	docs, err := this.api.MongoFindDocuments(ctx, this.dbName, this.collectionName, this.projection, this.filter, this.sort, this.skip, this.batchSize)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	this.skip += int64(len(docs))
+	if int64(len(docs)) < this.batchSize {
+		this.done = true
+	}
+	if len(docs) == 0 {
+		return nil, io.EOF
+	}
+
+	rows = make([]map[string]interface{}, 0, len(docs))
+	for _, doc := range docs {
+		rows = append(rows, doc)
+	}
+	return rows, nil
+}
+
+// Close MongoDB数据源无需释放资源
+func (this *MongoFindSource) Close(ctx context.Context) error {
+	return nil
+}
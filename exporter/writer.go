@@ -0,0 +1,164 @@
+// exporter包提供查询结果导出功能，支持将ES/MySQL/MongoDB数据源流式导出为CSV、NDJSON、XLSX文件
+package exporter
+
+// 导入所需的包
+import (
+	// CSV编码包
+	"encoding/csv"
+	// IO包
+	"io"
+
+	// JSON处理包
+	"github.com/goccy/go-json"
+	// 错误处理包
+	"github.com/pkg/errors"
+)
+
+// Format 导出文件格式
+type Format string
+
+// 支持的导出格式
+const (
+	// CSV格式
+	FormatCSV Format = "csv"
+	// NDJSON格式，每行一个JSON对象
+	FormatNDJSON Format = "ndjson"
+	// XLSX格式
+	FormatXLSX Format = "xlsx"
+)
+
+// ContentType 返回格式对应的Content-Type
+func (this Format) ContentType() string {
+	switch this {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "application/octet-stream"
+}
+
+// Ext 返回格式对应的文件扩展名
+func (this Format) Ext() string {
+	return "." + string(this)
+}
+
+// needColumns 判断该格式是否需要在写入前确定列
+func (this Format) needColumns() bool {
+	return this != FormatNDJSON
+}
+
+// RowWriter 行写入器接口
+type RowWriter interface {
+	// WriteHeader 写入表头
+	WriteHeader(columns []string) error
+	// WriteRow 按列顺序写入一行
+	WriteRow(columns []string, row map[string]interface{}) error
+	// Flush 将缓冲的数据写入底层io.Writer
+	Flush() error
+	// Close 完成写入，不会关闭底层io.Writer
+	Close() error
+}
+
+// NewRowWriter 根据格式创建行写入器
+// 参数：
+//   - format: 导出格式
+//   - w: 底层写入目标
+//
+// 返回：
+//   - RowWriter: 行写入器
+//   - error: 错误信息
+func NewRowWriter(format Format, w io.Writer) (RowWriter, error) {
+	switch format {
+	case FormatCSV:
+		return newCsvWriter(w), nil
+	case FormatNDJSON:
+		return newNdjsonWriter(w), nil
+	case FormatXLSX:
+		return newXlsxWriter(w), nil
+	}
+	return nil, errors.Errorf("不支持的导出格式：%s", format)
+}
+
+// csvWriter CSV行写入器
+type csvWriter struct {
+	raw    io.Writer
+	w      *csv.Writer
+	record []string
+}
+
+// newCsvWriter 创建CSV行写入器
+func newCsvWriter(w io.Writer) *csvWriter {
+	return &csvWriter{raw: w, w: csv.NewWriter(w)}
+}
+
+// WriteHeader 写入UTF-8 BOM（便于Excel正确识别编码）和表头
+func (this *csvWriter) WriteHeader(columns []string) error {
+	if _, err := this.raw.Write([]byte("\xEF\xBB\xBF")); err != nil {
+		return err
+	}
+	return this.w.Write(columns)
+}
+
+// WriteRow 写入一行
+func (this *csvWriter) WriteRow(columns []string, row map[string]interface{}) error {
+	this.record = this.record[:0]
+	for _, col := range columns {
+		this.record = append(this.record, FormatCell(row[col]))
+	}
+	return this.w.Write(this.record)
+}
+
+// Flush 刷新缓冲区
+func (this *csvWriter) Flush() error {
+	this.w.Flush()
+	return this.w.Error()
+}
+
+// Close 刷新缓冲区
+func (this *csvWriter) Close() error {
+	return this.Flush()
+}
+
+// ndjsonWriter NDJSON行写入器
+type ndjsonWriter struct {
+	enc *json.Encoder
+}
+
+// newNdjsonWriter 创建NDJSON行写入器
+func newNdjsonWriter(w io.Writer) *ndjsonWriter {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return &ndjsonWriter{enc: enc}
+}
+
+// WriteHeader NDJSON没有表头
+func (this *ndjsonWriter) WriteHeader(columns []string) error {
+	return nil
+}
+
+// WriteRow 写入一行，columns不为空时只输出指定列
+func (this *ndjsonWriter) WriteRow(columns []string, row map[string]interface{}) error {
+	if len(columns) == 0 {
+		return this.enc.Encode(row)
+	}
+	picked := make(map[string]interface{}, len(columns))
+	for _, col := range columns {
+		if v, ok := row[col]; ok {
+			picked[col] = v
+		}
+	}
+	return this.enc.Encode(picked)
+}
+
+// Flush NDJSON直接写入底层io.Writer，无需刷新
+func (this *ndjsonWriter) Flush() error {
+	return nil
+}
+
+// Close NDJSON无需收尾
+func (this *ndjsonWriter) Close() error {
+	return nil
+}
@@ -0,0 +1,179 @@
+// exporter包提供查询结果导出功能，支持将ES/MySQL/MongoDB数据源流式导出为CSV、NDJSON、XLSX文件
+package exporter
+
+// 导入所需的包
+import (
+	// ZIP压缩包
+	"archive/zip"
+	// 带缓冲IO包
+	"bufio"
+	// XML编码包
+	"encoding/xml"
+	// IO包
+	"io"
+	// 字符串转换包
+	"strconv"
+
+	// 错误处理包
+	"github.com/pkg/errors"
+)
+
+// XlsxMaxRows XLSX单个工作表的最大行数（含表头）
+const XlsxMaxRows = 1048576
+
+// xlsx文件中除工作表外的固定部件
+var xlsxStaticParts = []struct {
+	name    string
+	content string
+}{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+	{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`},
+}
+
+// xlsxWriter 流式XLSX行写入器
+// 工作表使用内联字符串，无需维护共享字符串表，因此可以边读边写
+type xlsxWriter struct {
+	zw      *zip.Writer
+	sheet   *bufio.Writer
+	started bool
+	rowNum  int
+	err     error
+}
+
+// newXlsxWriter 创建XLSX行写入器
+func newXlsxWriter(w io.Writer) *xlsxWriter {
+	return &xlsxWriter{zw: zip.NewWriter(w)}
+}
+
+// start 写入固定部件并打开工作表
+func (this *xlsxWriter) start() error {
+	if this.started {
+		return this.err
+	}
+	this.started = true
+
+	for _, part := range xlsxStaticParts {
+		f, err := this.zw.Create(part.name)
+		if err != nil {
+			this.err = errors.WithStack(err)
+			return this.err
+		}
+		if _, err = io.WriteString(f, part.content); err != nil {
+			this.err = errors.WithStack(err)
+			return this.err
+		}
+	}
+
+	f, err := this.zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		this.err = errors.WithStack(err)
+		return this.err
+	}
+	this.sheet = bufio.NewWriter(f)
+	this.sheet.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	return nil
+}
+
+// WriteHeader 写入表头
+func (this *xlsxWriter) WriteHeader(columns []string) error {
+	row := make(map[string]interface{}, len(columns))
+	for _, col := range columns {
+		row[col] = col
+	}
+	return this.WriteRow(columns, row)
+}
+
+// WriteRow 写入一行
+func (this *xlsxWriter) WriteRow(columns []string, row map[string]interface{}) error {
+	if err := this.start(); err != nil {
+		return err
+	}
+	if this.rowNum >= XlsxMaxRows {
+		return errors.Errorf("XLSX最多支持%d行", XlsxMaxRows)
+	}
+	this.rowNum++
+
+	rowRef := strconv.Itoa(this.rowNum)
+	this.sheet.WriteString(`<row r="`)
+	this.sheet.WriteString(rowRef)
+	this.sheet.WriteString(`">`)
+	for i, col := range columns {
+		v := row[col]
+		if v == nil {
+			continue
+		}
+		ref := xlsxColumnName(i) + rowRef
+		if isNumber(v) {
+			this.sheet.WriteString(`<c r="` + ref + `"><v>`)
+			this.sheet.WriteString(FormatCell(v))
+			this.sheet.WriteString(`</v></c>`)
+			continue
+		}
+		this.sheet.WriteString(`<c r="` + ref + `" t="inlineStr"><is><t xml:space="preserve">`)
+		xml.EscapeText(this.sheet, []byte(xlsxSanitize(FormatCell(v))))
+		this.sheet.WriteString(`</t></is></c>`)
+	}
+	_, err := this.sheet.WriteString(`</row>`)
+	return err
+}
+
+// Flush 刷新工作表缓冲区
+func (this *xlsxWriter) Flush() error {
+	if this.sheet == nil {
+		return nil
+	}
+	if err := this.sheet.Flush(); err != nil {
+		return err
+	}
+	return this.zw.Flush()
+}
+
+// Close 结束工作表并写入ZIP目录
+func (this *xlsxWriter) Close() error {
+	if err := this.start(); err != nil {
+		return err
+	}
+	this.sheet.WriteString(`</sheetData></worksheet>`)
+	if err := this.sheet.Flush(); err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(this.zw.Close())
+}
+
+// xlsxColumnName 将从0开始的列下标转换为Excel列名，例如 0 => A，26 => AA
+func xlsxColumnName(i int) string {
+	name := []byte{}
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = append([]byte{byte('A' + (i-1)%26)}, name...)
+	}
+	return string(name)
+}
+
+// xlsxSanitize 移除XML 1.0不允许出现的控制字符
+func xlsxSanitize(s string) string {
+	clean := true
+	for _, r := range s {
+		if r < 0x20 && r != '\t' && r != '\n' && r != '\r' {
+			clean = false
+			break
+		}
+	}
+	if clean {
+		return s
+	}
+	out := make([]rune, 0, len(s))
+	for _, r := range s {
+		if r < 0x20 && r != '\t' && r != '\n' && r != '\r' {
+			continue
+		}
+		out = append(out, r)
+	}
+	return string(out)
+}
@@ -0,0 +1,475 @@
+// importer包提供文件导入功能，支持将CSV、NDJSON文件批量导入ES索引、MySQL表或MongoDB集合
+package importer
+
+// 导入所需的包
+import (
+	// 上下文包
+	"context"
+	// 格式化包
+	"fmt"
+	// IO包
+	"io"
+	// 操作系统包
+	"os"
+	// 路径处理包
+	"path/filepath"
+	// 排序包
+	"sort"
+	// 时间处理包
+	"time"
+
+	// 插件服务包
+	"github.com/1340691923/eve-plugin-sdk-go/backend/plugin_server"
+	// EVE API包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api"
+	// Gin框架
+	"github.com/gin-gonic/gin"
+	// 错误处理包
+	"github.com/pkg/errors"
+)
+
+// 默认配置
+const (
+	// DefaultBatchSize 默认每批写入的行数
+	DefaultBatchSize = 500
+	// DefaultSampleSize 默认用于类型推断的样本行数
+	DefaultSampleSize = 200
+	// DefaultMaxErrors 报告中默认最多保留的行错误数
+	DefaultMaxErrors = 1000
+	// DefaultProgressInterval 默认推送进度的最小间隔
+	DefaultProgressInterval = time.Second
+)
+
+// RowError 行错误
+type RowError struct {
+	// 出错的行号
+	Line int `json:"line"`
+	// 错误信息
+	Msg string `json:"msg"`
+}
+
+// Error 实现error接口
+func (this *RowError) Error() string {
+	return fmt.Sprintf("第%d行：%s", this.Line, this.Msg)
+}
+
+// Report 导入结果报告
+type Report struct {
+	// 读取的总行数
+	Total int64 `json:"total"`
+	// 写入成功的行数
+	Success int64 `json:"success"`
+	// 失败的行数
+	Failed int64 `json:"failed"`
+	// 行错误明细，最多保留MaxErrors条
+	Errors []*RowError `json:"errors"`
+	// 推断出的表结构
+	Schema *Schema `json:"schema"`
+	// 样本之后才出现的字段，没有推断出类型，所有导入目标都不会写入
+	DroppedFields []string `json:"dropped_fields"`
+	// 耗时
+	Cost string `json:"cost"`
+}
+
+// Progress 推送到长连接频道的进度数据
+type Progress struct {
+	// 已处理的行数
+	Processed int64 `json:"processed"`
+	// 写入成功的行数
+	Success int64 `json:"success"`
+	// 失败的行数
+	Failed int64 `json:"failed"`
+	// 已读取的字节数
+	ReadBytes int64 `json:"read_bytes"`
+	// 文件总字节数，未知时为0
+	TotalBytes int64 `json:"total_bytes"`
+	// 是否已结束
+	Done bool `json:"done"`
+	// 结束时的错误信息
+	Err string `json:"err,omitempty"`
+}
+
+// Options 导入选项
+type Options struct {
+	// 文件格式
+	Format Format
+	// 列到目标字段的映射，不为空时只导入映射中的列
+	Mapping map[string]string
+	// 手动指定字段类型，优先于推断结果，键为文件中的列名
+	Types map[string]FieldType
+	// 每批写入的行数
+	BatchSize int
+	// 用于类型推断的样本行数
+	SampleSize int
+	// 报告中最多保留的行错误数
+	MaxErrors int
+	// 失败行数超过该值时中止导入，<=0表示不中止
+	AbortAfterErrors int64
+	// 推送进度的长连接频道，为空时不推送
+	ProgressChannel string
+	// 推送进度的最小间隔
+	ProgressInterval time.Duration
+}
+
+// withDefaults 填充默认值
+func (this Options) withDefaults() Options {
+	if this.BatchSize <= 0 {
+		this.BatchSize = DefaultBatchSize
+	}
+	if this.SampleSize <= 0 {
+		this.SampleSize = DefaultSampleSize
+	}
+	if this.MaxErrors <= 0 {
+		this.MaxErrors = DefaultMaxErrors
+	}
+	if this.ProgressInterval <= 0 {
+		this.ProgressInterval = DefaultProgressInterval
+	}
+	return this
+}
+
+// importer 单次导入的执行状态
+type importer struct {
+	sink       Sink
+	opts       Options
+	report     *Report
+	counter    *countingReader
+	totalBytes int64
+	lastPush   time.Time
+	columns    []string
+	targets    []string
+	types      []FieldType
+	known      map[string]bool
+	dropped    map[string]bool
+}
+
+// Import 从r中读取数据并导入到sink
+// 参数：
+//   - ctx: 上下文
+//   - r: 数据来源
+//   - totalBytes: 数据总字节数，用于计算进度，未知时传0
+//   - sink: 导入目标
+//   - opts: 导入选项
+//
+// 返回：
+//   - *Report: 导入结果报告
+//   - error: 不可恢复的错误，行级错误记录在报告中
+func Import(ctx context.Context, r io.Reader, totalBytes int64, sink Sink, opts Options) (*Report, error) {
+	t := time.Now()
+	opts = opts.withDefaults()
+
+	this := &importer{
+		sink:       sink,
+		opts:       opts,
+		report:     &Report{Errors: []*RowError{}, DroppedFields: []string{}},
+		counter:    &countingReader{r: r},
+		totalBytes: totalBytes,
+		dropped:    map[string]bool{},
+	}
+
+	err := this.run(ctx)
+	sort.Strings(this.report.DroppedFields)
+	this.report.Cost = time.Now().Sub(t).String()
+
+	progress := this.progress()
+	progress.Done = true
+	if err != nil {
+		progress.Err = err.Error()
+	}
+	this.push(context.Background(), progress, true)
+
+	return this.report, err
+}
+
+// run 执行导入：读取样本推断类型，然后分批转换并写入
+func (this *importer) run(ctx context.Context) error {
+	reader, err := NewRecordReader(this.opts.Format, this.counter)
+	if err != nil {
+		return err
+	}
+
+	samples := make([]*Record, 0, this.opts.SampleSize)
+	eof := false
+	for len(samples) < this.opts.SampleSize {
+		record, err := this.read(reader)
+		if err == io.EOF {
+			eof = true
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if record != nil {
+			samples = append(samples, record)
+		}
+	}
+
+	sampleData := make([]map[string]interface{}, 0, len(samples))
+	for _, record := range samples {
+		sampleData = append(sampleData, record.Data)
+	}
+	this.report.Schema = InferSchema(reader.Columns(), sampleData)
+	for col, t := range this.opts.Types {
+		for i := range this.report.Schema.Fields {
+			if this.report.Schema.Fields[i].Name == col {
+				this.report.Schema.Fields[i].Type = t
+			}
+		}
+	}
+	this.buildColumns()
+
+	batch := make([]*Record, 0, this.opts.BatchSize)
+	for _, record := range samples {
+		batch = append(batch, record)
+		if len(batch) >= this.opts.BatchSize {
+			if err = this.flush(ctx, batch); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+
+	for !eof {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		record, err := this.read(reader)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if record == nil {
+			continue
+		}
+		batch = append(batch, record)
+		if len(batch) >= this.opts.BatchSize {
+			if err = this.flush(ctx, batch); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+
+	if len(batch) > 0 {
+		return this.flush(ctx, batch)
+	}
+	return nil
+}
+
+// read 读取一行，解析失败的行计入报告并返回nil
+func (this *importer) read(reader RecordReader) (*Record, error) {
+	record, err := reader.Read()
+	if err == nil {
+		this.report.Total++
+		return record, nil
+	}
+	if rowErr, ok := err.(*RowError); ok {
+		this.report.Total++
+		return nil, this.fail(rowErr)
+	}
+	return nil, err
+}
+
+// buildColumns 根据映射确定要导入的列及其目标字段名
+func (this *importer) buildColumns() {
+	this.columns = this.columns[:0]
+	this.targets = this.targets[:0]
+	this.types = this.types[:0]
+	this.known = make(map[string]bool, len(this.report.Schema.Fields))
+	for _, f := range this.report.Schema.Fields {
+		this.known[f.Name] = true
+		target := f.Name
+		if len(this.opts.Mapping) > 0 {
+			mapped, ok := this.opts.Mapping[f.Name]
+			if !ok || mapped == "" {
+				continue
+			}
+			target = mapped
+		}
+		this.columns = append(this.columns, f.Name)
+		this.targets = append(this.targets, target)
+		this.types = append(this.types, f.Type)
+	}
+}
+
+// flush 转换并写入一批数据
+func (this *importer) flush(ctx context.Context, batch []*Record) error {
+	rows := make([]map[string]interface{}, 0, len(batch))
+	lines := make([]int, 0, len(batch))
+
+	for _, record := range batch {
+		row, err := this.convert(record)
+		if err != nil {
+			if err = this.fail(err.(*RowError)); err != nil {
+				return err
+			}
+			continue
+		}
+		rows = append(rows, row)
+		lines = append(lines, record.Line)
+	}
+
+	if len(rows) > 0 {
+		rowErrs, err := this.sink.WriteBatch(ctx, this.targets, rows)
+		if err != nil {
+			return err
+		}
+		for i, rowErr := range rowErrs {
+			if rowErr == nil {
+				this.report.Success++
+				continue
+			}
+			if err = this.fail(&RowError{Line: lines[i], Msg: rowErr.Error()}); err != nil {
+				return err
+			}
+		}
+	}
+
+	this.push(ctx, this.progress(), false)
+	return nil
+}
+
+// convert 按映射和推断的类型转换一行
+func (this *importer) convert(record *Record) (map[string]interface{}, error) {
+	row := make(map[string]interface{}, len(this.columns))
+	for i, col := range this.columns {
+		v, err := ConvertValue(record.Data[col], this.types[i])
+		if err != nil {
+			return nil, &RowError{Line: record.Line, Msg: fmt.Sprintf("列%s：%s", col, err.Error())}
+		}
+		row[this.targets[i]] = v
+	}
+	this.collectDropped(record)
+	return row, nil
+}
+
+// collectDropped 记录样本之后才出现的字段，这些字段没有推断出类型，不写入任何导入目标
+// 指定了映射时只记录映射中的字段，映射之外的字段本就不导入
+func (this *importer) collectDropped(record *Record) {
+	for k := range record.Data {
+		if this.known[k] || this.dropped[k] {
+			continue
+		}
+		if len(this.opts.Mapping) > 0 && this.opts.Mapping[k] == "" {
+			continue
+		}
+		this.dropped[k] = true
+		this.report.DroppedFields = append(this.report.DroppedFields, k)
+	}
+}
+
+// fail 记录行错误，失败数超过阈值时返回错误中止导入
+func (this *importer) fail(rowErr *RowError) error {
+	this.report.Failed++
+	if len(this.report.Errors) < this.opts.MaxErrors {
+		this.report.Errors = append(this.report.Errors, rowErr)
+	}
+	if this.opts.AbortAfterErrors > 0 && this.report.Failed >= this.opts.AbortAfterErrors {
+		return errors.Errorf("失败行数已达%d行，导入中止", this.report.Failed)
+	}
+	return nil
+}
+
+// progress 生成当前进度
+func (this *importer) progress() *Progress {
+	return &Progress{
+		Processed:  this.report.Success + this.report.Failed,
+		Success:    this.report.Success,
+		Failed:     this.report.Failed,
+		ReadBytes:  this.counter.n,
+		TotalBytes: this.totalBytes,
+	}
+}
+
+// push 通过LiveBroadcast推送进度，推送失败不影响导入
+func (this *importer) push(ctx context.Context, progress *Progress, force bool) {
+	if this.opts.ProgressChannel == "" {
+		return
+	}
+	if !force && time.Since(this.lastPush) < this.opts.ProgressInterval {
+		return
+	}
+	this.lastPush = time.Now()
+	ev_api.GetEvApi().LiveBroadcast(ctx, this.opts.ProgressChannel, progress)
+}
+
+// countingReader 统计已读取字节数的Reader
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+// Read 读取数据并累加字节数
+func (this *countingReader) Read(p []byte) (int, error) {
+	n, err := this.r.Read(p)
+	this.n += int64(n)
+	return n, err
+}
+
+// ImportUpload 将gin请求中上传的文件暂存到plugin_server.GetTmpFileStorePath()后导入，结束后删除暂存文件
+// 参数：
+//   - c: gin上下文
+//   - formField: 上传文件的表单字段名
+//   - sink: 导入目标
+//   - opts: 导入选项，Format为空时根据文件扩展名判断
+//
+// 返回：
+//   - *Report: 导入结果报告
+//   - error: 错误信息
+func ImportUpload(c *gin.Context, formField string, sink Sink, opts Options) (*Report, error) {
+	fileHeader, err := c.FormFile(formField)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if opts.Format == "" {
+		opts.Format, err = FormatFromFileName(fileHeader.Filename)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	tmpPath := filepath.Join(plugin_server.GetTmpFileStorePath(),
+		fmt.Sprintf("ev_import_%d%s", time.Now().UnixNano(), filepath.Ext(fileHeader.Filename)))
+	if err = c.SaveUploadedFile(fileHeader, tmpPath); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer os.Remove(tmpPath)
+
+	return ImportFile(c.Request.Context(), tmpPath, sink, opts)
+}
+
+// ImportFile 导入本地文件
+// 参数：
+//   - ctx: 上下文
+//   - filePath: 文件路径
+//   - sink: 导入目标
+//   - opts: 导入选项，Format为空时根据文件扩展名判断
+//
+// 返回：
+//   - *Report: 导入结果报告
+//   - error: 错误信息
+func ImportFile(ctx context.Context, filePath string, sink Sink, opts Options) (*Report, error) {
+	var err error
+	if opts.Format == "" {
+		opts.Format, err = FormatFromFileName(filePath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return Import(ctx, f, stat.Size(), sink, opts)
+}
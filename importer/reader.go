@@ -0,0 +1,197 @@
+// importer包提供文件导入功能，支持将CSV、NDJSON文件批量导入ES索引、MySQL表或MongoDB集合
+package importer
+
+// 导入所需的包
+import (
+	// 带缓冲IO包
+	"bufio"
+	// 字节处理包
+	"bytes"
+	// CSV编码包
+	"encoding/csv"
+	// IO包
+	"io"
+	// 路径处理包
+	"path/filepath"
+	// 字符串处理包
+	"strings"
+
+	// JSON处理包
+	"github.com/goccy/go-json"
+	// 错误处理包
+	"github.com/pkg/errors"
+)
+
+// Format 导入文件格式
+type Format string
+
+// 支持的导入格式
+const (
+	// CSV格式，首行为表头
+	FormatCSV Format = "csv"
+	// NDJSON格式，每行一个JSON对象
+	FormatNDJSON Format = "ndjson"
+)
+
+// FormatFromFileName 根据文件扩展名判断导入格式
+func FormatFromFileName(fileName string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		return FormatCSV, nil
+	case ".ndjson", ".jsonl", ".json":
+		return FormatNDJSON, nil
+	}
+	return "", errors.Errorf("不支持的导入文件类型：%s", fileName)
+}
+
+// Record 读取到的一行数据
+type Record struct {
+	// 行号，从1开始，CSV文件表头为第1行
+	Line int
+	// 行数据
+	Data map[string]interface{}
+}
+
+// RecordReader 行读取器接口，读取完毕时返回io.EOF
+type RecordReader interface {
+	// Columns 返回已知的列顺序
+	Columns() []string
+	// Read 读取一行，解析失败时返回*RowError，调用方可跳过该行继续读取
+	Read() (*Record, error)
+}
+
+// NewRecordReader 根据格式创建行读取器
+// 参数：
+//   - format: 导入格式
+//   - r: 数据来源
+//
+// 返回：
+//   - RecordReader: 行读取器
+//   - error: 错误信息
+func NewRecordReader(format Format, r io.Reader) (RecordReader, error) {
+	switch format {
+	case FormatCSV:
+		return newCsvReader(r)
+	case FormatNDJSON:
+		return newNdjsonReader(r), nil
+	}
+	return nil, errors.Errorf("不支持的导入格式：%s", format)
+}
+
+// csvReader CSV行读取器
+type csvReader struct {
+	r       *csv.Reader
+	columns []string
+	line    int
+}
+
+// newCsvReader 创建CSV行读取器并读取表头
+func newCsvReader(r io.Reader) (*csvReader, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, errors.New("CSV文件为空")
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	columns := make([]string, len(header))
+	for i, col := range header {
+		if i == 0 {
+			col = strings.TrimPrefix(col, "\xEF\xBB\xBF")
+		}
+		columns[i] = strings.TrimSpace(col)
+	}
+
+	return &csvReader{r: cr, columns: columns, line: 1}, nil
+}
+
+// Columns 返回表头
+func (this *csvReader) Columns() []string {
+	return this.columns
+}
+
+// Read 读取一行
+func (this *csvReader) Read() (*Record, error) {
+	record, err := this.r.Read()
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+	this.line++
+	if err != nil {
+		if _, ok := err.(*csv.ParseError); ok {
+			return nil, &RowError{Line: this.line, Msg: err.Error()}
+		}
+		return nil, errors.WithStack(err)
+	}
+	if len(record) > len(this.columns) {
+		return nil, &RowError{Line: this.line, Msg: "列数多于表头"}
+	}
+
+	data := make(map[string]interface{}, len(this.columns))
+	for i, val := range record {
+		data[this.columns[i]] = val
+	}
+	return &Record{Line: this.line, Data: data}, nil
+}
+
+// ndjsonReader NDJSON行读取器
+type ndjsonReader struct {
+	r       *bufio.Reader
+	columns []string
+	seen    map[string]struct{}
+	line    int
+}
+
+// newNdjsonReader 创建NDJSON行读取器
+func newNdjsonReader(r io.Reader) *ndjsonReader {
+	return &ndjsonReader{r: bufio.NewReaderSize(r, 64*1024), seen: map[string]struct{}{}}
+}
+
+// Columns 返回已读取的行中出现过的字段
+func (this *ndjsonReader) Columns() []string {
+	return this.columns
+}
+
+// Read 读取一行，空行会被跳过
+func (this *ndjsonReader) Read() (*Record, error) {
+	for {
+		line, err := this.r.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return nil, errors.WithStack(err)
+		}
+		if len(line) == 0 && err == io.EOF {
+			return nil, io.EOF
+		}
+		this.line++
+
+		line = bytes.TrimSpace(line)
+		if this.line == 1 {
+			line = bytes.TrimPrefix(line, []byte("\xEF\xBB\xBF"))
+		}
+		if len(line) == 0 {
+			if err == io.EOF {
+				return nil, io.EOF
+			}
+			continue
+		}
+
+		data := map[string]interface{}{}
+		dec := json.NewDecoder(bytes.NewReader(line))
+		dec.UseNumber()
+		if decodeErr := dec.Decode(&data); decodeErr != nil {
+			return nil, &RowError{Line: this.line, Msg: decodeErr.Error()}
+		}
+		for col := range data {
+			if _, ok := this.seen[col]; !ok {
+				this.seen[col] = struct{}{}
+				this.columns = append(this.columns, col)
+			}
+		}
+		return &Record{Line: this.line, Data: data}, nil
+	}
+}
@@ -0,0 +1,261 @@
+// importer包提供文件导入功能，支持将CSV、NDJSON文件批量导入ES索引、MySQL表或MongoDB集合
+package importer
+
+// 导入所需的包
+import (
+	// 格式化包
+	"fmt"
+	// 字符串转换包
+	"strconv"
+	// 字符串处理包
+	"strings"
+	// 时间处理包
+	"time"
+
+	// JSON处理包
+	"github.com/goccy/go-json"
+)
+
+// FieldType 推断出的字段类型
+type FieldType string
+
+// 支持推断的字段类型
+const (
+	// 字符串
+	FieldTypeString FieldType = "string"
+	// 整数
+	FieldTypeInteger FieldType = "integer"
+	// 浮点数
+	FieldTypeFloat FieldType = "float"
+	// 布尔值
+	FieldTypeBoolean FieldType = "boolean"
+	// 日期时间
+	FieldTypeDatetime FieldType = "datetime"
+	// 对象或数组，仅NDJSON可能出现
+	FieldTypeObject FieldType = "object"
+)
+
+// DatetimeLayouts 推断日期时间类型时尝试的格式
+var DatetimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04:05.000",
+	"2006/01/02 15:04:05",
+	"2006-01-02",
+	"2006/01/02",
+}
+
+// Field 字段定义
+type Field struct {
+	// 字段名
+	Name string `json:"name"`
+	// 字段类型
+	Type FieldType `json:"type"`
+	// 样本中是否出现过空值
+	Nullable bool `json:"nullable"`
+}
+
+// Schema 推断出的表结构
+type Schema struct {
+	// 字段列表，与文件中的列顺序一致
+	Fields []Field `json:"fields"`
+}
+
+// Field 按名称获取字段
+func (this *Schema) Field(name string) (Field, bool) {
+	for _, f := range this.Fields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return Field{}, false
+}
+
+// EsMapping 将表结构转换为ES mapping，可直接作为EsCreateIndex的mappings
+func (this *Schema) EsMapping() map[string]interface{} {
+	properties := map[string]interface{}{}
+	for _, f := range this.Fields {
+		switch f.Type {
+		case FieldTypeInteger:
+			properties[f.Name] = map[string]interface{}{"type": "long"}
+		case FieldTypeFloat:
+			properties[f.Name] = map[string]interface{}{"type": "double"}
+		case FieldTypeBoolean:
+			properties[f.Name] = map[string]interface{}{"type": "boolean"}
+		case FieldTypeDatetime:
+			properties[f.Name] = map[string]interface{}{"type": "date"}
+		case FieldTypeObject:
+			properties[f.Name] = map[string]interface{}{"type": "object", "enabled": false}
+		default:
+			properties[f.Name] = map[string]interface{}{
+				"type":   "text",
+				"fields": map[string]interface{}{"keyword": map[string]interface{}{"type": "keyword", "ignore_above": 256}},
+			}
+		}
+	}
+	return map[string]interface{}{"properties": properties}
+}
+
+// typeStat 单个字段的类型统计
+type typeStat struct {
+	counts   map[FieldType]int
+	nullable bool
+}
+
+// InferSchema 根据样本推断表结构
+// 参数：
+//   - columns: 列顺序
+//   - samples: 样本数据
+//
+// 返回：
+//   - *Schema: 推断出的表结构
+func InferSchema(columns []string, samples []map[string]interface{}) *Schema {
+	stats := make(map[string]*typeStat, len(columns))
+	for _, col := range columns {
+		stats[col] = &typeStat{counts: map[FieldType]int{}}
+	}
+
+	for _, row := range samples {
+		for _, col := range columns {
+			stat := stats[col]
+			v, ok := row[col]
+			if !ok || isEmpty(v) {
+				stat.nullable = true
+				continue
+			}
+			stat.counts[detectType(v)]++
+		}
+	}
+
+	schema := &Schema{Fields: make([]Field, 0, len(columns))}
+	for _, col := range columns {
+		stat := stats[col]
+		schema.Fields = append(schema.Fields, Field{Name: col, Type: mergeTypes(stat.counts), Nullable: stat.nullable})
+	}
+	return schema
+}
+
+// isEmpty 判断值是否为空
+func isEmpty(v interface{}) bool {
+	switch val := v.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(val) == ""
+	}
+	return false
+}
+
+// detectType 检测单个值的类型
+func detectType(v interface{}) FieldType {
+	switch val := v.(type) {
+	case bool:
+		return FieldTypeBoolean
+	case json.Number:
+		if _, err := val.Int64(); err == nil {
+			return FieldTypeInteger
+		}
+		return FieldTypeFloat
+	case float64, float32:
+		return FieldTypeFloat
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return FieldTypeInteger
+	case map[string]interface{}, []interface{}:
+		return FieldTypeObject
+	case string:
+		s := strings.TrimSpace(val)
+		if _, err := strconv.ParseInt(s, 10, 64); err == nil {
+			// 以0开头的多位数字（如编号、邮编）按字符串处理，避免丢失前导0
+			if len(s) > 1 && s[0] == '0' {
+				return FieldTypeString
+			}
+			return FieldTypeInteger
+		}
+		if _, err := strconv.ParseFloat(s, 64); err == nil {
+			return FieldTypeFloat
+		}
+		if _, err := parseBool(s); err == nil {
+			return FieldTypeBoolean
+		}
+		if _, err := parseDatetime(s); err == nil {
+			return FieldTypeDatetime
+		}
+	}
+	return FieldTypeString
+}
+
+// mergeTypes 合并样本中出现的类型，整数与浮点数合并为浮点数，其余冲突按字符串处理
+func mergeTypes(counts map[FieldType]int) FieldType {
+	if len(counts) == 0 {
+		return FieldTypeString
+	}
+	if len(counts) == 1 {
+		for t := range counts {
+			return t
+		}
+	}
+	if len(counts) == 2 && counts[FieldTypeInteger] > 0 && counts[FieldTypeFloat] > 0 {
+		return FieldTypeFloat
+	}
+	return FieldTypeString
+}
+
+// parseBool 解析布尔值
+func parseBool(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid bool: %s", s)
+}
+
+// parseDatetime 按DatetimeLayouts解析日期时间
+func parseDatetime(s string) (time.Time, error) {
+	for _, layout := range DatetimeLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid datetime: %s", s)
+}
+
+// ConvertValue 按字段类型转换值，空字符串转换为nil
+// 参数：
+//   - v: 原始值
+//   - t: 目标类型
+//
+// 返回：
+//   - interface{}: 转换后的值
+//   - error: 转换失败时的错误
+func ConvertValue(v interface{}, t FieldType) (interface{}, error) {
+	if isEmpty(v) {
+		return nil, nil
+	}
+
+	switch val := v.(type) {
+	case string:
+		s := strings.TrimSpace(val)
+		switch t {
+		case FieldTypeInteger:
+			return strconv.ParseInt(s, 10, 64)
+		case FieldTypeFloat:
+			return strconv.ParseFloat(s, 64)
+		case FieldTypeBoolean:
+			return parseBool(s)
+		case FieldTypeDatetime:
+			return parseDatetime(s)
+		}
+		return val, nil
+	case json.Number:
+		switch t {
+		case FieldTypeInteger:
+			return val.Int64()
+		case FieldTypeString:
+			return val.String(), nil
+		}
+		return val.Float64()
+	}
+	return v, nil
+}
@@ -0,0 +1,198 @@
+// importer包提供文件导入功能，支持将CSV、NDJSON文件批量导入ES索引、MySQL表或MongoDB集合
+package importer
+
+// 导入所需的包
+import (
+	// 字节处理包
+	"bytes"
+	// 上下文包
+	"context"
+	// HTTP包
+	"net/http"
+	// 时间处理包
+	"time"
+
+	// MongoDB BSON包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/bson"
+	// 数据源接口包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/pkg"
+	// JSON处理包
+	"github.com/goccy/go-json"
+	// 错误处理包
+	"github.com/pkg/errors"
+	// 类型转换包
+	"github.com/spf13/cast"
+	// JSON路径解析包
+	"github.com/tidwall/gjson"
+)
+
+// Sink 导入目标接口
+type Sink interface {
+	// WriteBatch 批量写入，返回与rows下标一一对应的行错误（成功的行为nil），err表示整批不可恢复的错误
+	WriteBatch(ctx context.Context, columns []string, rows []map[string]interface{}) (rowErrs []error, err error)
+}
+
+// EsBulkSink 通过_bulk接口写入ES索引
+type EsBulkSink struct {
+	api       pkg.ClientInterface
+	indexName string
+	idField   string
+}
+
+// NewEsBulkSink 创建ES导入目标
+// 参数：
+//   - api: 数据源接口
+//   - indexName: 索引名称
+//   - idField: 作为文档_id的字段，为空时由ES生成
+//
+// 返回：
+//   - *EsBulkSink: ES导入目标
+func NewEsBulkSink(api pkg.ClientInterface, indexName, idField string) *EsBulkSink {
+	return &EsBulkSink{api: api, indexName: indexName, idField: idField}
+}
+
+// WriteBatch 批量写入文档
+func (this *EsBulkSink) WriteBatch(ctx context.Context, columns []string, rows []map[string]interface{}) (rowErrs []error, err error) {
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	for _, row := range rows {
+		meta := map[string]interface{}{"_index": this.indexName}
+		if this.idField != "" {
+			if id, ok := row[this.idField]; ok && id != nil {
+				meta["_id"] = cast.ToString(id)
+			}
+		}
+		if err = enc.Encode(map[string]interface{}{"index": meta}); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if err = enc.Encode(row); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	req, err := http.NewRequest(http.MethodPost, "/_bulk", &body)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+
+	res, err := this.api.EsPerformRequest(ctx, req)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err = res.StatusErr(); err != nil {
+		return nil, err
+	}
+
+	rowErrs = make([]error, len(rows))
+	resBody := res.ResByte()
+	if !gjson.GetBytes(resBody, "errors").Bool() {
+		return rowErrs, nil
+	}
+	for i, item := range gjson.GetBytes(resBody, "items").Array() {
+		if i >= len(rows) {
+			break
+		}
+		if reason := item.Get("index.error"); reason.Exists() {
+			rowErrs[i] = errors.New(reason.Get("reason").String())
+		}
+	}
+	return rowErrs, nil
+}
+
+// MysqlSink 通过BatchInsertData写入MySQL表
+type MysqlSink struct {
+	api       pkg.ClientInterface
+	dbName    string
+	tableName string
+}
+
+// NewMysqlSink 创建MySQL导入目标
+// 参数：
+//   - api: 数据源接口
+//   - dbName: 数据库名称
+//   - tableName: 表名
+//
+// 返回：
+//   - *MysqlSink: MySQL导入目标
+func NewMysqlSink(api pkg.ClientInterface, dbName, tableName string) *MysqlSink {
+	return &MysqlSink{api: api, dbName: dbName, tableName: tableName}
+}
+
+// WriteBatch 批量写入，整批失败时逐行重试以定位出错的行
+func (this *MysqlSink) WriteBatch(ctx context.Context, columns []string, rows []map[string]interface{}) (rowErrs []error, err error) {
+	data := make([][]interface{}, 0, len(rows))
+	for _, row := range rows {
+		data = append(data, this.values(columns, row))
+	}
+
+	rowErrs = make([]error, len(rows))
+	if err = this.api.BatchInsertData(ctx, this.dbName, this.tableName, columns, data); err == nil {
+		return rowErrs, nil
+	}
+	if len(rows) == 1 {
+		rowErrs[0] = err
+		return rowErrs, nil
+	}
+
+	for i, values := range data {
+		if err = ctx.Err(); err != nil {
+			return nil, err
+		}
+		rowErrs[i] = this.api.BatchInsertData(ctx, this.dbName, this.tableName, columns, [][]interface{}{values})
+	}
+	return rowErrs, nil
+}
+
+// values 按列顺序取出一行的值，时间转换为MySQL可接受的格式
+func (this *MysqlSink) values(columns []string, row map[string]interface{}) []interface{} {
+	values := make([]interface{}, 0, len(columns))
+	for _, col := range columns {
+		v := row[col]
+		switch val := v.(type) {
+		case time.Time:
+			v = val.Format("2006-01-02 15:04:05")
+		case map[string]interface{}, []interface{}:
+			b, _ := json.Marshal(val)
+			v = string(b)
+		}
+		values = append(values, v)
+	}
+	return values
+}
+
+// MongoSink 通过InsertManyMongoDocuments写入MongoDB集合
+type MongoSink struct {
+	api            pkg.ClientInterface
+	dbName         string
+	collectionName string
+}
+
+// NewMongoSink 创建MongoDB导入目标
+// 参数：
+//   - api: 数据源接口
+//   - dbName: 数据库名称
+//   - collectionName: 集合名称
+//
+// 返回：
+//   - *MongoSink: MongoDB导入目标
+func NewMongoSink(api pkg.ClientInterface, dbName, collectionName string) *MongoSink {
+	return &MongoSink{api: api, dbName: dbName, collectionName: collectionName}
+}
+
+// WriteBatch 批量写入文档
+// insertMany失败时可能已有部分文档写入，逐条重试会产生重复数据，因此整批标记为失败
+func (this *MongoSink) WriteBatch(ctx context.Context, columns []string, rows []map[string]interface{}) (rowErrs []error, err error) {
+	docs := make([]bson.M, 0, len(rows))
+	for _, row := range rows {
+		docs = append(docs, row)
+	}
+
+	rowErrs = make([]error, len(rows))
+	if _, err = this.api.MongoInsertManyDocuments(ctx, this.dbName, this.collectionName, docs); err != nil {
+		for i := range rowErrs {
+			rowErrs[i] = err
+		}
+	}
+	return rowErrs, nil
+}
@@ -0,0 +1,258 @@
+package repository
+
+// 导入所需的包
+import (
+	// 数据库接口包
+	"database/sql"
+	// 数据库驱动接口包
+	"database/sql/driver"
+	// 编码接口包
+	"encoding"
+	// 反射包
+	"reflect"
+	// 字符串处理包
+	"strings"
+	// 并发控制包
+	"sync"
+	// 时间处理包
+	"time"
+
+	// 结构体扫描包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/scan"
+	// SQL驱动包
+	"github.com/1340691923/eve-plugin-sdk-go/sql_driver"
+	// JSON处理包
+	"github.com/goccy/go-json"
+	// 错误处理包
+	"github.com/pkg/errors"
+)
+
+// 标签选项
+const (
+	// tagPk 主键
+	tagPk = "pk"
+	// tagAutoIncr 自增列，插入时不写入零值，插入后回填
+	tagAutoIncr = "autoincr"
+	// tagSoftDelete 软删除标记列
+	tagSoftDelete = "softdelete"
+)
+
+// timeType time.Time的类型
+var timeType = reflect.TypeOf(time.Time{})
+
+// column 结构体字段对应的列
+type column struct {
+	// 列名
+	name string
+	// 字段索引
+	index []int
+	// 字段类型
+	typ reflect.Type
+	// 是否主键
+	pk bool
+	// 是否自增
+	autoIncr bool
+	// 是否软删除标记
+	softDelete bool
+}
+
+// tableMeta 结构体的列信息
+type tableMeta struct {
+	columns    []*column
+	pks        []*column
+	autoIncr   *column
+	softDelete *column
+}
+
+// metaCache 结构体类型 => 列信息
+var metaCache sync.Map
+
+// metaOf 解析结构体的列信息，字段命名规则与scan包一致
+// 标签格式为 `db:"列名,选项..."`，选项为pk、autoincr、softdelete
+func metaOf(t reflect.Type) (*tableMeta, error) {
+	if cached, ok := metaCache.Load(t); ok {
+		return cached.(*tableMeta), nil
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, errors.Errorf("Repository的类型参数必须是结构体，实际为%s", t)
+	}
+	meta := &tableMeta{}
+	seen := map[string]int{}
+	collectColumns(t, nil, meta, seen)
+	for _, col := range meta.columns {
+		if col.pk {
+			meta.pks = append(meta.pks, col)
+		}
+		if col.autoIncr {
+			if meta.autoIncr != nil {
+				return nil, errors.Errorf("%s: 只能有一个autoincr字段", t)
+			}
+			meta.autoIncr = col
+		}
+		if col.softDelete {
+			if meta.softDelete != nil {
+				return nil, errors.Errorf("%s: 只能有一个softdelete字段", t)
+			}
+			if softDeleteKind(col.typ) == "" {
+				return nil, errors.Errorf("%s: softdelete字段%s的类型必须是*time.Time、sql.NullTime、整数或bool", t, col.name)
+			}
+			meta.softDelete = col
+		}
+	}
+	if len(meta.pks) == 0 {
+		return nil, errors.Errorf("%s: 缺少pk字段", t)
+	}
+	metaCache.Store(t, meta)
+	return meta, nil
+}
+
+// collectColumns 递归收集列，嵌入的匿名结构体会被展开，外层字段优先
+func collectColumns(t reflect.Type, parent []int, meta *tableMeta, seen map[string]int) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get(scan.TagName)
+		if tag == "-" {
+			continue
+		}
+		opts := strings.Split(tag, ",")
+		name := strings.TrimSpace(opts[0])
+
+		index := make([]int, len(parent)+1)
+		copy(index, parent)
+		index[len(parent)] = i
+
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct && ft != timeType {
+			collectColumns(ft, index, meta, seen)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = scan.SnakeCase(f.Name)
+		}
+		col := &column{name: name, index: index, typ: f.Type}
+		for _, opt := range opts[1:] {
+			switch strings.TrimSpace(opt) {
+			case tagPk:
+				col.pk = true
+			case tagAutoIncr:
+				col.autoIncr = true
+			case tagSoftDelete:
+				col.softDelete = true
+			}
+		}
+		if pos, ok := seen[name]; ok {
+			if len(meta.columns[pos].index) <= len(index) {
+				continue
+			}
+			meta.columns[pos] = col
+			continue
+		}
+		seen[name] = len(meta.columns)
+		meta.columns = append(meta.columns, col)
+	}
+}
+
+// softDeleteKind 返回软删除字段的类别：time表示NULL为未删除，flag表示0为未删除
+func softDeleteKind(t reflect.Type) string {
+	if t == reflect.TypeOf(sql.NullTime{}) || (t.Kind() == reflect.Ptr && t.Elem() == timeType) {
+		return "time"
+	}
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "flag"
+	}
+	return ""
+}
+
+// field 返回实体中列对应的字段，读取时嵌入的nil结构体指针返回无效值
+func (this *column) field(entity reflect.Value) reflect.Value {
+	v := entity
+	for i, x := range this.index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+// value 返回列在实体中的值，已转换为可发送给基座的参数
+func (this *column) value(entity reflect.Value) (interface{}, error) {
+	v := this.field(entity)
+	if !v.IsValid() {
+		return nil, nil
+	}
+	value, err := dbValue(v)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "列%s", this.name)
+	}
+	return value, nil
+}
+
+// isZero 判断列在实体中是否为零值
+func (this *column) isZero(entity reflect.Value) bool {
+	v := this.field(entity)
+	return !v.IsValid() || v.IsZero()
+}
+
+// dbValue 将字段值转换为基座接口的参数
+// driver.Valuer按其Value返回，nil指针为NULL，时间按sql_driver.TimeFormat格式化，
+// encoding.TextMarshaler按其文本，结构体、map和切片（[]byte除外）编码为JSON字符串，与scan包的读取规则对应
+func dbValue(v reflect.Value) (interface{}, error) {
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		return nil, nil
+	}
+	if valuer, ok := v.Interface().(driver.Valuer); ok {
+		value, err := valuer.Value()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if t, ok := value.(time.Time); ok {
+			return t.Format(sql_driver.TimeFormat), nil
+		}
+		return value, nil
+	}
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Type() == timeType {
+		return v.Interface().(time.Time).Format(sql_driver.TimeFormat), nil
+	}
+	if marshaler, ok := v.Interface().(encoding.TextMarshaler); ok {
+		b, err := marshaler.MarshalText()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return string(b), nil
+	}
+	switch v.Kind() {
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil, nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return string(v.Bytes()), nil
+		}
+		fallthrough
+	case reflect.Struct, reflect.Map, reflect.Array:
+		if v.Kind() == reflect.Map && v.IsNil() {
+			return nil, nil
+		}
+		b, err := json.Marshal(v.Interface())
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return string(b), nil
+	}
+	return v.Interface(), nil
+}
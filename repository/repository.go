@@ -0,0 +1,581 @@
+// repository包提供基于插件存储的泛型仓储，通过`db`标签将结构体映射到表
+//
+// 标签格式为 `db:"列名,选项..."`，列名规则与scan包一致，选项：
+//   - pk: 主键，可以有多个
+//   - autoincr: 自增列，零值时插入不写入该列，插入后回填自增ID
+//   - softdelete: 软删除标记列，类型为*time.Time、sql.NullTime（NULL为未删除）或整数、bool（0为未删除），
+//     查询时自动过滤已删除的记录
+//
+// 所有操作都通过ev_api的Store方法执行，SQL按plugin_server.DbType区分sqlite3和mysql。
+//
+// 示例用法:
+//
+//	type User struct {
+//		Id        int64      `db:"id,pk,autoincr"`
+//		Name      string     `db:"name"`
+//		Age       int        `db:"age"`
+//		DeletedAt *time.Time `db:"deleted_at,softdelete"`
+//	}
+//
+//	users := repository.New[User]("users")
+//	id, err := users.Insert(ctx, &User{Name: "ev"})
+//	list, err := users.FindWhere(ctx, sql_builder.Gt{"age": 18}, "id DESC")
+//	page, err := users.Paginate(ctx, nil, 1, 20, "id DESC")
+package repository
+
+// 导入所需的包
+import (
+	// 上下文包
+	"context"
+	// 反射包
+	"reflect"
+	// 字符串处理包
+	"strings"
+	// 时间处理包
+	"time"
+
+	// EVE API包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api"
+	// 结构体扫描包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/scan"
+	// SQL构建包
+	"github.com/1340691923/eve-plugin-sdk-go/sql_builder"
+	// SQL驱动包
+	"github.com/1340691923/eve-plugin-sdk-go/sql_driver"
+	// SQL构建库
+	"github.com/Masterminds/squirrel"
+	// 错误处理包
+	"github.com/pkg/errors"
+)
+
+// ErrNotFound 记录不存在
+var ErrNotFound = scan.ErrNoRows
+
+// Page 分页查询结果
+type Page[T any] struct {
+	// 当前页的记录
+	List []T `json:"list"`
+	// 符合条件的记录总数
+	Total int64 `json:"total"`
+	// 页码，从1开始
+	Page int `json:"page"`
+	// 每页记录数
+	PageSize int `json:"page_size"`
+}
+
+// Repository 泛型仓储，T为带`db`标签的结构体
+type Repository[T any] struct {
+	table       string
+	meta        *tableMeta
+	err         error
+	store       store
+	inTx        bool
+	withDeleted bool
+}
+
+// New 创建仓储，T的标签有误时在首次操作时返回错误
+// 参数：
+//   - table: 表名
+//
+// 返回：
+//   - *Repository[T]: 仓储
+func New[T any](table string) *Repository[T] {
+	meta, err := metaOf(reflect.TypeOf((*T)(nil)).Elem())
+	return &Repository[T]{table: table, meta: meta, err: err, store: apiStore{}}
+}
+
+// WithTx 返回在tx内执行的仓储副本，例如在ev_api.StoreTx的回调中使用
+func (this *Repository[T]) WithTx(tx ev_api.StoreTx) *Repository[T] {
+	clone := *this
+	clone.store = tx
+	clone.inTx = true
+	return &clone
+}
+
+// WithDeleted 返回查询时包含已软删除记录的仓储副本
+func (this *Repository[T]) WithDeleted() *Repository[T] {
+	clone := *this
+	clone.withDeleted = true
+	return &clone
+}
+
+// Table 返回表名
+func (this *Repository[T]) Table() string {
+	return this.table
+}
+
+// quote 按插件存储的方言为标识符加引号
+func (this *Repository[T]) quote(name string) string {
+	return sql_builder.Quote(name)
+}
+
+// selectBuilder 返回查询全部列的构建器，已加上软删除过滤
+func (this *Repository[T]) selectBuilder(columns ...string) squirrel.SelectBuilder {
+	if len(columns) == 0 {
+		for _, col := range this.meta.columns {
+			columns = append(columns, this.quote(col.name))
+		}
+	}
+	builder := sql_builder.SqlBuilder.Select(columns...).From(this.quote(this.table))
+	if filter := this.notDeleted(); filter != nil {
+		builder = builder.Where(filter)
+	}
+	return builder
+}
+
+// notDeleted 返回过滤已软删除记录的条件，无需过滤时返回nil
+func (this *Repository[T]) notDeleted() squirrel.Sqlizer {
+	if this.withDeleted || this.meta.softDelete == nil {
+		return nil
+	}
+	return squirrel.Expr(this.notDeletedSql())
+}
+
+// notDeletedSql 返回记录未被软删除的条件
+func (this *Repository[T]) notDeletedSql() string {
+	col := this.meta.softDelete
+	if softDeleteKind(col.typ) == "time" {
+		return this.quote(col.name) + " IS NULL"
+	}
+	return this.quote(col.name) + " = 0"
+}
+
+// pkWhere 返回按主键定位记录的条件
+func (this *Repository[T]) pkWhere(ids ...interface{}) (string, []interface{}, error) {
+	if len(ids) != len(this.meta.pks) {
+		return "", nil, errors.Errorf("表%s有%d个主键列，传入了%d个值", this.table, len(this.meta.pks), len(ids))
+	}
+	conds := make([]string, len(ids))
+	for i, col := range this.meta.pks {
+		conds[i] = this.quote(col.name) + " = ?"
+	}
+	return strings.Join(conds, " AND "), ids, nil
+}
+
+// entityPk 返回实体的主键值
+func (this *Repository[T]) entityPk(entity reflect.Value) ([]interface{}, error) {
+	ids := make([]interface{}, len(this.meta.pks))
+	for i, col := range this.meta.pks {
+		if col.isZero(entity) {
+			return nil, errors.Errorf("主键%s为零值", col.name)
+		}
+		v, err := col.value(entity)
+		if err != nil {
+			return nil, err
+		}
+		ids[i] = v
+	}
+	return ids, nil
+}
+
+// query 执行查询并扫描为[]T
+func (this *Repository[T]) query(ctx context.Context, builder squirrel.SelectBuilder) ([]T, error) {
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	res, err := this.store.SelectRows(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	return scan.Scan[T](res.Result, res.ColumnTypes)
+}
+
+// FindByID 按主键查询，复合主键按标签顺序传入各列的值，记录不存在时返回ErrNotFound
+// 参数：
+//   - ctx: 上下文
+//   - ids: 主键值
+//
+// 返回：
+//   - T: 查询结果
+//   - err: 错误信息
+func (this *Repository[T]) FindByID(ctx context.Context, ids ...interface{}) (T, error) {
+	var zero T
+	if this.err != nil {
+		return zero, this.err
+	}
+	where, args, err := this.pkWhere(ids...)
+	if err != nil {
+		return zero, err
+	}
+	list, err := this.query(ctx, this.selectBuilder().Where(where, args...).Limit(1))
+	if err != nil {
+		return zero, err
+	}
+	if len(list) == 0 {
+		return zero, ErrNotFound
+	}
+	return list[0], nil
+}
+
+// FindWhere 按条件查询
+// 参数：
+//   - ctx: 上下文
+//   - cond: 查询条件，例如 sql_builder.Eq{"status": 1}，为nil时查询全部
+//   - orderBy: 排序，例如 "id DESC"
+//
+// 返回：
+//   - []T: 查询结果
+//   - err: 错误信息
+func (this *Repository[T]) FindWhere(ctx context.Context, cond squirrel.Sqlizer, orderBy ...string) ([]T, error) {
+	if this.err != nil {
+		return nil, this.err
+	}
+	builder := this.selectBuilder().OrderBy(orderBy...)
+	if cond != nil {
+		builder = builder.Where(cond)
+	}
+	return this.query(ctx, builder)
+}
+
+// FirstWhere 按条件查询第一条记录，记录不存在时返回ErrNotFound
+func (this *Repository[T]) FirstWhere(ctx context.Context, cond squirrel.Sqlizer, orderBy ...string) (T, error) {
+	var zero T
+	if this.err != nil {
+		return zero, this.err
+	}
+	builder := this.selectBuilder().OrderBy(orderBy...).Limit(1)
+	if cond != nil {
+		builder = builder.Where(cond)
+	}
+	list, err := this.query(ctx, builder)
+	if err != nil {
+		return zero, err
+	}
+	if len(list) == 0 {
+		return zero, ErrNotFound
+	}
+	return list[0], nil
+}
+
+// Count 按条件统计记录数
+// 参数：
+//   - ctx: 上下文
+//   - cond: 查询条件，为nil时统计全部
+//
+// 返回：
+//   - int64: 记录数
+//   - err: 错误信息
+func (this *Repository[T]) Count(ctx context.Context, cond squirrel.Sqlizer) (int64, error) {
+	if this.err != nil {
+		return 0, this.err
+	}
+	builder := this.selectBuilder("COUNT(*) AS total")
+	if cond != nil {
+		builder = builder.Where(cond)
+	}
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	res, err := this.store.SelectRows(ctx, sql, args...)
+	if err != nil {
+		return 0, err
+	}
+	if len(res.Result) == 0 {
+		return 0, nil
+	}
+	return scan.ScanOne[int64](res.Result[0], res.ColumnTypes)
+}
+
+// Paginate 按条件分页查询，同时返回记录总数
+// 参数：
+//   - ctx: 上下文
+//   - cond: 查询条件，为nil时查询全部
+//   - page: 页码，从1开始
+//   - pageSize: 每页记录数
+//   - orderBy: 排序，分页时应指定以保证结果稳定
+//
+// 返回：
+//   - *Page[T]: 分页结果
+//   - err: 错误信息
+func (this *Repository[T]) Paginate(ctx context.Context, cond squirrel.Sqlizer, page, pageSize int, orderBy ...string) (*Page[T], error) {
+	if this.err != nil {
+		return nil, this.err
+	}
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		return nil, errors.Errorf("每页记录数必须大于0，实际为%d", pageSize)
+	}
+	total, err := this.Count(ctx, cond)
+	if err != nil {
+		return nil, err
+	}
+	res := &Page[T]{List: []T{}, Total: total, Page: page, PageSize: pageSize}
+	offset := sql_builder.CreatePage(page, pageSize)
+	if total == 0 || offset >= uint64(total) {
+		return res, nil
+	}
+	builder := this.selectBuilder().OrderBy(orderBy...).Limit(uint64(pageSize)).Offset(offset)
+	if cond != nil {
+		builder = builder.Where(cond)
+	}
+	if res.List, err = this.query(ctx, builder); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Insert 插入记录，有autoincr列时返回自增ID并回填到entity，否则返回0
+// 获取自增ID需要与插入在同一连接上，未在事务中时会开启一个插件存储事务
+// 参数：
+//   - ctx: 上下文
+//   - entity: 要插入的记录
+//
+// 返回：
+//   - id: 自增ID
+//   - err: 错误信息
+func (this *Repository[T]) Insert(ctx context.Context, entity *T) (id int64, err error) {
+	if this.err != nil {
+		return 0, this.err
+	}
+	v := reflect.ValueOf(entity).Elem()
+	columns := make([]string, 0, len(this.meta.columns))
+	values := make([]interface{}, 0, len(this.meta.columns))
+	for _, col := range this.meta.columns {
+		if col.autoIncr && col.isZero(v) {
+			continue
+		}
+		value, err := col.value(v)
+		if err != nil {
+			return 0, err
+		}
+		columns = append(columns, this.quote(col.name))
+		values = append(values, value)
+	}
+	sql, args, err := sql_builder.SqlBuilder.Insert(this.quote(this.table)).Columns(columns...).Values(values...).ToSql()
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	autoIncr := this.meta.autoIncr
+	if autoIncr == nil || !autoIncr.isZero(v) {
+		_, err = this.store.Exec(ctx, sql, args...)
+		return 0, err
+	}
+
+	insert := func(s store) error {
+		if _, err := s.Exec(ctx, sql, args...); err != nil {
+			return err
+		}
+		id, err = this.lastInsertId(ctx, s)
+		return err
+	}
+	if this.inTx {
+		err = insert(this.store)
+	} else {
+		err = ev_api.GetEvApi().StoreTx(ctx, func(tx ev_api.StoreTx) error {
+			return insert(tx)
+		})
+	}
+	if err != nil {
+		return 0, err
+	}
+	if err = setInt(autoIncr.field(v), id); err != nil {
+		return id, errors.WithMessagef(err, "回填%s", autoIncr.name)
+	}
+	return id, nil
+}
+
+// lastInsertId 查询同一连接上最后插入的自增ID
+func (this *Repository[T]) lastInsertId(ctx context.Context, s store) (int64, error) {
+	sql := "SELECT last_insert_rowid() AS id"
+	if sql_builder.CurrentDialect() == sql_builder.DialectMysql {
+		sql = "SELECT LAST_INSERT_ID() AS id"
+	}
+	res, err := s.SelectRows(ctx, sql)
+	if err != nil {
+		return 0, err
+	}
+	if len(res.Result) == 0 {
+		return 0, errors.New("未获取到自增ID")
+	}
+	return scan.ScanOne[int64](res.Result[0], res.ColumnTypes)
+}
+
+// setInt 将自增ID写入整数字段
+func setInt(field reflect.Value, id int64) error {
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		field.SetInt(id)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		field.SetUint(uint64(id))
+	default:
+		return errors.Errorf("自增字段的类型必须是整数，实际为%s", field.Type())
+	}
+	return nil
+}
+
+// Update 对比original和updated，只更新有变化的列，按updated的主键定位记录，没有变化时不执行
+// 参数：
+//   - ctx: 上下文
+//   - original: 修改前的记录，通常为查询得到的记录
+//   - updated: 修改后的记录
+//
+// 返回：
+//   - rowsAffected: 影响的行数
+//   - err: 错误信息
+func (this *Repository[T]) Update(ctx context.Context, original, updated *T) (rowsAffected int64, err error) {
+	if this.err != nil {
+		return 0, this.err
+	}
+	ov, uv := reflect.ValueOf(original).Elem(), reflect.ValueOf(updated).Elem()
+	data := map[string]interface{}{}
+	for _, col := range this.meta.columns {
+		if col.pk {
+			continue
+		}
+		before, err := col.value(ov)
+		if err != nil {
+			return 0, err
+		}
+		after, err := col.value(uv)
+		if err != nil {
+			return 0, err
+		}
+		if !reflect.DeepEqual(before, after) {
+			data[col.name] = after
+		}
+	}
+	if len(data) == 0 {
+		return 0, nil
+	}
+	return this.updateByPk(ctx, uv, data)
+}
+
+// UpdateColumns 按entity的主键更新指定列，columns为空时更新全部非主键列
+// 参数：
+//   - ctx: 上下文
+//   - entity: 记录
+//   - columns: 要更新的列名
+//
+// 返回：
+//   - rowsAffected: 影响的行数
+//   - err: 错误信息
+func (this *Repository[T]) UpdateColumns(ctx context.Context, entity *T, columns ...string) (rowsAffected int64, err error) {
+	if this.err != nil {
+		return 0, this.err
+	}
+	v := reflect.ValueOf(entity).Elem()
+	wanted := map[string]bool{}
+	for _, name := range columns {
+		wanted[name] = true
+	}
+	data := map[string]interface{}{}
+	for _, col := range this.meta.columns {
+		if col.pk || (len(wanted) > 0 && !wanted[col.name]) {
+			continue
+		}
+		delete(wanted, col.name)
+		if data[col.name], err = col.value(v); err != nil {
+			return 0, err
+		}
+	}
+	for name := range wanted {
+		return 0, errors.Errorf("表%s没有列%s", this.table, name)
+	}
+	if len(data) == 0 {
+		return 0, nil
+	}
+	return this.updateByPk(ctx, v, data)
+}
+
+// updateByPk 按实体的主键更新数据
+func (this *Repository[T]) updateByPk(ctx context.Context, entity reflect.Value, data map[string]interface{}) (int64, error) {
+	ids, err := this.entityPk(entity)
+	if err != nil {
+		return 0, err
+	}
+	where, args, err := this.pkWhere(ids...)
+	if err != nil {
+		return 0, err
+	}
+	return this.store.Update(ctx, this.table, data, where, args...)
+}
+
+// SoftDelete 按主键软删除记录，将softdelete列设为当前时间或1，已删除的记录不受影响
+// 参数：
+//   - ctx: 上下文
+//   - ids: 主键值
+//
+// 返回：
+//   - rowsAffected: 影响的行数
+//   - err: 错误信息
+func (this *Repository[T]) SoftDelete(ctx context.Context, ids ...interface{}) (rowsAffected int64, err error) {
+	if this.err != nil {
+		return 0, this.err
+	}
+	col := this.meta.softDelete
+	if col == nil {
+		return 0, errors.Errorf("表%s没有softdelete字段", this.table)
+	}
+	where, args, err := this.pkWhere(ids...)
+	if err != nil {
+		return 0, err
+	}
+	var value interface{} = 1
+	if softDeleteKind(col.typ) == "time" {
+		value = time.Now().Format(sql_driver.TimeFormat)
+	}
+	where += " AND " + this.notDeletedSql()
+	return this.store.Update(ctx, this.table, map[string]interface{}{col.name: value}, where, args...)
+}
+
+// Delete 按主键物理删除记录
+// 参数：
+//   - ctx: 上下文
+//   - ids: 主键值
+//
+// 返回：
+//   - rowsAffected: 影响的行数
+//   - err: 错误信息
+func (this *Repository[T]) Delete(ctx context.Context, ids ...interface{}) (rowsAffected int64, err error) {
+	if this.err != nil {
+		return 0, this.err
+	}
+	where, args, err := this.pkWhere(ids...)
+	if err != nil {
+		return 0, err
+	}
+	return this.store.Delete(ctx, this.table, where, args...)
+}
+
+// Upsert 插入记录，uniqueKeys冲突时更新其余列，uniqueKeys为空时使用主键
+// 零值的autoincr列不写入，冲突判断需依赖其他唯一键
+// 参数：
+//   - ctx: 上下文
+//   - entity: 记录
+//   - uniqueKeys: 判断冲突的唯一键列
+//
+// 返回：
+//   - err: 错误信息
+func (this *Repository[T]) Upsert(ctx context.Context, entity *T, uniqueKeys ...string) error {
+	if this.err != nil {
+		return this.err
+	}
+	v := reflect.ValueOf(entity).Elem()
+	if len(uniqueKeys) == 0 {
+		for _, col := range this.meta.pks {
+			uniqueKeys = append(uniqueKeys, col.name)
+		}
+	}
+	data := make(map[string]interface{}, len(this.meta.columns))
+	for _, col := range this.meta.columns {
+		if col.autoIncr && col.isZero(v) {
+			continue
+		}
+		value, err := col.value(v)
+		if err != nil {
+			return err
+		}
+		data[col.name] = value
+	}
+	for _, key := range uniqueKeys {
+		if _, ok := data[key]; !ok {
+			return errors.Errorf("唯一键%s没有值", key)
+		}
+	}
+	return this.store.InsertOrUpdate(ctx, this.table, data, uniqueKeys...)
+}
@@ -0,0 +1,44 @@
+package repository
+
+// 导入所需的包
+import (
+	// 上下文包
+	"context"
+
+	// EVE API包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api"
+	// 视图对象包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/vo"
+)
+
+// store Repository使用的插件存储操作，与ev_api.StoreTx的方法一致
+type store interface {
+	Exec(ctx context.Context, sql string, args ...interface{}) (rowsAffected int64, err error)
+	SelectRows(ctx context.Context, sql string, args ...interface{}) (*vo.StoreRowsRes, error)
+	Update(ctx context.Context, table string, updateData map[string]interface{}, whereSql string, whereArgs ...interface{}) (rowsAffected int64, err error)
+	Delete(ctx context.Context, table, whereSql string, whereArgs ...interface{}) (rowsAffected int64, err error)
+	InsertOrUpdate(ctx context.Context, table string, upsertData map[string]interface{}, uniqueKeys ...string) error
+}
+
+// apiStore 不使用事务，直接调用ev_api的Store方法
+type apiStore struct{}
+
+func (this apiStore) Exec(ctx context.Context, sql string, args ...interface{}) (int64, error) {
+	return ev_api.GetEvApi().StoreExec(ctx, sql, args...)
+}
+
+func (this apiStore) SelectRows(ctx context.Context, sql string, args ...interface{}) (*vo.StoreRowsRes, error) {
+	return ev_api.GetEvApi().StoreSelectRows(ctx, sql, args...)
+}
+
+func (this apiStore) Update(ctx context.Context, table string, updateData map[string]interface{}, whereSql string, whereArgs ...interface{}) (int64, error) {
+	return ev_api.GetEvApi().StoreUpdate(ctx, table, updateData, whereSql, whereArgs...)
+}
+
+func (this apiStore) Delete(ctx context.Context, table, whereSql string, whereArgs ...interface{}) (int64, error) {
+	return ev_api.GetEvApi().StoreDelete(ctx, table, whereSql, whereArgs...)
+}
+
+func (this apiStore) InsertOrUpdate(ctx context.Context, table string, upsertData map[string]interface{}, uniqueKeys ...string) error {
+	return ev_api.GetEvApi().StoreInsertOrUpdate(ctx, table, upsertData, uniqueKeys...)
+}
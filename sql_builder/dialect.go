@@ -0,0 +1,179 @@
+// sql_builder包提供SQL构建工具和辅助功能
+//
+// dialect.go 文件提供插件存储方言相关的标识符引号、分页和upsert语法。
+package sql_builder
+
+// 导入所需的包
+import (
+	// 排序包
+	"sort"
+	// 字符串处理包
+	"strings"
+
+	// 插件服务包
+	"github.com/1340691923/eve-plugin-sdk-go/backend/plugin_server"
+	// 枚举包
+	"github.com/1340691923/eve-plugin-sdk-go/enum"
+	// squirrel SQL构建库
+	"github.com/Masterminds/squirrel"
+	// squirrel的不可变构建器
+	"github.com/lann/builder"
+)
+
+// Dialect SQL方言，取值与plugin_server.DbType一致
+type Dialect string
+
+// 支持的方言
+const (
+	// DialectSqlite SQLite方言
+	DialectSqlite Dialect = enum.SqliteDbTyp
+	// DialectMysql MySQL方言
+	DialectMysql Dialect = enum.MysqlDbTyp
+)
+
+// CurrentDialect 返回插件存储的方言，由启动参数dbType决定，未知取值按SQLite处理
+func CurrentDialect() Dialect {
+	if plugin_server.DbType == enum.MysqlDbTyp {
+		return DialectMysql
+	}
+	return DialectSqlite
+}
+
+// Quote 按插件存储的方言为标识符加引号，见Dialect.Quote
+func Quote(name string) string {
+	return CurrentDialect().Quote(name)
+}
+
+// Quote 为标识符加引号，MySQL使用反引号，SQLite使用双引号
+// 带点的名称按段分别加引号，例如 u.name => `u`.`name`，*保持不变
+// 参数：
+//   - name: 表名或列名
+//
+// 返回：
+//   - string: 加引号后的标识符
+func (this Dialect) Quote(name string) string {
+	q := `"`
+	if this == DialectMysql {
+		q = "`"
+	}
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		if part == "*" {
+			continue
+		}
+		parts[i] = q + strings.ReplaceAll(part, q, q+q) + q
+	}
+	return strings.Join(parts, ".")
+}
+
+// Upsert 创建插入或更新的构建器，MySQL使用ON DUPLICATE KEY UPDATE，SQLite使用ON CONFLICT
+// 除uniqueKeys以外的列在冲突时更新为新值，全部列都是uniqueKeys时冲突则忽略
+// 参数：
+//   - table: 表名
+//   - data: 列名 => 值
+//   - uniqueKeys: 唯一键的列，SQLite作为ON CONFLICT的冲突目标，MySQL按表上的唯一索引判断冲突
+//
+// 返回：
+//   - InsertBuilder: 插入构建器
+func (this Dialect) Upsert(table string, data map[string]interface{}, uniqueKeys ...string) InsertBuilder {
+	columns := make([]string, 0, len(data))
+	for col := range data {
+		columns = append(columns, col)
+	}
+	sort.Strings(columns)
+
+	isKey := make(map[string]bool, len(uniqueKeys))
+	for _, key := range uniqueKeys {
+		isKey[key] = true
+	}
+	quoted := make([]string, len(columns))
+	values := make([]interface{}, len(columns))
+	var sets []string
+	for i, col := range columns {
+		quoted[i] = this.Quote(col)
+		values[i] = data[col]
+		if isKey[col] {
+			continue
+		}
+		if this == DialectMysql {
+			sets = append(sets, quoted[i]+" = VALUES("+quoted[i]+")")
+		} else {
+			sets = append(sets, quoted[i]+" = excluded."+quoted[i])
+		}
+	}
+
+	var suffix string
+	if this == DialectMysql {
+		if len(sets) == 0 && len(quoted) > 0 {
+			// MySQL没有DO NOTHING，将列更新为自身以忽略冲突
+			sets = append(sets, quoted[0]+" = "+quoted[0])
+		}
+		suffix = "ON DUPLICATE KEY UPDATE " + strings.Join(sets, ", ")
+	} else {
+		suffix = "ON CONFLICT"
+		if len(uniqueKeys) > 0 {
+			keys := make([]string, len(uniqueKeys))
+			for i, key := range uniqueKeys {
+				keys[i] = this.Quote(key)
+			}
+			suffix += " (" + strings.Join(keys, ", ") + ")"
+		}
+		if len(sets) == 0 {
+			suffix += " DO NOTHING"
+		} else {
+			suffix += " DO UPDATE SET " + strings.Join(sets, ", ")
+		}
+	}
+	return SqlBuilder.Insert(this.Quote(table)).Columns(quoted...).Values(values...).Suffix(suffix)
+}
+
+// Paginate 为查询设置分页，page从1开始，小于1时按1处理
+// 参数：
+//   - b: 查询构建器
+//   - page: 页码
+//   - pageSize: 每页记录数
+//
+// 返回：
+//   - SelectBuilder: 设置了LIMIT和OFFSET的查询构建器
+func (this Dialect) Paginate(b SelectBuilder, page, pageSize int) SelectBuilder {
+	if page < 1 {
+		page = 1
+	}
+	return b.Limit(uint64(pageSize)).Offset(CreatePage(page, pageSize))
+}
+
+// fixLimit 只设置了OFFSET时补上不限制行数的LIMIT，两种方言都不支持单独的OFFSET
+func (this Dialect) fixLimit(b SelectBuilder) SelectBuilder {
+	offset, _ := builder.Get(b, "Offset")
+	limit, _ := builder.Get(b, "Limit")
+	if offset == nil || offset == "" || (limit != nil && limit != "") {
+		return b
+	}
+	if this == DialectMysql {
+		return b.Limit(18446744073709551615)
+	}
+	return builder.Set(b, "Limit", "-1").(SelectBuilder)
+}
+
+// toSql 生成方言对应的SQL，占位符统一为?
+func (this Dialect) toSql(b squirrel.Sqlizer) (string, []interface{}, error) {
+	switch v := b.(type) {
+	case SelectBuilder:
+		b = this.fixLimit(v).PlaceholderFormat(squirrel.Question)
+	case *SelectBuilder:
+		b = this.fixLimit(*v).PlaceholderFormat(squirrel.Question)
+	case InsertBuilder:
+		b = v.PlaceholderFormat(squirrel.Question)
+	case *InsertBuilder:
+		b = v.PlaceholderFormat(squirrel.Question)
+	case UpdateBuilder:
+		b = v.PlaceholderFormat(squirrel.Question)
+	case *UpdateBuilder:
+		b = v.PlaceholderFormat(squirrel.Question)
+	case DeleteBuilder:
+		b = v.PlaceholderFormat(squirrel.Question)
+	case *DeleteBuilder:
+		b = v.PlaceholderFormat(squirrel.Question)
+	}
+	return b.ToSql()
+}
@@ -0,0 +1,248 @@
+// sql_builder包提供SQL构建工具和辅助功能
+//
+// executor.go 文件提供直接执行构建器的方法，执行目标可以是插件存储、插件存储事务或MySQL数据源。
+package sql_builder
+
+// 导入所需的包
+import (
+	// 上下文包
+	"context"
+
+	// EVE API包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api"
+	// 结构体扫描包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/scan"
+	// 视图对象包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/vo"
+	// squirrel的不可变构建器
+	"github.com/lann/builder"
+	// 错误处理包
+	"github.com/pkg/errors"
+)
+
+// ErrNoRows First没有查询到记录
+var ErrNoRows = scan.ErrNoRows
+
+// conn 执行SQL的目标
+type conn interface {
+	exec(ctx context.Context, sql string, args ...interface{}) (int64, error)
+	rows(ctx context.Context, sql string, args ...interface{}) ([]map[string]interface{}, []vo.ColumnType, error)
+}
+
+// storeConn 插件存储
+type storeConn struct{}
+
+func (this storeConn) exec(ctx context.Context, sql string, args ...interface{}) (int64, error) {
+	return ev_api.GetEvApi().StoreExec(ctx, sql, args...)
+}
+
+func (this storeConn) rows(ctx context.Context, sql string, args ...interface{}) ([]map[string]interface{}, []vo.ColumnType, error) {
+	res, err := ev_api.GetEvApi().StoreSelectRows(ctx, sql, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return res.Result, res.ColumnTypes, nil
+}
+
+// storeTxConn 插件存储事务
+type storeTxConn struct {
+	tx ev_api.StoreTx
+}
+
+func (this storeTxConn) exec(ctx context.Context, sql string, args ...interface{}) (int64, error) {
+	return this.tx.Exec(ctx, sql, args...)
+}
+
+func (this storeTxConn) rows(ctx context.Context, sql string, args ...interface{}) ([]map[string]interface{}, []vo.ColumnType, error) {
+	res, err := this.tx.SelectRows(ctx, sql, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return res.Result, res.ColumnTypes, nil
+}
+
+// mysqlConn MySQL数据源
+type mysqlConn struct {
+	api    *ev_api.EvApiAdapter
+	dbName string
+}
+
+func (this mysqlConn) exec(ctx context.Context, sql string, args ...interface{}) (int64, error) {
+	return this.api.MysqlExecSql(ctx, this.dbName, sql, args...)
+}
+
+func (this mysqlConn) rows(ctx context.Context, sql string, args ...interface{}) ([]map[string]interface{}, []vo.ColumnType, error) {
+	res, err := this.api.MysqlSelectRows(ctx, this.dbName, sql, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return res.Result, res.ColumnTypes, nil
+}
+
+// Executor 按目标的方言生成SQL并执行构建器，查询结果按scan包的规则扫描
+type Executor struct {
+	dialect Dialect
+	conn    conn
+}
+
+// Store 返回在插件存储上执行的Executor，方言由plugin_server.DbType决定
+func Store() *Executor {
+	return &Executor{dialect: CurrentDialect(), conn: storeConn{}}
+}
+
+// StoreTx 返回在插件存储事务内执行的Executor，例如在ev_api.StoreTx的回调中使用
+func StoreTx(tx ev_api.StoreTx) *Executor {
+	return &Executor{dialect: CurrentDialect(), conn: storeTxConn{tx: tx}}
+}
+
+// Mysql 返回在MySQL数据源上执行的Executor
+// 参数：
+//   - api: 数据源适配器
+//   - dbName: 数据库名称
+//
+// 返回：
+//   - *Executor: 执行器
+func Mysql(api *ev_api.EvApiAdapter, dbName string) *Executor {
+	return &Executor{dialect: DialectMysql, conn: mysqlConn{api: api, dbName: dbName}}
+}
+
+// Dialect 返回执行目标的方言
+func (this *Executor) Dialect() Dialect {
+	return this.dialect
+}
+
+// Quote 按执行目标的方言为标识符加引号
+func (this *Executor) Quote(name string) string {
+	return this.dialect.Quote(name)
+}
+
+// Exec 执行插入、更新、删除等语句
+// 参数：
+//   - ctx: 上下文
+//   - b: 构建器，例如 SqlBuilder.Update("users").Set("name", "ev").Where(Eq{"id": 1})
+//
+// 返回：
+//   - rowsAffected: 影响的行数
+//   - err: 错误信息
+func (this *Executor) Exec(ctx context.Context, b Sqlizer) (rowsAffected int64, err error) {
+	sql, args, err := this.dialect.toSql(b)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	return this.conn.exec(ctx, sql, args...)
+}
+
+// Select 执行查询并将结果扫描到dest
+// 参数：
+//   - ctx: 上下文
+//   - dest: 切片指针，例如 *[]User、*[]map[string]interface{}
+//   - b: 查询构建器
+//
+// 返回：
+//   - err: 错误信息
+func (this *Executor) Select(ctx context.Context, dest interface{}, b Sqlizer) (err error) {
+	sql, args, err := this.dialect.toSql(b)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	rows, columnTypes, err := this.conn.rows(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+	return scan.ScanInto(dest, rows, columnTypes)
+}
+
+// First 查询第一条记录并扫描到dest，没有记录时返回ErrNoRows
+// 参数：
+//   - ctx: 上下文
+//   - dest: 指针，例如 *User
+//   - b: 查询构建器，会被设置为LIMIT 1
+//
+// 返回：
+//   - err: 错误信息
+func (this *Executor) First(ctx context.Context, dest interface{}, b SelectBuilder) (err error) {
+	sql, args, err := this.dialect.toSql(b.Limit(1))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	rows, columnTypes, err := this.conn.rows(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return ErrNoRows
+	}
+	return scan.ScanOneInto(dest, rows[0], columnTypes)
+}
+
+// Count 返回查询的记录数，b的LIMIT、OFFSET和ORDER BY会被忽略
+// 参数：
+//   - ctx: 上下文
+//   - b: 查询构建器
+//
+// 返回：
+//   - total: 记录数
+//   - err: 错误信息
+func (this *Executor) Count(ctx context.Context, b SelectBuilder) (total int64, err error) {
+	inner := builder.Delete(b.RemoveLimit().RemoveOffset(), "OrderByParts").(SelectBuilder)
+	countBuilder := SqlBuilder.Select("COUNT(*) AS total").FromSelect(inner, "t")
+	sql, args, err := this.dialect.toSql(countBuilder)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	rows, columnTypes, err := this.conn.rows(ctx, sql, args...)
+	if err != nil {
+		return 0, err
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	if err = scan.ScanOneInto(&total, rows[0], columnTypes); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// Page 分页查询并将当前页扫描到dest，同时返回记录总数
+// 参数：
+//   - ctx: 上下文
+//   - dest: 切片指针，例如 *[]User
+//   - b: 查询构建器，分页时应指定ORDER BY以保证结果稳定
+//   - page: 页码，从1开始
+//   - pageSize: 每页记录数
+//
+// 返回：
+//   - total: 记录总数
+//   - err: 错误信息
+func (this *Executor) Page(ctx context.Context, dest interface{}, b SelectBuilder, page, pageSize int) (total int64, err error) {
+	if pageSize < 1 {
+		return 0, errors.Errorf("每页记录数必须大于0，实际为%d", pageSize)
+	}
+	if total, err = this.Count(ctx, b); err != nil {
+		return 0, err
+	}
+	if total == 0 {
+		return 0, scan.ScanInto(dest, nil, nil)
+	}
+	if err = this.Select(ctx, dest, this.dialect.Paginate(b, page, pageSize)); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// Upsert 插入记录，唯一键冲突时更新其余列，见Dialect.Upsert
+// 参数：
+//   - ctx: 上下文
+//   - table: 表名
+//   - data: 列名 => 值
+//   - uniqueKeys: 唯一键的列
+//
+// 返回：
+//   - rowsAffected: 影响的行数
+//   - err: 错误信息
+func (this *Executor) Upsert(ctx context.Context, table string, data map[string]interface{}, uniqueKeys ...string) (rowsAffected int64, err error) {
+	if len(data) == 0 {
+		return 0, errors.New("upsert的数据不能为空")
+	}
+	return this.Exec(ctx, this.dialect.Upsert(table, data, uniqueKeys...))
+}
@@ -42,6 +42,10 @@ type (
 	InsertBuilder = squirrel.InsertBuilder
 	// UpdateBuilder 更新构建器别名
 	UpdateBuilder = squirrel.UpdateBuilder
+	// DeleteBuilder 删除构建器别名
+	DeleteBuilder = squirrel.DeleteBuilder
+	// Sqlizer 可生成SQL的构建器或条件
+	Sqlizer = squirrel.Sqlizer
 )
 
 // CreatePage 创建分页查询的偏移量
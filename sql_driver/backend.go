@@ -0,0 +1,164 @@
+// sql_driver包提供基于EV数据源的database/sql驱动
+package sql_driver
+
+// 导入所需的包
+import (
+	// 上下文包
+	"context"
+
+	// EVE API包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api"
+)
+
+// executor 执行SQL的后端
+type executor interface {
+	// exec 执行语句，返回影响行数
+	exec(ctx context.Context, query string, args []interface{}) (Result, error)
+	// query 执行查询
+	query(ctx context.Context, query string, args []interface{}) (*rows, error)
+}
+
+// backend 数据源后端
+type backend interface {
+	executor
+	// begin 开启事务
+	begin(ctx context.Context) (txBackend, error)
+}
+
+// txBackend 事务后端
+type txBackend interface {
+	executor
+	// commit 提交事务
+	commit(ctx context.Context) error
+	// rollback 回滚事务
+	rollback(ctx context.Context) error
+}
+
+// mysqlBackend 用户MySQL数据源
+type mysqlBackend struct {
+	api    *ev_api.EvApiAdapter
+	dbName string
+}
+
+// exec 执行语句
+func (this *mysqlBackend) exec(ctx context.Context, query string, args []interface{}) (Result, error) {
+	rowsAffected, err := this.api.MysqlExecSql(ctx, this.dbName, query, args...)
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{rowsAffected: rowsAffected}, nil
+}
+
+// query 执行查询
+func (this *mysqlBackend) query(ctx context.Context, query string, args []interface{}) (*rows, error) {
+	res, err := this.api.MysqlSelectRows(ctx, this.dbName, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return newRows(res.Columns, res.ColumnTypes, res.Result), nil
+}
+
+// begin 开启事务
+func (this *mysqlBackend) begin(ctx context.Context) (txBackend, error) {
+	tx, err := this.api.MysqlBeginTx(ctx, this.dbName)
+	if err != nil {
+		return nil, err
+	}
+	return &mysqlTxBackend{tx: tx}, nil
+}
+
+// mysqlTxBackend 用户MySQL数据源的事务
+type mysqlTxBackend struct {
+	tx ev_api.MysqlTx
+}
+
+// exec 在事务内执行语句
+func (this *mysqlTxBackend) exec(ctx context.Context, query string, args []interface{}) (Result, error) {
+	rowsAffected, err := this.tx.Exec(ctx, query, args...)
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{rowsAffected: rowsAffected}, nil
+}
+
+// query 在事务内执行查询
+func (this *mysqlTxBackend) query(ctx context.Context, query string, args []interface{}) (*rows, error) {
+	res, err := this.tx.SelectRows(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return newRows(res.Columns, res.ColumnTypes, res.Result), nil
+}
+
+// commit 提交事务
+func (this *mysqlTxBackend) commit(ctx context.Context) error {
+	return this.tx.Commit(ctx)
+}
+
+// rollback 回滚事务
+func (this *mysqlTxBackend) rollback(ctx context.Context) error {
+	return this.tx.Rollback(ctx)
+}
+
+// storeBackend 插件存储
+type storeBackend struct{}
+
+// exec 执行语句
+func (this *storeBackend) exec(ctx context.Context, query string, args []interface{}) (Result, error) {
+	rowsAffected, err := ev_api.GetEvApi().StoreExec(ctx, query, args...)
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{rowsAffected: rowsAffected}, nil
+}
+
+// query 执行查询
+func (this *storeBackend) query(ctx context.Context, query string, args []interface{}) (*rows, error) {
+	res, err := ev_api.GetEvApi().StoreSelectRows(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return newRows(nil, res.ColumnTypes, res.Result), nil
+}
+
+// begin 开启事务
+func (this *storeBackend) begin(ctx context.Context) (txBackend, error) {
+	tx, err := ev_api.GetEvApi().StoreBeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &storeTxBackend{tx: tx}, nil
+}
+
+// storeTxBackend 插件存储的事务
+type storeTxBackend struct {
+	tx ev_api.StoreTx
+}
+
+// exec 在事务内执行语句
+func (this *storeTxBackend) exec(ctx context.Context, query string, args []interface{}) (Result, error) {
+	rowsAffected, err := this.tx.Exec(ctx, query, args...)
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{rowsAffected: rowsAffected}, nil
+}
+
+// query 在事务内执行查询
+func (this *storeTxBackend) query(ctx context.Context, query string, args []interface{}) (*rows, error) {
+	res, err := this.tx.SelectRows(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return newRows(nil, res.ColumnTypes, res.Result), nil
+}
+
+// commit 提交事务
+func (this *storeTxBackend) commit(ctx context.Context) error {
+	return this.tx.Commit(ctx)
+}
+
+// rollback 回滚事务
+func (this *storeTxBackend) rollback(ctx context.Context) error {
+	return this.tx.Rollback(ctx)
+}
@@ -0,0 +1,216 @@
+// sql_driver包提供基于EV数据源的database/sql驱动
+package sql_driver
+
+// 导入所需的包
+import (
+	// 上下文包
+	"context"
+	// 数据库接口包
+	"database/sql"
+	// 数据库驱动接口包
+	"database/sql/driver"
+	// 时间处理包
+	"time"
+
+	// 错误处理包
+	"github.com/pkg/errors"
+)
+
+// TimeFormat 时间参数发送到基座时使用的格式
+var TimeFormat = "2006-01-02 15:04:05.999999"
+
+// ErrLastInsertId 基座接口不返回自增ID
+var ErrLastInsertId = errors.New("ev驱动不支持LastInsertId，请在事务内使用 SELECT LAST_INSERT_ID()")
+
+// conn 数据库连接，未开启事务时不占用基座的连接
+type conn struct {
+	backend backend
+	tx      txBackend
+}
+
+// Prepare 实现driver.Conn接口，语句不会在基座预编译
+func (this *conn) Prepare(query string) (driver.Stmt, error) {
+	return &stmt{conn: this, query: query}, nil
+}
+
+// PrepareContext 实现driver.ConnPrepareContext接口
+func (this *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	return this.Prepare(query)
+}
+
+// Close 实现driver.Conn接口
+func (this *conn) Close() error {
+	if this.tx != nil {
+		tx := this.tx
+		this.tx = nil
+		return tx.rollback(context.Background())
+	}
+	return nil
+}
+
+// Begin 实现driver.Conn接口
+func (this *conn) Begin() (driver.Tx, error) {
+	return this.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+// BeginTx 实现driver.ConnBeginTx接口，不支持指定隔离级别和只读事务
+func (this *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if this.tx != nil {
+		return nil, errors.New("已存在未结束的事务")
+	}
+	if sql.IsolationLevel(opts.Isolation) != sql.LevelDefault {
+		return nil, errors.Errorf("不支持指定事务隔离级别：%s", sql.IsolationLevel(opts.Isolation))
+	}
+	if opts.ReadOnly {
+		return nil, errors.New("不支持只读事务")
+	}
+	tx, err := this.backend.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	this.tx = tx
+	return &connTx{conn: this}, nil
+}
+
+// ExecContext 实现driver.ExecerContext接口
+func (this *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	values, err := toArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	res, err := this.executor().exec(ctx, query, values)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// QueryContext 实现driver.QueryerContext接口
+func (this *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	values, err := toArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	return this.executor().query(ctx, query, values)
+}
+
+// executor 返回当前的执行者，开启事务时为事务
+func (this *conn) executor() executor {
+	if this.tx != nil {
+		return this.tx
+	}
+	return this.backend
+}
+
+// toArgs 将驱动参数转换为基座接口的参数
+// 基座通过JSON接收参数，时间转换为TimeFormat格式的字符串，[]byte转换为字符串
+func toArgs(named []driver.NamedValue) ([]interface{}, error) {
+	args := make([]interface{}, 0, len(named))
+	for _, nv := range named {
+		if nv.Name != "" {
+			return nil, errors.Errorf("不支持命名参数：%s", nv.Name)
+		}
+		switch v := nv.Value.(type) {
+		case time.Time:
+			args = append(args, v.Format(TimeFormat))
+		case []byte:
+			args = append(args, string(v))
+		default:
+			args = append(args, v)
+		}
+	}
+	return args, nil
+}
+
+// connTx 事务
+type connTx struct {
+	conn *conn
+}
+
+// Commit 实现driver.Tx接口
+func (this *connTx) Commit() error {
+	tx, err := this.take()
+	if err != nil {
+		return err
+	}
+	return tx.commit(context.Background())
+}
+
+// Rollback 实现driver.Tx接口
+func (this *connTx) Rollback() error {
+	tx, err := this.take()
+	if err != nil {
+		return err
+	}
+	return tx.rollback(context.Background())
+}
+
+// take 取出连接上的事务
+func (this *connTx) take() (txBackend, error) {
+	tx := this.conn.tx
+	if tx == nil {
+		return nil, sql.ErrTxDone
+	}
+	this.conn.tx = nil
+	return tx, nil
+}
+
+// stmt 语句，执行时直接发送SQL与参数
+type stmt struct {
+	conn  *conn
+	query string
+}
+
+// Close 实现driver.Stmt接口
+func (this *stmt) Close() error {
+	return nil
+}
+
+// NumInput 实现driver.Stmt接口，返回-1表示不检查参数个数
+func (this *stmt) NumInput() int {
+	return -1
+}
+
+// Exec 实现driver.Stmt接口
+func (this *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return this.ExecContext(context.Background(), toNamed(args))
+}
+
+// Query 实现driver.Stmt接口
+func (this *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return this.QueryContext(context.Background(), toNamed(args))
+}
+
+// ExecContext 实现driver.StmtExecContext接口
+func (this *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	return this.conn.ExecContext(ctx, this.query, args)
+}
+
+// QueryContext 实现driver.StmtQueryContext接口
+func (this *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return this.conn.QueryContext(ctx, this.query, args)
+}
+
+// toNamed 将位置参数转换为driver.NamedValue
+func toNamed(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, 0, len(args))
+	for i, v := range args {
+		named = append(named, driver.NamedValue{Ordinal: i + 1, Value: v})
+	}
+	return named
+}
+
+// Result 执行结果
+type Result struct {
+	rowsAffected int64
+}
+
+// LastInsertId 实现driver.Result接口，始终返回ErrLastInsertId
+func (this Result) LastInsertId() (int64, error) {
+	return 0, ErrLastInsertId
+}
+
+// RowsAffected 实现driver.Result接口
+func (this Result) RowsAffected() (int64, error) {
+	return this.rowsAffected, nil
+}
@@ -0,0 +1,172 @@
+// sql_driver包提供基于EV数据源的database/sql驱动
+//
+// 注册了两个驱动：
+//   - ev-mysql：通过基座访问用户的MySQL数据源，DSN格式为 conn_id=1&user_id=1&db=test
+//   - ev-store：访问插件自身的存储（SQLite或MySQL），DSN可为空
+//
+// 注册后即可配合sqlx、squirrel等标准工具使用，例如：
+//
+//	db, _ := sql.Open("ev-mysql", "conn_id=1&user_id=1&db=test")
+//	rows, err := sql_builder.SqlBuilder.Select("*").From("user").RunWith(db).QueryContext(ctx)
+//
+// 注意：基座接口不返回自增ID，Result.LastInsertId始终返回错误；
+// ev-mysql在事务内可通过 SELECT LAST_INSERT_ID() 获取。
+package sql_driver
+
+// 导入所需的包
+import (
+	// 上下文包
+	"context"
+	// 数据库接口包
+	"database/sql"
+	// 数据库驱动接口包
+	"database/sql/driver"
+	// URL处理包
+	"net/url"
+	// 字符串转换包
+	"strconv"
+
+	// EVE API包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api"
+	// 错误处理包
+	"github.com/pkg/errors"
+)
+
+// 驱动名称
+const (
+	// MysqlDriverName 用户MySQL数据源驱动名
+	MysqlDriverName = "ev-mysql"
+	// StoreDriverName 插件存储驱动名
+	StoreDriverName = "ev-store"
+)
+
+// init 注册驱动
+func init() {
+	sql.Register(MysqlDriverName, &MysqlDriver{})
+	sql.Register(StoreDriverName, &StoreDriver{})
+}
+
+// Config ev-mysql的DSN配置
+type Config struct {
+	// 连接ID
+	ConnId int
+	// 用户ID
+	UserId int
+	// 数据库名称
+	DbName string
+}
+
+// ParseDSN 解析ev-mysql的DSN，格式为 conn_id=1&user_id=1&db=test
+// 参数：
+//   - dsn: 数据源名称
+//
+// 返回：
+//   - *Config: DSN配置
+//   - error: 错误信息
+func ParseDSN(dsn string) (*Config, error) {
+	values, err := url.ParseQuery(dsn)
+	if err != nil {
+		return nil, errors.Wrapf(err, "DSN格式错误：%s", dsn)
+	}
+	cfg := &Config{DbName: values.Get("db")}
+	if cfg.ConnId, err = strconv.Atoi(values.Get("conn_id")); err != nil {
+		return nil, errors.Errorf("DSN中的conn_id无效：%s", dsn)
+	}
+	if cfg.UserId, err = strconv.Atoi(values.Get("user_id")); err != nil {
+		return nil, errors.Errorf("DSN中的user_id无效：%s", dsn)
+	}
+	return cfg, nil
+}
+
+// FormatDSN 生成DSN
+func (this *Config) FormatDSN() string {
+	values := url.Values{}
+	values.Set("conn_id", strconv.Itoa(this.ConnId))
+	values.Set("user_id", strconv.Itoa(this.UserId))
+	values.Set("db", this.DbName)
+	return values.Encode()
+}
+
+// OpenMysql 打开用户MySQL数据源，无需拼接DSN
+// 参数：
+//   - connId: 连接ID
+//   - userId: 用户ID
+//   - dbName: 数据库名称
+//
+// 返回：
+//   - *sql.DB: 数据库句柄
+func OpenMysql(connId, userId int, dbName string) *sql.DB {
+	return sql.OpenDB(&mysqlConnector{cfg: &Config{ConnId: connId, UserId: userId, DbName: dbName}})
+}
+
+// OpenStore 打开插件存储
+// 返回：
+//   - *sql.DB: 数据库句柄
+func OpenStore() *sql.DB {
+	return sql.OpenDB(&storeConnector{})
+}
+
+// MysqlDriver ev-mysql驱动
+type MysqlDriver struct{}
+
+// Open 实现driver.Driver接口
+func (this *MysqlDriver) Open(dsn string) (driver.Conn, error) {
+	connector, err := this.OpenConnector(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return connector.Connect(context.Background())
+}
+
+// OpenConnector 实现driver.DriverContext接口
+func (this *MysqlDriver) OpenConnector(dsn string) (driver.Connector, error) {
+	cfg, err := ParseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &mysqlConnector{cfg: cfg}, nil
+}
+
+// mysqlConnector ev-mysql连接器
+type mysqlConnector struct {
+	cfg *Config
+}
+
+// Connect 实现driver.Connector接口，连接是无状态的，只有开启事务时才占用基座的连接
+func (this *mysqlConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return &conn{backend: &mysqlBackend{
+		api:    ev_api.NewEvWrapApi(this.cfg.ConnId, this.cfg.UserId),
+		dbName: this.cfg.DbName,
+	}}, nil
+}
+
+// Driver 实现driver.Connector接口
+func (this *mysqlConnector) Driver() driver.Driver {
+	return &MysqlDriver{}
+}
+
+// StoreDriver ev-store驱动
+type StoreDriver struct{}
+
+// Open 实现driver.Driver接口，DSN被忽略
+func (this *StoreDriver) Open(dsn string) (driver.Conn, error) {
+	return (&storeConnector{}).Connect(context.Background())
+}
+
+// OpenConnector 实现driver.DriverContext接口
+func (this *StoreDriver) OpenConnector(dsn string) (driver.Connector, error) {
+	return &storeConnector{}, nil
+}
+
+// storeConnector ev-store连接器
+type storeConnector struct{}
+
+// Connect 实现driver.Connector接口
+func (this *storeConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return &conn{backend: &storeBackend{}}, nil
+}
+
+// Driver 实现driver.Connector接口
+func (this *storeConnector) Driver() driver.Driver {
+	return &StoreDriver{}
+}
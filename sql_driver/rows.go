@@ -0,0 +1,115 @@
+// sql_driver包提供基于EV数据源的database/sql驱动
+package sql_driver
+
+// 导入所需的包
+import (
+	// 数据库驱动接口包
+	"database/sql/driver"
+	// IO包
+	"io"
+	// 数学包
+	"math"
+	// 排序包
+	"sort"
+	// 字符串转换包
+	"strconv"
+	// 字符串处理包
+	"strings"
+
+	// 结构体扫描包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/scan"
+	// 视图对象包
+	"github.com/1340691923/eve-plugin-sdk-go/ev_api/vo"
+)
+
+// rows 查询结果，基座一次性返回全部数据
+type rows struct {
+	columns []string
+	types   []*vo.ColumnType
+	data    []map[string]interface{}
+	pos     int
+}
+
+// newRows 创建查询结果
+// columns为空时按列类型元数据的顺序确定列，仍为空时按首行的列名排序
+func newRows(columns []string, columnTypes []vo.ColumnType, data []map[string]interface{}) *rows {
+	if len(columns) == 0 {
+		for _, ct := range columnTypes {
+			columns = append(columns, ct.Name)
+		}
+	}
+	if len(columns) == 0 && len(data) > 0 {
+		for col := range data[0] {
+			columns = append(columns, col)
+		}
+		sort.Strings(columns)
+	}
+
+	byName := make(map[string]*vo.ColumnType, len(columnTypes))
+	for i := range columnTypes {
+		byName[columnTypes[i].Name] = &columnTypes[i]
+	}
+	types := make([]*vo.ColumnType, len(columns))
+	for i, col := range columns {
+		types[i] = byName[col]
+	}
+
+	return &rows{columns: columns, types: types, data: data}
+}
+
+// Columns 实现driver.Rows接口
+func (this *rows) Columns() []string {
+	return this.columns
+}
+
+// Close 实现driver.Rows接口
+func (this *rows) Close() error {
+	this.data = nil
+	return nil
+}
+
+// Next 实现driver.Rows接口
+func (this *rows) Next(dest []driver.Value) error {
+	if this.pos >= len(this.data) {
+		return io.EOF
+	}
+	row := this.data[this.pos]
+	this.pos++
+	for i, col := range this.columns {
+		dest[i] = driverValue(scan.Normalize(row[col], this.databaseTypeName(i)))
+	}
+	return nil
+}
+
+// ColumnTypeDatabaseTypeName 实现driver.RowsColumnTypeDatabaseTypeName接口
+func (this *rows) ColumnTypeDatabaseTypeName(index int) string {
+	return this.databaseTypeName(index)
+}
+
+// ColumnTypeNullable 实现driver.RowsColumnTypeNullable接口
+func (this *rows) ColumnTypeNullable(index int) (nullable, ok bool) {
+	if this.types[index] == nil {
+		return false, false
+	}
+	return this.types[index].Nullable, true
+}
+
+// databaseTypeName 返回列的数据库类型名称（大写），未知时为空
+func (this *rows) databaseTypeName(index int) string {
+	if this.types[index] == nil {
+		return ""
+	}
+	return strings.ToUpper(this.types[index].DatabaseType)
+}
+
+// driverValue 将值转换为driver.Value允许的类型
+func driverValue(v interface{}) driver.Value {
+	switch val := v.(type) {
+	case uint64:
+		if val > math.MaxInt64 {
+			return strconv.FormatUint(val, 10)
+		}
+		return int64(val)
+	}
+	return v
+}